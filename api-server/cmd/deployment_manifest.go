@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/common/command"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/reqcli"
+)
+
+// DeploymentManifestClientOption holds the flags shared by the subcommands that talk to a running api server
+type DeploymentManifestClientOption struct {
+	Endpoint     string
+	ApiToken     string
+	Organization string
+	Cluster      string
+}
+
+func (opt *DeploymentManifestClientOption) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&opt.Endpoint, "endpoint", os.Getenv(consts.EnvYataiEndpoint), "yatai endpoint, e.g. http://yatai.example.com")
+	cmd.Flags().StringVar(&opt.ApiToken, "api-token", os.Getenv(consts.EnvYataiApiToken), "yatai api token")
+	cmd.Flags().StringVar(&opt.Organization, "organization", "", "organization name, the default organization of the api token is used when empty")
+	cmd.Flags().StringVar(&opt.Cluster, "cluster", "", "cluster name, all the clusters of the organization are used when empty")
+}
+
+func (opt *DeploymentManifestClientOption) Validate(ctx context.Context) error {
+	if opt.Endpoint == "" {
+		return errors.Errorf("--endpoint or the %s env is required", consts.EnvYataiEndpoint)
+	}
+	if opt.ApiToken == "" {
+		return errors.Errorf("--api-token or the %s env is required", consts.EnvYataiApiToken)
+	}
+	return nil
+}
+
+func (opt *DeploymentManifestClientOption) getUrl(path string) string {
+	prefix := strings.TrimRight(opt.Endpoint, "/") + "/api/v1"
+	if opt.Cluster != "" {
+		prefix = fmt.Sprintf("%s/clusters/%s", prefix, url.PathEscape(opt.Cluster))
+	}
+	return prefix + path
+}
+
+func (opt *DeploymentManifestClientOption) getHeaders() map[string]string {
+	headers := map[string]string{
+		consts.YataiApiTokenHeaderName: opt.ApiToken,
+	}
+	if opt.Organization != "" {
+		headers[consts.YataiOrganizationHeaderName] = opt.Organization
+	}
+	return headers
+}
+
+type ExportOption struct {
+	DeploymentManifestClientOption
+	OutputDir string
+}
+
+func (opt *ExportOption) Complete(ctx context.Context, args []string, argsLenAtDash int) error {
+	return nil
+}
+
+func (opt *ExportOption) Run(ctx context.Context, args []string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opt.getUrl("/deployments/manifests"), nil)
+	if err != nil {
+		return errors.Wrap(err, "new export request")
+	}
+	for key, value := range opt.getHeaders() {
+		req.Header.Set(key, value)
+	}
+	resp, err := reqcli.GetDefaultHttpClient().Do(req)
+	if err != nil {
+		return errors.Wrap(err, "do export request")
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "read export response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("export deployments: status=%d, %s", resp.StatusCode, content)
+	}
+
+	if opt.OutputDir == "" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+
+	manifests, err := services.DeploymentManifestService.UnmarshalManifests(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	// one file per deployment keeps the reviews of the changes small
+	for _, manifest := range manifests {
+		content, err := services.DeploymentManifestService.MarshalManifests([]*schemas.DeploymentManifestSchema{manifest})
+		if err != nil {
+			return err
+		}
+		dir := filepath.Join(opt.OutputDir, manifest.Metadata.Cluster, manifest.Metadata.Namespace)
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return errors.Wrapf(err, "create directory %s", dir)
+		}
+		path := filepath.Join(dir, manifest.Metadata.Name+".yaml")
+		// nolint: gosec
+		if err = os.WriteFile(path, content, 0644); err != nil {
+			return errors.Wrapf(err, "write %s", path)
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
+
+func getExportCmd() *cobra.Command {
+	var opt ExportOption
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "export the deployments of an organization or a cluster as yaml manifests",
+		Long:  "",
+		RunE:  command.MakeRunE(&opt),
+	}
+	opt.addFlags(cmd)
+	cmd.Flags().StringVarP(&opt.OutputDir, "output-dir", "o", "", "write one <cluster>/<namespace>/<name>.yaml file per deployment into this directory instead of printing the manifests")
+	return cmd
+}
+
+type ImportOption struct {
+	DeploymentManifestClientOption
+	Path   string
+	DryRun bool
+}
+
+func (opt *ImportOption) Complete(ctx context.Context, args []string, argsLenAtDash int) error {
+	if len(args) > 0 {
+		opt.Path = args[0]
+	}
+	return nil
+}
+
+func (opt *ImportOption) Validate(ctx context.Context) error {
+	if opt.Path == "" {
+		return errors.New("the path of the manifests directory or file is required")
+	}
+	return opt.DeploymentManifestClientOption.Validate(ctx)
+}
+
+func readDeploymentManifests(path string) ([]*schemas.DeploymentManifestSchema, error) {
+	paths := make([]string, 0)
+	err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(path)
+		if !info.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "walk %s", path)
+	}
+	sort.Strings(paths)
+
+	res := make([]*schemas.DeploymentManifestSchema, 0, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read %s", path)
+		}
+		manifests, err := services.DeploymentManifestService.UnmarshalManifests(bytes.NewReader(content))
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse %s", path)
+		}
+		res = append(res, manifests...)
+	}
+	return res, nil
+}
+
+func (opt *ImportOption) Run(ctx context.Context, args []string) error {
+	manifests, err := readDeploymentManifests(opt.Path)
+	if err != nil {
+		return err
+	}
+
+	var plan schemas.DeploymentManifestPlanSchema
+	_, err = reqcli.NewJsonRequestBuilder().
+		Method(http.MethodPost).
+		Url(opt.getUrl("/deployments/manifests/import")).
+		Headers(opt.getHeaders()).
+		Payload(schemas.ImportDeploymentManifestsSchema{
+			Manifests: manifests,
+			DryRun:    opt.DryRun,
+		}).
+		Result(&plan).
+		Do(ctx)
+	if err != nil {
+		return errors.Wrap(err, "import deployments")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tCLUSTER\tNAMESPACE\tNAME\tTARGETS CHANGED")
+	for _, item := range plan.Items {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", item.Action, item.Cluster, item.Namespace, item.Name, item.TargetsChanged)
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if plan.DryRun {
+		fmt.Println("dry run, nothing was applied")
+	}
+	return nil
+}
+
+func getImportCmd() *cobra.Command {
+	var opt ImportOption
+	cmd := &cobra.Command{
+		Use:   "import <manifests directory or file>",
+		Short: "apply yaml deployment manifests idempotently and print the plan",
+		Long:  "",
+		Args:  cobra.ExactArgs(1),
+		RunE:  command.MakeRunE(&opt),
+	}
+	opt.addFlags(cmd)
+	cmd.Flags().BoolVar(&opt.DryRun, "dry-run", false, "only print the plan")
+	return cmd
+}
@@ -18,53 +18,90 @@ import (
 	"github.com/bentoml/yatai/api-server/routes"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/common/command"
-	"github.com/bentoml/yatai/common/sync/errsgroup"
 )
 
 func addCron(ctx context.Context) {
 	c := cron.New()
-	logger := logrus.New().WithField("cron", "sync env")
 
-	err := c.AddFunc("@every 1m", func() {
-		ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+	gcLogger := logrus.New().WithField("cron", "retention gc")
+
+	err := c.AddFunc("@every 1h", func() {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute*30)
 		defer cancel()
-		logger.Info("listing unsynced deployments")
-		deployments, err := services.DeploymentService.ListUnsynced(ctx)
+		gcLogger.Info("collecting expired bentos and models")
+		err := services.RetentionService.CollectGarbage(ctx)
 		if err != nil {
-			logger.Errorf("list unsynced deployments: %s", err.Error())
+			gcLogger.Errorf("collect garbage: %s", err.Error())
+			return
 		}
-		logger.Info("updating unsynced deployments syncing_at")
-		now := time.Now()
-		nowPtr := &now
-		for _, deployment := range deployments {
-			_, err := services.DeploymentService.UpdateStatus(ctx, deployment, services.UpdateDeploymentStatusOption{
-				SyncingAt: &nowPtr,
-			})
-			if err != nil {
-				logger.Errorf("update deployment %d status: %s", deployment.ID, err.Error())
-			}
+		gcLogger.Info("collected expired bentos and models")
+	})
+
+	if err != nil {
+		gcLogger.Errorf("cron add func failed: %s", err.Error())
+	}
+
+	reaperLogger := logrus.New().WithField("cron", "upload reaper")
+
+	err = c.AddFunc("@every 10m", func() {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute*10)
+		defer cancel()
+		reaperLogger.Info("reaping stalled uploads")
+		err := services.UploadReaperService.ReapStalledUploads(ctx)
+		if err != nil {
+			reaperLogger.Errorf("reap stalled uploads: %s", err.Error())
+			return
 		}
-		logger.Info("updated unsynced deployments syncing_at")
-		var eg errsgroup.Group
-		eg.SetPoolSize(1000)
-		for _, deployment := range deployments {
-			deployment := deployment
-			eg.Go(func() error {
-				_, err := services.DeploymentService.SyncStatus(ctx, deployment)
-				return err
-			})
+		reaperLogger.Info("reaped stalled uploads")
+	})
+
+	if err != nil {
+		reaperLogger.Errorf("cron add func failed: %s", err.Error())
+	}
+
+	imageBuildLogger := logrus.New().WithField("cron", "image build retry")
+
+	err = c.AddFunc("@every 1m", func() {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+		defer cancel()
+		err := services.ImageBuildService.RetryFailedBuilds(ctx)
+		if err != nil {
+			imageBuildLogger.Errorf("retry failed image builds: %s", err.Error())
 		}
+	})
+
+	if err != nil {
+		imageBuildLogger.Errorf("cron add func failed: %s", err.Error())
+	}
+
+	canaryRolloutLogger := logrus.New().WithField("cron", "canary rollout")
+
+	err = c.AddFunc("@every 30s", func() {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+		defer cancel()
+		err := services.CanaryRolloutService.ProgressRunningRollouts(ctx)
+		if err != nil {
+			canaryRolloutLogger.Errorf("progress running canary rollouts: %s", err.Error())
+		}
+	})
+
+	if err != nil {
+		canaryRolloutLogger.Errorf("cron add func failed: %s", err.Error())
+	}
 
-		logger.Info("syncing unsynced app deployment deployments...")
-		err = eg.WaitWithTimeout(10 * time.Minute)
-		logger.Info("synced unsynced app deployment deployments...")
+	driftLogger := logrus.New().WithField("cron", "deployment drift")
+
+	err = c.AddFunc("@every 5m", func() {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+		defer cancel()
+		err := services.DeploymentDriftService.DetectAll(ctx)
 		if err != nil {
-			logger.Errorf("sync deployments: %s", err.Error())
+			driftLogger.Errorf("detect deployment drift: %s", err.Error())
 		}
 	})
 
 	if err != nil {
-		logger.Errorf("cron add func failed: %s", err.Error())
+		driftLogger.Errorf("cron add func failed: %s", err.Error())
 	}
 
 	c.Start()
@@ -127,6 +164,8 @@ func (opt *ServeOption) Run(ctx context.Context, args []string) error {
 
 	addCron(ctx)
 
+	go services.DeploymentStatusWatcher.Run(ctx)
+
 	router, err := routes.NewRouter()
 	if err != nil {
 		return err
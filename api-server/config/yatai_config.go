@@ -15,6 +15,8 @@ type YataiServerConfigYaml struct {
 	SessionSecretKey  string `yaml:"session_secret_key"`
 	MigrationDir      string `yaml:"migration_dir"`
 	ReadHeaderTimeout int    `yaml:"read_header_timeout"`
+	// UploadTimeout is the number of seconds after which an unfinished bento or model upload is considered stalled
+	UploadTimeout int `yaml:"upload_timeout"`
 }
 
 type YataiPostgresqlConfigYaml struct {
@@ -35,6 +37,15 @@ type YataiS3ConfigYaml struct {
 	BucketName string `yaml:"bucket_name"`
 }
 
+type YataiLocalStorageConfigYaml struct {
+	RootDir string `yaml:"root_dir"`
+}
+
+type YataiStorageConfigYaml struct {
+	Driver string                       `yaml:"driver"`
+	Local  *YataiLocalStorageConfigYaml `yaml:"local,omitempty"`
+}
+
 type YataiDockerRegistryConfigYaml struct {
 	BentoRepositoryName string `yaml:"bento_repository_name"`
 	ModelRepositoryName string `yaml:"model_repository_name"`
@@ -48,15 +59,28 @@ type YataiDockerImageBuilderConfigYaml struct {
 	Privileged bool `yaml:"privileged"`
 }
 
+type YataiPrometheusConfigYaml struct {
+	// Endpoint is the base url of the prometheus http api, it is used to evaluate the queries of canary rollouts
+	Endpoint string `yaml:"endpoint"`
+}
+
+type YataiSecretsConfigYaml struct {
+	// EncryptionKey encrypts the yatai managed secrets at rest, changing it makes the stored secrets unreadable
+	EncryptionKey string `yaml:"encryption_key"`
+}
+
 type YataiConfigYaml struct {
-	IsSass              bool                      `yaml:"is_sass"`
-	SassDomainSuffix    string                    `yaml:"sass_domain_suffix"`
-	InCluster           bool                      `yaml:"in_cluster"`
-	Server              YataiServerConfigYaml     `yaml:"server"`
-	Postgresql          YataiPostgresqlConfigYaml `yaml:"postgresql"`
-	S3                  *YataiS3ConfigYaml        `yaml:"s3,omitempty"`
-	NewsURL             string                    `yaml:"news_url"`
-	InitializationToken string                    `yaml:"initialization_token"`
+	IsSass              bool                       `yaml:"is_sass"`
+	SassDomainSuffix    string                     `yaml:"sass_domain_suffix"`
+	InCluster           bool                       `yaml:"in_cluster"`
+	Server              YataiServerConfigYaml      `yaml:"server"`
+	Postgresql          YataiPostgresqlConfigYaml  `yaml:"postgresql"`
+	S3                  *YataiS3ConfigYaml         `yaml:"s3,omitempty"`
+	Storage             *YataiStorageConfigYaml    `yaml:"storage,omitempty"`
+	Prometheus          *YataiPrometheusConfigYaml `yaml:"prometheus,omitempty"`
+	Secrets             *YataiSecretsConfigYaml    `yaml:"secrets,omitempty"`
+	NewsURL             string                     `yaml:"news_url"`
+	InitializationToken string                     `yaml:"initialization_token"`
 }
 
 var YataiConfig = &YataiConfigYaml{}
@@ -121,6 +145,15 @@ func PopulateYataiConfig() error {
 		YataiConfig.Server.ReadHeaderTimeout = readHeaderTimeout_
 	}
 
+	uploadTimeout, ok := os.LookupEnv(consts.EnvUploadTimeout)
+	if ok {
+		uploadTimeout_, err := strconv.Atoi(uploadTimeout)
+		if err != nil {
+			return errors.Wrapf(err, "convert %s from env to int", consts.EnvUploadTimeout)
+		}
+		YataiConfig.Server.UploadTimeout = uploadTimeout_
+	}
+
 	initializationToken, ok := os.LookupEnv(consts.EnvInitializationToken)
 	if ok {
 		YataiConfig.InitializationToken = initializationToken
@@ -164,5 +197,37 @@ func PopulateYataiConfig() error {
 		makesureS3IsNotNil()
 		YataiConfig.S3.BucketName = s3BucketName
 	}
+	makesureStorageIsNotNil := func() {
+		if YataiConfig.Storage == nil {
+			YataiConfig.Storage = &YataiStorageConfigYaml{}
+		}
+	}
+	storageDriver, ok := os.LookupEnv(consts.EnvStorageDriver)
+	if ok {
+		makesureStorageIsNotNil()
+		YataiConfig.Storage.Driver = storageDriver
+	}
+	localStorageRootDir, ok := os.LookupEnv(consts.EnvLocalStorageRootDir)
+	if ok {
+		makesureStorageIsNotNil()
+		if YataiConfig.Storage.Local == nil {
+			YataiConfig.Storage.Local = &YataiLocalStorageConfigYaml{}
+		}
+		YataiConfig.Storage.Local.RootDir = localStorageRootDir
+	}
+	prometheusEndpoint, ok := os.LookupEnv(consts.EnvPrometheusEndpoint)
+	if ok {
+		if YataiConfig.Prometheus == nil {
+			YataiConfig.Prometheus = &YataiPrometheusConfigYaml{}
+		}
+		YataiConfig.Prometheus.Endpoint = prometheusEndpoint
+	}
+	secretsEncryptionKey, ok := os.LookupEnv(consts.EnvSecretsEncryptionKey)
+	if ok {
+		if YataiConfig.Secrets == nil {
+			YataiConfig.Secrets = &YataiSecretsConfigYaml{}
+		}
+		YataiConfig.Secrets.EncryptionKey = secretsEncryptionKey
+	}
 	return nil
 }
@@ -14,8 +14,10 @@ import (
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai-schemas/schemasv1"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/consts"
 	"github.com/bentoml/yatai/common/utils"
 )
 
@@ -202,26 +204,36 @@ func (c *bentoController) Upload(ctx *gin.Context) {
 	bodySize := ctx.Request.ContentLength
 
 	err = services.BentoService.Upload(ctx, bento, ctx.Request.Body, bodySize)
+	if err == nil {
+		if expectedChecksum := ctx.GetHeader(consts.YataiChecksumHeaderName); expectedChecksum != "" && expectedChecksum != bento.Checksum {
+			err = errors.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, bento.Checksum)
+		}
+	}
 	if err != nil {
+		uploadErr := err
 		uploadStatus = modelschemas.BentoUploadStatusFailed
+		uploadFinishedReason := uploadErr.Error()
 		now = time.Now()
 		nowPtr = &now
 		bento, err = services.BentoService.Update(ctx, bento, services.UpdateBentoOption{
-			UploadStatus:    &uploadStatus,
-			UploadStartedAt: &nowPtr,
+			UploadStatus:         &uploadStatus,
+			UploadFinishedAt:     &nowPtr,
+			UploadFinishedReason: &uploadFinishedReason,
 		})
 		if err != nil {
 			abortWithError(ctx, err)
 			return
 		}
+		abortWithError(ctx, uploadErr)
+		return
 	}
 
 	uploadStatus = modelschemas.BentoUploadStatusSuccess
 	now = time.Now()
 	nowPtr = &now
 	_, err = services.BentoService.Update(ctx, bento, services.UpdateBentoOption{
-		UploadStatus:    &uploadStatus,
-		UploadStartedAt: &nowPtr,
+		UploadStatus:     &uploadStatus,
+		UploadFinishedAt: &nowPtr,
 	})
 	if err != nil {
 		abortWithError(ctx, err)
@@ -247,10 +259,13 @@ func (c *bentoController) PreSignUploadUrl(ctx *gin.Context, schema *GetBentoSch
 	}
 	if !isNewBentomlCli(ctx) {
 		url_, err := services.BentoService.PreSignUploadUrl(ctx, bento)
-		if err != nil {
+		// the storage drivers that cannot presign urls leave the url empty, the clients then upload through the api-server
+		if err != nil && !errors.Is(err, consts.ErrNoImplemented) {
 			return nil, errors.Wrap(err, "pre sign upload url")
 		}
-		bentoSchema.PresignedUploadUrl = url_.String()
+		if url_ != nil {
+			bentoSchema.PresignedUploadUrl = url_.String()
+		}
 	}
 	bentoSchema.PresignedUrlsDeprecated = true
 	return bentoSchema, nil
@@ -273,10 +288,17 @@ func (c *bentoController) Download(ctx *gin.Context) {
 		abortWithError(ctx, err)
 		return
 	}
-	if err = services.BentoService.Download(ctx, bento, ctx.Writer); err != nil {
+	if bento.Checksum != "" {
+		ctx.Header(consts.YataiChecksumHeaderName, bento.Checksum)
+	}
+	objectInfo, err := services.BentoService.StatObject(ctx, bento)
+	if err != nil {
 		abortWithError(ctx, err)
 		return
 	}
+	serveObject(ctx, objectInfo, func(opt services.StorageGetOption) error {
+		return services.BentoService.DownloadWithOption(ctx, bento, ctx.Writer, opt)
+	})
 }
 
 func (c *bentoController) PreSignDownloadUrl(ctx *gin.Context, schema *GetBentoSchema) (*schemasv1.BentoSchema, error) {
@@ -293,15 +315,75 @@ func (c *bentoController) PreSignDownloadUrl(ctx *gin.Context, schema *GetBentoS
 	}
 	if !isNewBentomlCli(ctx) {
 		url_, err := services.BentoService.PreSignDownloadUrl(ctx, bento)
-		if err != nil {
+		// the storage drivers that cannot presign urls leave the url empty, the clients then download through the api-server
+		if err != nil && !errors.Is(err, consts.ErrNoImplemented) {
 			return nil, errors.Wrap(err, "pre sign download url")
 		}
-		bentoSchema.PresignedDownloadUrl = url_.String()
+		if url_ != nil {
+			bentoSchema.PresignedDownloadUrl = url_.String()
+		}
 	}
 	bentoSchema.PresignedUrlsDeprecated = true
 	return bentoSchema, nil
 }
 
+func (c *bentoController) Delete(ctx *gin.Context, schema *GetBentoSchema) (*schemasv1.BentoSchema, error) {
+	bento, err := schema.GetBento(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, bento); err != nil {
+		return nil, err
+	}
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bentoRepository, err := services.BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+	if err != nil {
+		return nil, err
+	}
+	org, err := services.OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
+	if err != nil {
+		return nil, err
+	}
+	bentoSchema, err := transformersv1.ToBentoSchema(ctx, bento)
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint: ineffassign, staticcheck
+	_, ctx_, df, err := services.StartTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	// the event must be created before the bento is gone, it keeps the resource name in its info
+	_, err = services.EventService.Create(ctx_, services.CreateEventOption{
+		CreatorId:      user.ID,
+		ApiTokenName:   apiTokenName,
+		OrganizationId: &org.ID,
+		ResourceType:   modelschemas.ResourceTypeBento,
+		ResourceId:     bento.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "deleted",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+
+	_, err = services.BentoService.Delete(ctx_, bento)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete bento")
+	}
+	return bentoSchema, nil
+}
+
 func (c *bentoController) StartUpload(ctx *gin.Context, schema *GetBentoSchema) (*schemasv1.BentoSchema, error) {
 	bento, err := schema.GetBento(ctx)
 	if err != nil {
@@ -326,6 +408,7 @@ func (c *bentoController) StartUpload(ctx *gin.Context, schema *GetBentoSchema)
 type FinishUploadBentoSchema struct {
 	schemasv1.FinishUploadBentoSchema
 	GetBentoSchema
+	Checksum *string `json:"checksum"`
 }
 
 func (c *bentoController) FinishUpload(ctx *gin.Context, schema *FinishUploadBentoSchema) (*schemasv1.BentoSchema, error) {
@@ -336,12 +419,29 @@ func (c *bentoController) FinishUpload(ctx *gin.Context, schema *FinishUploadBen
 	if err = c.canUpdate(ctx, bento); err != nil {
 		return nil, err
 	}
+	var checksum *string
+	if schema.Status != nil && *schema.Status == modelschemas.BentoUploadStatusSuccess {
+		// the archive has been uploaded by the client directly, so read it back to make sure it is intact
+		// a read error leaves the upload as it is so that the client can retry, only a mismatch fails it
+		actualChecksum, err := services.BentoService.ComputeChecksum(ctx, bento)
+		if err != nil {
+			return nil, errors.Wrap(err, "compute checksum")
+		}
+		if schema.Checksum != nil && *schema.Checksum != actualChecksum {
+			failedStatus := modelschemas.BentoUploadStatusFailed
+			schema.Status = &failedStatus
+			schema.Reason = utils.StringPtr(fmt.Sprintf("checksum mismatch: expected %s, got %s", *schema.Checksum, actualChecksum))
+		} else {
+			checksum = &actualChecksum
+		}
+	}
 	now := time.Now()
 	nowPtr := &now
 	bento, err = services.BentoService.Update(ctx, bento, services.UpdateBentoOption{
 		UploadStatus:         schema.Status,
 		UploadFinishedAt:     &nowPtr,
 		UploadFinishedReason: schema.Reason,
+		Checksum:             checksum,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "update bento")
@@ -405,7 +505,30 @@ func (c *bentoController) ListImageBuilderPods(ctx *gin.Context, schema *GetBent
 	return transformersv1.ToKubePodSchemas(ctx, majorCluster.ID, pods)
 }
 
-func (c *bentoController) Get(ctx *gin.Context, schema *GetBentoSchema) (*schemasv1.BentoFullSchema, error) {
+func (c *bentoController) Get(ctx *gin.Context, schema *GetBentoSchema) (*schemas.BentoFullSchema, error) {
+	bento, err := schema.GetBento(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, bento); err != nil {
+		return nil, err
+	}
+	bentoSchema, err := transformersv1.ToBentoFullSchema(ctx, bento)
+	if err != nil {
+		return nil, err
+	}
+	return &schemas.BentoFullSchema{
+		BentoFullSchema: *bentoSchema,
+		Checksum:        bento.Checksum,
+	}, nil
+}
+
+type CopyBentoSchema struct {
+	schemas.CopyBentoSchema
+	GetBentoSchema
+}
+
+func (c *bentoController) Copy(ctx *gin.Context, schema *CopyBentoSchema) (*schemasv1.BentoSchema, error) {
 	bento, err := schema.GetBento(ctx)
 	if err != nil {
 		return nil, err
@@ -413,7 +536,97 @@ func (c *bentoController) Get(ctx *gin.Context, schema *GetBentoSchema) (*schema
 	if err = c.canView(ctx, bento); err != nil {
 		return nil, err
 	}
-	return transformersv1.ToBentoFullSchema(ctx, bento)
+	targetOrg, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if schema.TargetOrganizationName != "" {
+		targetOrg, err = services.OrganizationService.GetByName(ctx, schema.TargetOrganizationName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get organization %s", schema.TargetOrganizationName)
+		}
+	}
+	if err = OrganizationController.canUpdate(ctx, targetOrg); err != nil {
+		return nil, err
+	}
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	newBento, err := services.CopyService.CopyBento(ctx, bento, services.CopyBentoOption{
+		CreatorId:                 user.ID,
+		ApiTokenName:              apiTokenName,
+		TargetOrganizationId:      targetOrg.ID,
+		TargetBentoRepositoryName: schema.TargetBentoRepositoryName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "copy bento")
+	}
+	return transformersv1.ToBentoSchema(ctx, newBento)
+}
+
+func (c *bentoController) VerifyChecksum(ctx *gin.Context, schema *GetBentoSchema) (*schemas.ChecksumVerificationSchema, error) {
+	bento, err := schema.GetBento(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, bento); err != nil {
+		return nil, err
+	}
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bentoRepository, err := services.BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+	if err != nil {
+		return nil, err
+	}
+	org, err := services.OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
+	if err != nil {
+		return nil, err
+	}
+	actualChecksum, err := services.BentoService.ComputeChecksum(ctx, bento)
+	if err != nil {
+		return nil, errors.Wrap(err, "compute checksum")
+	}
+	if bento.Checksum == "" {
+		// bentos pushed before checksums were recorded, trust the current content
+		bento, err = services.BentoService.Update(ctx, bento, services.UpdateBentoOption{
+			Checksum: &actualChecksum,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "update bento checksum")
+		}
+	}
+	res := &schemas.ChecksumVerificationSchema{
+		Checksum:       bento.Checksum,
+		ActualChecksum: actualChecksum,
+		Corrupted:      bento.Checksum != actualChecksum,
+	}
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	createEventOpt := services.CreateEventOption{
+		CreatorId:      user.ID,
+		ApiTokenName:   apiTokenName,
+		OrganizationId: &org.ID,
+		ResourceType:   modelschemas.ResourceTypeBento,
+		ResourceId:     bento.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "verified",
+	}
+	if res.Corrupted {
+		createEventOpt.Status = modelschemas.EventStatusFailed
+	}
+	if _, err = services.EventService.Create(ctx, createEventOpt); err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+	return res, nil
 }
 
 type ListBentoDeploymentSchema struct {
@@ -421,7 +634,7 @@ type ListBentoDeploymentSchema struct {
 	GetBentoSchema
 }
 
-func (c *bentoController) ListDeployment(ctx *gin.Context, schema *ListBentoDeploymentSchema) (*schemasv1.DeploymentListSchema, error) {
+func (c *bentoController) ListDeployment(ctx *gin.Context, schema *ListBentoDeploymentSchema) (*schemas.DeploymentListSchema, error) {
 	bento, err := schema.GetBento(ctx)
 	if err != nil {
 		return nil, err
@@ -444,7 +657,7 @@ func (c *bentoController) ListDeployment(ctx *gin.Context, schema *ListBentoDepl
 	if err != nil {
 		return nil, err
 	}
-	return &schemasv1.DeploymentListSchema{
+	return &schemas.DeploymentListSchema{
 		BaseListSchema: schemasv1.BaseListSchema{
 			Total: total,
 			Start: schema.Start,
@@ -681,9 +894,16 @@ func (c *bentoController) UpdateBentoImageBuildStatusSyncingAt(ctx *gin.Context,
 type UpdateBentoImageBuildStatusSchema struct {
 	GetBentoSchema
 	ImageBuildStatus modelschemas.ImageBuildStatus `json:"image_build_status"`
+	PodName          *string                       `json:"pod_name"`
+	Reason           *string                       `json:"reason"`
 }
 
 func (c *bentoController) UpdateBentoImageBuildStatus(ctx *gin.Context, schema *UpdateBentoImageBuildStatusSchema) error {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return err
+	}
+
 	bento, err := schema.GetBento(ctx)
 	if err != nil {
 		return err
@@ -703,5 +923,53 @@ func (c *bentoController) UpdateBentoImageBuildStatus(ctx *gin.Context, schema *
 		return errors.Wrap(err, "update bento")
 	}
 
+	_, err = services.ImageBuildService.RecordStatus(ctx, services.RecordImageBuildStatusOption{
+		CreatorId:     user.ID,
+		Bento:         bento,
+		Status:        schema.ImageBuildStatus,
+		PodName:       schema.PodName,
+		FailureReason: schema.Reason,
+	})
+	if err != nil {
+		return errors.Wrap(err, "record image build status")
+	}
+
 	return nil
 }
+
+func (c *bentoController) ListImageBuilds(ctx *gin.Context, schema *GetBentoSchema) ([]*schemas.ImageBuildSchema, error) {
+	bento, err := schema.GetBento(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, bento); err != nil {
+		return nil, err
+	}
+	builds, _, err := services.ImageBuildService.List(ctx, services.ListImageBuildOption{
+		ResourceType: modelschemas.ResourceTypeBento.Ptr(),
+		ResourceId:   &bento.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list image builds")
+	}
+	return transformersv1.ToImageBuildSchemas(ctx, builds)
+}
+
+func (c *bentoController) RebuildImage(ctx *gin.Context, schema *GetBentoSchema) (*schemas.ImageBuildSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bento, err := schema.GetBento(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, bento); err != nil {
+		return nil, err
+	}
+	build, err := services.ImageBuildService.Rebuild(ctx, user.ID, bento)
+	if err != nil {
+		return nil, errors.Wrap(err, "rebuild image")
+	}
+	return transformersv1.ToImageBuildSchema(ctx, build)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/bentoml/yatai-schemas/schemasv1"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
 	"github.com/bentoml/yatai/common/utils"
@@ -128,12 +129,200 @@ func (c *bentoRepositoryController) Get(ctx *gin.Context, schema *GetBentoReposi
 	return transformersv1.ToBentoRepositorySchema(ctx, bentoRepository)
 }
 
+func (c *bentoRepositoryController) GetRetentionPolicy(ctx *gin.Context, schema *GetBentoRepositorySchema) (*schemas.RetentionPolicySchema, error) {
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	return bentoRepository.RetentionPolicy, nil
+}
+
+type UpdateBentoRepositoryRetentionPolicySchema struct {
+	schemas.UpdateRetentionPolicySchema
+	GetBentoRepositorySchema
+}
+
+func (c *bentoRepositoryController) UpdateRetentionPolicy(ctx *gin.Context, schema *UpdateBentoRepositoryRetentionPolicySchema) (*schemas.RetentionPolicySchema, error) {
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	if err = schema.RetentionPolicy.Validate(); err != nil {
+		return nil, err
+	}
+	bentoRepository, err = services.BentoRepositoryService.Update(ctx, bentoRepository, services.UpdateBentoRepositoryOption{
+		RetentionPolicy: &schema.RetentionPolicy,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "update bentoRepository retention policy")
+	}
+	return bentoRepository.RetentionPolicy, nil
+}
+
+func (c *bentoRepositoryController) ListExpiredBentos(ctx *gin.Context, schema *GetBentoRepositorySchema) ([]*schemasv1.BentoSchema, error) {
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	bentos, err := services.RetentionService.ListExpiredBentos(ctx, bentoRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "list expired bentos")
+	}
+	return transformersv1.ToBentoSchemas(ctx, bentos)
+}
+
+func (c *bentoRepositoryController) ListAliases(ctx *gin.Context, schema *GetBentoRepositorySchema) ([]*schemas.RepositoryAliasSchema, error) {
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	aliases, _, err := services.RepositoryAliasService.List(ctx, services.ListRepositoryAliasOption{
+		ResourceType: bentoRepository.GetResourceType().Ptr(),
+		ResourceId:   &bentoRepository.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list aliases")
+	}
+	return transformersv1.ToRepositoryAliasSchemas(ctx, aliases)
+}
+
+type GetBentoRepositoryAliasSchema struct {
+	GetBentoRepositorySchema
+	AliasName string `path:"aliasName"`
+}
+
+func (s *GetBentoRepositoryAliasSchema) GetAlias(ctx context.Context) (*models.BentoRepository, *models.RepositoryAlias, error) {
+	bentoRepository, err := s.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	alias, err := services.RepositoryAliasService.GetByName(ctx, bentoRepository, s.AliasName)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "get alias %s", s.AliasName)
+	}
+	return bentoRepository, alias, nil
+}
+
+type SetBentoRepositoryAliasSchema struct {
+	schemas.SetRepositoryAliasSchema
+	GetBentoRepositoryAliasSchema
+}
+
+func (c *bentoRepositoryController) SetAlias(ctx *gin.Context, schema *SetBentoRepositoryAliasSchema) (*schemas.RepositoryAliasSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	_, err = services.BentoService.GetByVersion(ctx, bentoRepository.ID, schema.AliasName)
+	if err == nil {
+		return nil, errors.Errorf("alias %s conflicts with an existing version", schema.AliasName)
+	}
+	if !utils.IsNotFound(err) {
+		return nil, err
+	}
+	bento, err := services.BentoService.GetByVersion(ctx, bentoRepository.ID, schema.Version)
+	if err != nil {
+		return nil, err
+	}
+	alias, err := services.RepositoryAliasService.Set(ctx, services.SetRepositoryAliasOption{
+		CreatorId:      user.ID,
+		OrganizationId: bentoRepository.OrganizationId,
+		Repository:     bentoRepository,
+		Name:           schema.AliasName,
+		Target:         bento,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "set alias")
+	}
+	return transformersv1.ToRepositoryAliasSchema(ctx, alias)
+}
+
+func (c *bentoRepositoryController) DeleteAlias(ctx *gin.Context, schema *GetBentoRepositoryAliasSchema) (*schemas.RepositoryAliasSchema, error) {
+	bentoRepository, alias, err := schema.GetAlias(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	aliasSchema, err := transformersv1.ToRepositoryAliasSchema(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+	_, err = services.RepositoryAliasService.Delete(ctx, alias)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete alias")
+	}
+	return aliasSchema, nil
+}
+
+func (c *bentoRepositoryController) ListAliasHistories(ctx *gin.Context, schema *GetBentoRepositoryAliasSchema) ([]*schemas.RepositoryAliasHistorySchema, error) {
+	bentoRepository, alias, err := schema.GetAlias(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	histories, _, err := services.RepositoryAliasService.ListHistories(ctx, services.ListRepositoryAliasHistoryOption{
+		RepositoryAliasId: &alias.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list alias histories")
+	}
+	return transformersv1.ToRepositoryAliasHistorySchemas(ctx, histories)
+}
+
+type DiffBentoSchema struct {
+	GetBentoRepositorySchema
+	From string `query:"from"`
+	To   string `query:"to"`
+}
+
+func (c *bentoRepositoryController) Diff(ctx *gin.Context, schema *DiffBentoSchema) (*schemas.BentoManifestDiffSchema, error) {
+	bentoRepository, err := schema.GetBentoRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, bentoRepository); err != nil {
+		return nil, err
+	}
+	from, err := services.BentoService.GetByVersionOrAlias(ctx, bentoRepository, schema.From)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get bento %s", schema.From)
+	}
+	to, err := services.BentoService.GetByVersionOrAlias(ctx, bentoRepository, schema.To)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get bento %s", schema.To)
+	}
+	return services.BentoService.Diff(ctx, from, to)
+}
+
 type ListBentoRepositoryDeploymentSchema struct {
 	schemasv1.ListQuerySchema
 	GetBentoRepositorySchema
 }
 
-func (c *bentoRepositoryController) ListDeployment(ctx *gin.Context, schema *ListBentoRepositoryDeploymentSchema) (*schemasv1.DeploymentListSchema, error) {
+func (c *bentoRepositoryController) ListDeployment(ctx *gin.Context, schema *ListBentoRepositoryDeploymentSchema) (*schemas.DeploymentListSchema, error) {
 	bentoRepository, err := schema.GetBentoRepository(ctx)
 	if err != nil {
 		return nil, err
@@ -165,7 +354,7 @@ func (c *bentoRepositoryController) ListDeployment(ctx *gin.Context, schema *Lis
 	if err != nil {
 		return nil, err
 	}
-	return &schemasv1.DeploymentListSchema{
+	return &schemas.DeploymentListSchema{
 		BaseListSchema: schemasv1.BaseListSchema{
 			Start: schema.Start,
 			Count: schema.Count,
@@ -0,0 +1,158 @@
+package controllersv1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type canaryRolloutController struct {
+	baseController
+}
+
+var CanaryRolloutController = canaryRolloutController{}
+
+type GetCanaryRolloutSchema struct {
+	GetDeploymentSchema
+	RolloutUid string `path:"rolloutUid"`
+}
+
+func (s *GetCanaryRolloutSchema) GetCanaryRollout(ctx *gin.Context, deployment *models.Deployment) (*models.CanaryRollout, error) {
+	rollout, err := services.CanaryRolloutService.GetByUid(ctx, s.RolloutUid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get canary rollout %s", s.RolloutUid)
+	}
+	if rollout.DeploymentId != deployment.ID {
+		return nil, errors.Errorf("canary rollout %s not found", s.RolloutUid)
+	}
+	return rollout, nil
+}
+
+type CreateCanaryRolloutSchema struct {
+	schemas.CreateCanaryRolloutSchema
+	GetDeploymentSchema
+}
+
+func (c *canaryRolloutController) Create(ctx *gin.Context, schema *CreateCanaryRolloutSchema) (*schemas.CanaryRolloutSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = DeploymentController.canUpdate(ctx, deployment); err != nil {
+		return nil, err
+	}
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	rollout, err := services.CanaryRolloutService.Create(ctx, services.CreateCanaryRolloutOption{
+		CreatorId:    user.ID,
+		ApiTokenName: apiTokenName,
+		Deployment:   deployment,
+		Steps:        schema.Steps,
+		Analysis:     schema.Analysis,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create canary rollout")
+	}
+	return transformersv1.ToCanaryRolloutSchema(ctx, rollout)
+}
+
+type ListCanaryRolloutSchema struct {
+	schemasv1.ListQuerySchema
+	GetDeploymentSchema
+}
+
+func (c *canaryRolloutController) List(ctx *gin.Context, schema *ListCanaryRolloutSchema) ([]*schemas.CanaryRolloutSchema, error) {
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = DeploymentController.canView(ctx, deployment); err != nil {
+		return nil, err
+	}
+	rollouts, _, err := services.CanaryRolloutService.List(ctx, services.ListCanaryRolloutOption{
+		BaseListOption: services.BaseListOption{
+			Start: utils.UintPtr(schema.Start),
+			Count: utils.UintPtr(schema.Count),
+		},
+		DeploymentId: utils.UintPtr(deployment.ID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list canary rollouts")
+	}
+	return transformersv1.ToCanaryRolloutSchemas(ctx, rollouts)
+}
+
+func (c *canaryRolloutController) Get(ctx *gin.Context, schema *GetCanaryRolloutSchema) (*schemas.CanaryRolloutSchema, error) {
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = DeploymentController.canView(ctx, deployment); err != nil {
+		return nil, err
+	}
+	rollout, err := schema.GetCanaryRollout(ctx, deployment)
+	if err != nil {
+		return nil, err
+	}
+	return transformersv1.ToCanaryRolloutSchema(ctx, rollout)
+}
+
+func (c *canaryRolloutController) Promote(ctx *gin.Context, schema *GetCanaryRolloutSchema) (*schemas.CanaryRolloutSchema, error) {
+	return c.finish(ctx, schema, true)
+}
+
+func (c *canaryRolloutController) Abort(ctx *gin.Context, schema *GetCanaryRolloutSchema) (*schemas.CanaryRolloutSchema, error) {
+	return c.finish(ctx, schema, false)
+}
+
+func (c *canaryRolloutController) finish(ctx *gin.Context, schema *GetCanaryRolloutSchema, promote bool) (*schemas.CanaryRolloutSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = DeploymentController.canUpdate(ctx, deployment); err != nil {
+		return nil, err
+	}
+	rollout, err := schema.GetCanaryRollout(ctx, deployment)
+	if err != nil {
+		return nil, err
+	}
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	opt := services.FinishCanaryRolloutOption{
+		CreatorId:    user.ID,
+		ApiTokenName: apiTokenName,
+	}
+	if promote {
+		opt.Reason = "promoted manually by " + user.Name
+		err = services.CanaryRolloutService.Promote(ctx, rollout, opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "promote canary rollout")
+		}
+	} else {
+		opt.Reason = "aborted manually by " + user.Name
+		err = services.CanaryRolloutService.Abort(ctx, rollout, opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "abort canary rollout")
+		}
+	}
+	return transformersv1.ToCanaryRolloutSchema(ctx, rollout)
+}
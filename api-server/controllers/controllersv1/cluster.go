@@ -18,6 +18,7 @@ import (
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai-schemas/schemasv1"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
 	"github.com/bentoml/yatai/common/utils"
@@ -71,11 +72,11 @@ func (c *clusterController) canOperate(ctx context.Context, cluster *models.Clus
 }
 
 type CreateClusterSchema struct {
-	schemasv1.CreateClusterSchema
+	schemas.CreateClusterSchema
 	GetOrganizationSchema
 }
 
-func (c *clusterController) Create(ctx *gin.Context, schema *CreateClusterSchema) (*schemasv1.ClusterFullSchema, error) {
+func (c *clusterController) Create(ctx *gin.Context, schema *CreateClusterSchema) (*schemas.ClusterFullSchema, error) {
 	user, err := services.GetCurrentUser(ctx)
 	if err != nil {
 		return nil, err
@@ -113,11 +114,11 @@ func (c *clusterController) Create(ctx *gin.Context, schema *CreateClusterSchema
 }
 
 type UpdateClusterSchema struct {
-	schemasv1.UpdateClusterSchema
+	schemas.UpdateClusterSchema
 	GetClusterSchema
 }
 
-func (c *clusterController) Update(ctx *gin.Context, schema *UpdateClusterSchema) (*schemasv1.ClusterFullSchema, error) {
+func (c *clusterController) Update(ctx *gin.Context, schema *UpdateClusterSchema) (*schemas.ClusterFullSchema, error) {
 	cluster, err := schema.GetCluster(ctx)
 	if err != nil {
 		return nil, err
@@ -136,7 +137,7 @@ func (c *clusterController) Update(ctx *gin.Context, schema *UpdateClusterSchema
 	return transformersv1.ToClusterFullSchema(ctx, cluster)
 }
 
-func (c *clusterController) Get(ctx *gin.Context, schema *GetClusterSchema) (*schemasv1.ClusterFullSchema, error) {
+func (c *clusterController) Get(ctx *gin.Context, schema *GetClusterSchema) (*schemas.ClusterFullSchema, error) {
 	cluster, err := schema.GetCluster(ctx)
 	if err != nil {
 		return nil, err
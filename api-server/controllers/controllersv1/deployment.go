@@ -23,6 +23,7 @@ import (
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai-schemas/schemasv1"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
 	"github.com/bentoml/yatai/common/sync/errsgroup"
@@ -80,13 +81,11 @@ func (c *deploymentController) canOperate(ctx context.Context, deployment *model
 type CreateDeploymentSchema struct {
 	schemasv1.CreateDeploymentSchema
 	GetClusterSchema
+	// Targets shadows the targets of the embedded schema so that they can reference a deployment preset
+	Targets []*schemas.CreateDeploymentTargetSchema `json:"targets"`
 }
 
-func (c *deploymentController) Create(ctx *gin.Context, schema *CreateDeploymentSchema) (*schemasv1.DeploymentSchema, error) {
-	user, err := services.GetCurrentUser(ctx)
-	if err != nil {
-		return nil, err
-	}
+func (c *deploymentController) Create(ctx *gin.Context, schema *CreateDeploymentSchema) (*schemas.DeploymentSchema, error) {
 	cluster, err := schema.GetCluster(ctx)
 	if err != nil {
 		return nil, err
@@ -100,9 +99,9 @@ func (c *deploymentController) Create(ctx *gin.Context, schema *CreateDeployment
 		return nil, err
 	}
 
-	labels := make(modelschemas.LabelItemsSchema, 0)
-	if schema.Labels != nil {
-		labels = *schema.Labels
+	targets, err := c.resolveTargetPresets(ctx, cluster, schema.Targets)
+	if err != nil {
+		return nil, err
 	}
 
 	kubeNamespace := strings.TrimSpace(schema.KubeNamespace)
@@ -110,6 +109,49 @@ func (c *deploymentController) Create(ctx *gin.Context, schema *CreateDeployment
 		kubeNamespace = services.ClusterService.GetDeploymentKubeNamespace(cluster)
 	}
 
+	return c.doCreate(ctx, schemas.UpdateDeploymentSchema{
+		UpdateDeploymentSchema: schema.UpdateDeploymentSchema,
+		Targets:                targets,
+	}, org, cluster, schema.Name, kubeNamespace)
+}
+
+// resolveTargetPresets expands the deployment presets referenced by the targets into their configs,
+// the targets without a preset nor a config get the default config of the cluster
+func (c *deploymentController) resolveTargetPresets(ctx context.Context, cluster *models.Cluster, targets []*schemas.CreateDeploymentTargetSchema) ([]*schemas.CreateDeploymentTargetSchema, error) {
+	res := make([]*schemas.CreateDeploymentTargetSchema, 0, len(targets))
+	for _, target := range targets {
+		config, err := services.DeploymentPresetService.ResolveConfig(ctx, cluster, target.Preset, target.Config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolve the config of the target of bento %s:%s", target.BentoRepository, target.Bento)
+		}
+		createDeploymentTargetSchema := *target
+		createDeploymentTargetSchema.Preset = ""
+		createDeploymentTargetSchema.Config = config
+		res = append(res, &createDeploymentTargetSchema)
+	}
+	return res, nil
+}
+
+func (c *deploymentController) resolveDeploymentTargetPresets(ctx context.Context, deployment *models.Deployment, schema *schemas.UpdateDeploymentSchema) (err error) {
+	cluster, err := services.ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return errors.Wrap(err, "get associated cluster")
+	}
+	schema.Targets, err = c.resolveTargetPresets(ctx, cluster, schema.Targets)
+	return err
+}
+
+func (c *deploymentController) doCreate(ctx context.Context, schema schemas.UpdateDeploymentSchema, org *models.Organization, cluster *models.Cluster, name, kubeNamespace string) (*schemas.DeploymentSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make(modelschemas.LabelItemsSchema, 0)
+	if schema.Labels != nil {
+		labels = *schema.Labels
+	}
+
 	description := ""
 	if schema.Description != nil {
 		description = *schema.Description
@@ -125,7 +167,7 @@ func (c *deploymentController) Create(ctx *gin.Context, schema *CreateDeployment
 	deployment, err := services.DeploymentService.Create(ctx_, services.CreateDeploymentOption{
 		CreatorId:     user.ID,
 		ClusterId:     cluster.ID,
-		Name:          schema.Name,
+		Name:          name,
 		Description:   description,
 		Labels:        labels,
 		KubeNamespace: kubeNamespace,
@@ -157,17 +199,17 @@ func (c *deploymentController) Create(ctx *gin.Context, schema *CreateDeployment
 		}
 	}()
 
-	deploymentSchema, err := c.doUpdate(ctx_, schema.UpdateDeploymentSchema, org, deployment)
+	deploymentSchema, err := c.doUpdate(ctx_, schema, org, deployment)
 
 	return deploymentSchema, err
 }
 
 type UpdateDeploymentSchema struct {
-	schemasv1.UpdateDeploymentSchema
+	schemas.UpdateDeploymentSchema
 	GetDeploymentSchema
 }
 
-func (c *deploymentController) SyncStatus(ctx *gin.Context, schema *UpdateDeploymentSchema) (*schemasv1.DeploymentSchema, error) {
+func (c *deploymentController) SyncStatus(ctx *gin.Context, schema *UpdateDeploymentSchema) (*schemas.DeploymentSchema, error) {
 	deployment, err := schema.GetDeployment(ctx)
 	if err != nil {
 		return nil, err
@@ -186,7 +228,7 @@ func (c *deploymentController) SyncStatus(ctx *gin.Context, schema *UpdateDeploy
 	return transformersv1.ToDeploymentSchema(ctx, deployment)
 }
 
-func (c *deploymentController) Update(ctx *gin.Context, schema *UpdateDeploymentSchema) (*schemasv1.DeploymentSchema, error) {
+func (c *deploymentController) Update(ctx *gin.Context, schema *UpdateDeploymentSchema) (*schemas.DeploymentSchema, error) {
 	deployment, err := schema.GetDeployment(ctx)
 	if err != nil {
 		return nil, err
@@ -198,6 +240,9 @@ func (c *deploymentController) Update(ctx *gin.Context, schema *UpdateDeployment
 	if err != nil {
 		return nil, err
 	}
+	if err = c.resolveDeploymentTargetPresets(ctx, deployment, &schema.UpdateDeploymentSchema); err != nil {
+		return nil, err
+	}
 
 	// nolint: ineffassign, staticcheck
 	_, ctx_, df, err := services.StartTransaction(ctx)
@@ -218,17 +263,267 @@ func (c *deploymentController) Update(ctx *gin.Context, schema *UpdateDeployment
 	return deploymentSchema, err
 }
 
-func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.UpdateDeploymentSchema, org *models.Organization, deployment *models.Deployment) (*schemasv1.DeploymentSchema, error) {
-	user, err := services.GetCurrentUser(ctx)
+type ApplyDeploymentSchema struct {
+	schemas.UpdateDeploymentSchema
+	GetDeploymentSchema
+}
+
+// Apply creates the deployment or brings it to the desired spec, a new revision is only deployed when the targets differ from the active ones
+func (c *deploymentController) Apply(ctx *gin.Context, schema *ApplyDeploymentSchema) (*schemas.DeploymentSchema, error) {
+	cluster, err := schema.GetCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schema.Targets, err = c.resolveTargetPresets(ctx, cluster, schema.Targets)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doApply(ctx, schema.UpdateDeploymentSchema, org, cluster, schema.DeploymentName, schema.KubeNamespace)
+}
+
+func (c *deploymentController) doApply(ctx context.Context, schema schemas.UpdateDeploymentSchema, org *models.Organization, cluster *models.Cluster, name, kubeNamespace string) (*schemas.DeploymentSchema, error) {
+	deployment, err := services.DeploymentService.GetByName(ctx, cluster.ID, kubeNamespace, name)
+	if utils.IsNotFound(err) {
+		if err = ClusterController.canUpdate(ctx, cluster); err != nil {
+			return nil, err
+		}
+		return c.doCreate(ctx, schema, org, cluster, name, kubeNamespace)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "get deployment %s", name)
+	}
+	if err = c.canUpdate(ctx, deployment); err != nil {
+		return nil, err
+	}
+
+	// nolint: ineffassign, staticcheck
+	_, ctx_, df, err := services.StartTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	deployment, err = services.DeploymentService.Update(ctx_, deployment, services.UpdateDeploymentOption{
+		Description: schema.Description,
+		Labels:      schema.Labels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	unchanged, err := c.isTargetsUnchanged(ctx_, org, deployment, schema.Targets)
+	if err != nil {
+		return nil, err
+	}
+	if unchanged {
+		return transformersv1.ToDeploymentSchema(ctx_, deployment)
+	}
+
+	deploymentSchema, err := c.doUpdate(ctx_, schema, org, deployment)
+	return deploymentSchema, err
+}
+
+// isTargetsUnchanged tells whether the targets are the ones of the active revision, a deployment that is not running is always redeployed
+func (c *deploymentController) isTargetsUnchanged(ctx context.Context, org *models.Organization, deployment *models.Deployment, targets []*schemas.CreateDeploymentTargetSchema) (bool, error) {
+	switch deployment.Status {
+	case modelschemas.DeploymentStatusTerminating, modelschemas.DeploymentStatusTerminated, modelschemas.DeploymentStatusNonDeployed:
+		return false, nil
+	}
+
+	bentosMapping, err := c.resolveTargetBentos(ctx, org, targets)
+	if err != nil {
+		return false, err
+	}
+
+	status := modelschemas.DeploymentRevisionStatusActive
+	activeDeploymentTargets, _, err := services.DeploymentTargetService.List(ctx, services.ListDeploymentTargetOption{
+		DeploymentId:             utils.UintPtr(deployment.ID),
+		DeploymentRevisionStatus: &status,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "list active deployment targets")
+	}
+	if len(activeDeploymentTargets) == 0 {
+		return false, nil
+	}
+
+	desiredDeploymentTargets := make([]*models.DeploymentTarget, 0, len(targets))
+	for _, createDeploymentTargetSchema := range targets {
+		bento := bentosMapping[fmt.Sprintf("%s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)]
+		if bento == nil {
+			return false, errors.Errorf("can't find bento: %s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)
+		}
+		desiredDeploymentTargets = append(desiredDeploymentTargets, &models.DeploymentTarget{
+			BentoAssociate: models.BentoAssociate{
+				BentoId: bento.ID,
+			},
+			Type:        createDeploymentTargetSchema.Type,
+			CanaryRules: createDeploymentTargetSchema.CanaryRules,
+			Config:      createDeploymentTargetSchema.Config,
+			Ingress:     createDeploymentTargetSchema.Ingress,
+		})
+	}
+
+	return services.IsSameDeploymentTargets(activeDeploymentTargets, desiredDeploymentTargets)
+}
+
+func (c *deploymentController) CreateDryRun(ctx *gin.Context, schema *CreateDeploymentSchema) (*schemas.DeploymentDryRunSchema, error) {
+	cluster, err := schema.GetCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = ClusterController.canUpdate(ctx, cluster); err != nil {
+		return nil, err
+	}
+
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := c.resolveTargetPresets(ctx, cluster, schema.Targets)
 	if err != nil {
 		return nil, err
 	}
-	bentoRepositoryNames := make([]string, 0, len(schema.Targets))
-	bentoRepositoryNamesSeen := make(map[string]struct{}, len(schema.Targets))
 
-	bentoVersionsMapping := make(map[string][]string, len(schema.Targets))
+	kubeNamespace := strings.TrimSpace(schema.KubeNamespace)
+	if kubeNamespace == "" {
+		kubeNamespace = services.ClusterService.GetDeploymentKubeNamespace(cluster)
+	}
+
+	err = services.DeploymentService.ValidateCreateOption(services.CreateDeploymentOption{
+		Name:          schema.Name,
+		KubeNamespace: kubeNamespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = services.DeploymentService.GetByName(ctx, cluster.ID, kubeNamespace, schema.Name)
+	if err == nil {
+		return nil, errors.Errorf("deployment %s already exists in namespace %s", schema.Name, kubeNamespace)
+	}
+	if !utils.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "get deployment %s", schema.Name)
+	}
+
+	// the deployment is not persisted, it only carries what the rendering of the BentoDeployment needs
+	deployment := &models.Deployment{
+		ResourceMixin: models.ResourceMixin{
+			Name: schema.Name,
+		},
+		ClusterAssociate: models.ClusterAssociate{
+			ClusterId:              cluster.ID,
+			AssociatedClusterCache: cluster,
+		},
+		KubeNamespace: kubeNamespace,
+	}
+
+	return c.doDryRun(ctx, schemas.UpdateDeploymentSchema{
+		UpdateDeploymentSchema: schema.UpdateDeploymentSchema,
+		Targets:                targets,
+	}, org, deployment)
+}
 
+func (c *deploymentController) UpdateDryRun(ctx *gin.Context, schema *UpdateDeploymentSchema) (*schemas.DeploymentDryRunSchema, error) {
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, deployment); err != nil {
+		return nil, err
+	}
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.resolveDeploymentTargetPresets(ctx, deployment, &schema.UpdateDeploymentSchema); err != nil {
+		return nil, err
+	}
+
+	return c.doDryRun(ctx, schema.UpdateDeploymentSchema, org, deployment)
+}
+
+// doDryRun renders the BentoDeployment of every target and submits it with the server-side dry run, nothing is persisted
+func (c *deploymentController) doDryRun(ctx context.Context, schema schemas.UpdateDeploymentSchema, org *models.Organization, deployment *models.Deployment) (*schemas.DeploymentDryRunSchema, error) {
+	bentosMapping, err := c.resolveTargetBentos(ctx, org, schema.Targets)
+	if err != nil {
+		return nil, err
+	}
+	if err = services.DeploymentTargetService.ValidateIngresses(ctx, deployment, schema.Targets); err != nil {
+		return nil, err
+	}
+
+	res := &schemas.DeploymentDryRunSchema{
+		Targets: make([]*schemas.DeploymentTargetDryRunSchema, 0, len(schema.Targets)),
+	}
 	for _, createDeploymentTargetSchema := range schema.Targets {
+		bento := bentosMapping[fmt.Sprintf("%s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)]
+		if bento == nil {
+			return nil, errors.Errorf("can't find bento: %s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)
+		}
+
+		var config *modelschemas.DeploymentTargetConfig
+		if createDeploymentTargetSchema.Config != nil {
+			config_ := *createDeploymentTargetSchema.Config
+			config_.KubeResourceUid = ""
+			config_.KubeResourceVersion = ""
+			config = &config_
+		}
+		deploymentTarget := &models.DeploymentTarget{
+			DeploymentAssociate: models.DeploymentAssociate{
+				DeploymentId:              deployment.ID,
+				AssociatedDeploymentCache: deployment,
+			},
+			BentoAssociate: models.BentoAssociate{
+				BentoId:              bento.ID,
+				AssociatedBentoCache: bento,
+			},
+			Type:        createDeploymentTargetSchema.Type,
+			CanaryRules: createDeploymentTargetSchema.CanaryRules,
+			Config:      config,
+			Ingress:     createDeploymentTargetSchema.Ingress,
+		}
+
+		dryRunResult, err := services.KubeBentoDeploymentService.DryRun(ctx, deployment, deploymentTarget)
+		if err != nil {
+			return nil, errors.Wrapf(err, "dry run bento %s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)
+		}
+
+		targetSchema := &schemas.DeploymentTargetDryRunSchema{
+			BentoRepository: createDeploymentTargetSchema.BentoRepository,
+			Bento:           bento.Version,
+			Desired:         dryRunResult.Desired,
+			AdmissionError:  dryRunResult.AdmissionError,
+			Warnings:        dryRunResult.Warnings,
+		}
+		var liveSpec interface{} = map[string]interface{}{}
+		if dryRunResult.Live != nil {
+			targetSchema.Live = dryRunResult.Live
+			liveSpec = dryRunResult.Live.Spec
+		}
+		targetSchema.Diff, err = services.DiffKubeObjects(liveSpec, dryRunResult.Desired.Spec)
+		if err != nil {
+			return nil, errors.Wrap(err, "diff kube bento deployment")
+		}
+		res.Targets = append(res.Targets, targetSchema)
+	}
+
+	return res, nil
+}
+
+// resolveTargetBentos maps "repository:version" to the bento of every target, the bento of a target may be given as an alias and is pinned to its version then
+func (c *deploymentController) resolveTargetBentos(ctx context.Context, org *models.Organization, targets []*schemas.CreateDeploymentTargetSchema) (map[string]*models.Bento, error) {
+	bentoRepositoryNames := make([]string, 0, len(targets))
+	bentoRepositoryNamesSeen := make(map[string]struct{}, len(targets))
+
+	bentoVersionsMapping := make(map[string][]string, len(targets))
+
+	for _, createDeploymentTargetSchema := range targets {
 		if _, ok := bentoRepositoryNamesSeen[createDeploymentTargetSchema.BentoRepository]; !ok {
 			bentoRepositoryNames = append(bentoRepositoryNames, createDeploymentTargetSchema.BentoRepository)
 			bentoRepositoryNamesSeen[createDeploymentTargetSchema.BentoRepository] = struct{}{}
@@ -268,6 +563,162 @@ func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.Up
 		}
 	}
 
+	// the bento of a target can also be an alias of the bento repository, it is resolved here so that the deployment target pins the version
+	for _, createDeploymentTargetSchema := range targets {
+		if _, ok := bentosMapping[fmt.Sprintf("%s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)]; ok {
+			continue
+		}
+		bentoRepository, ok := bentoRepositoriesMapping[createDeploymentTargetSchema.BentoRepository]
+		if !ok {
+			continue
+		}
+		bento, err := services.BentoService.GetByVersionOrAlias(ctx, bentoRepository, createDeploymentTargetSchema.Bento)
+		if err != nil {
+			if utils.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "resolve bento %s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)
+		}
+		createDeploymentTargetSchema.Bento = bento.Version
+		bentosMapping[fmt.Sprintf("%s:%s", bentoRepository.Name, bento.Version)] = bento
+	}
+
+	return bentosMapping, nil
+}
+
+// doUpdate deploys a new revision with the targets, on a protected cluster a change request waiting for the approval of another admin is created instead
+func (c *deploymentController) doUpdate(ctx context.Context, schema schemas.UpdateDeploymentSchema, org *models.Organization, deployment *models.Deployment) (*schemas.DeploymentSchema, error) {
+	cluster, err := services.ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated cluster")
+	}
+	if !services.ClusterService.IsProtected(cluster) {
+		return c.doDeploy(ctx, schema, org, deployment)
+	}
+
+	if schema.DoNotDeploy {
+		// only the kube resource version of an active target can be synced without a review, a new revision must be requested
+		bentosMapping, err := c.resolveTargetBentos(ctx, org, schema.Targets)
+		if err != nil {
+			return nil, err
+		}
+		deploymentRevisions, _, err := services.DeploymentRevisionService.List(ctx, services.ListDeploymentRevisionOption{
+			DeploymentId: utils.UintPtr(deployment.ID),
+			Status:       modelschemas.DeploymentRevisionStatusActive.Ptr(),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "list deployment revisions")
+		}
+		synced, err := c.syncKubeResourceVersion(ctx, schema, bentosMapping, deploymentRevisions)
+		if err != nil {
+			return nil, err
+		}
+		if !synced {
+			return nil, errors.Errorf("cluster %s is protected, the revisions of deployment %s can only be changed through a change request", cluster.Name, deployment.Name)
+		}
+		return transformersv1.ToDeploymentSchema(ctx, deployment)
+	}
+
+	// a change that cannot be deployed must not wait for a review
+	if _, err = c.resolveTargetBentos(ctx, org, schema.Targets); err != nil {
+		return nil, err
+	}
+	if err = services.DeploymentTargetService.ValidateIngresses(ctx, deployment, schema.Targets); err != nil {
+		return nil, err
+	}
+	for _, createDeploymentTargetSchema := range schema.Targets {
+		if err = services.ValidateDeploymentTargetConfigEnvs(createDeploymentTargetSchema.Config); err != nil {
+			return nil, errors.Wrapf(err, "the target of bento %s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)
+		}
+	}
+	if err = c.requestChange(ctx, deployment, schemas.DeploymentChangeRequestKindDeploy, schema.Targets); err != nil {
+		return nil, err
+	}
+	return transformersv1.ToDeploymentSchema(ctx, deployment)
+}
+
+func (c *deploymentController) requestChange(ctx context.Context, deployment *models.Deployment, kind schemas.DeploymentChangeRequestKind, targets []*schemas.CreateDeploymentTargetSchema) error {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return err
+	}
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	_, err = services.DeploymentChangeRequestService.Create(ctx, services.CreateDeploymentChangeRequestOption{
+		CreatorId:    user.ID,
+		ApiTokenName: apiTokenName,
+		Deployment:   deployment,
+		Kind:         kind,
+		Targets:      targets,
+	})
+	return errors.Wrap(err, "create deployment change request")
+}
+
+// syncKubeResourceVersion records the kube resource version reported for a target of the active revisions,
+// it returns false when no active target matches so that the caller records a new revision instead
+func (c *deploymentController) syncKubeResourceVersion(ctx context.Context, schema schemas.UpdateDeploymentSchema, bentosMapping map[string]*models.Bento, deploymentRevisions []*models.DeploymentRevision) (bool, error) {
+	for _, deploymentRevision := range deploymentRevisions {
+		deploymentTargets, _, err := services.DeploymentTargetService.List(ctx, services.ListDeploymentTargetOption{
+			DeploymentRevisionId: utils.UintPtr(deploymentRevision.ID),
+		})
+		if err != nil {
+			return false, errors.Wrap(err, "list deployment targets")
+		}
+		for _, deploymentTarget := range deploymentTargets {
+			for _, createDeploymentTargetSchema := range schema.Targets {
+				bento := bentosMapping[fmt.Sprintf("%s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)]
+				if bento == nil {
+					return false, errors.Errorf("can't find bento: %s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)
+				}
+				if deploymentTarget.BentoId != bento.ID {
+					continue
+				}
+				if deploymentTarget.Config == nil {
+					deploymentTarget.Config = &modelschemas.DeploymentTargetConfig{}
+				}
+				if createDeploymentTargetSchema.Config == nil {
+					continue
+				}
+				if createDeploymentTargetSchema.Config.KubeResourceVersion == "" {
+					continue
+				}
+				if deploymentTarget.Config.KubeResourceVersion != "" && deploymentTarget.Config.KubeResourceVersion != createDeploymentTargetSchema.Config.KubeResourceVersion {
+					continue
+				}
+
+				config := deploymentTarget.Config
+				config.KubeResourceUid = createDeploymentTargetSchema.Config.KubeResourceUid
+				config.KubeResourceVersion = createDeploymentTargetSchema.Config.KubeResourceVersion
+
+				_, err = services.DeploymentTargetService.Update(ctx, deploymentTarget, services.UpdateDeploymentTargetOption{
+					Config: &config,
+				})
+				if err != nil {
+					return false, errors.Wrap(err, "update deployment target")
+				}
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// doDeploy creates the revision of the targets and deploys it, it is also how an approved change request is applied
+func (c *deploymentController) doDeploy(ctx context.Context, schema schemas.UpdateDeploymentSchema, org *models.Organization, deployment *models.Deployment) (*schemas.DeploymentSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bentosMapping, err := c.resolveTargetBentos(ctx, org, schema.Targets)
+	if err != nil {
+		return nil, err
+	}
+	if err = services.DeploymentTargetService.ValidateIngresses(ctx, deployment, schema.Targets); err != nil {
+		return nil, err
+	}
+
 	status_ := modelschemas.DeploymentRevisionStatusActive
 	deploymentRevisions, _, err := services.DeploymentRevisionService.List(ctx, services.ListDeploymentRevisionOption{
 		DeploymentId: utils.UintPtr(deployment.ID),
@@ -278,48 +729,12 @@ func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.Up
 	}
 
 	if schema.DoNotDeploy {
-		for _, deploymentRevision := range deploymentRevisions {
-			deploymentTargets, _, err := services.DeploymentTargetService.List(ctx, services.ListDeploymentTargetOption{
-				DeploymentRevisionId: utils.UintPtr(deploymentRevision.ID),
-			})
-			if err != nil {
-				return nil, errors.Wrap(err, "list deployment targets")
-			}
-			for _, deploymentTarget := range deploymentTargets {
-				for _, createDeploymentTargetSchema := range schema.Targets {
-					bento := bentosMapping[fmt.Sprintf("%s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)]
-					if bento == nil {
-						return nil, errors.Errorf("can't find bento: %s:%s", createDeploymentTargetSchema.BentoRepository, createDeploymentTargetSchema.Bento)
-					}
-					if deploymentTarget.BentoId != bento.ID {
-						continue
-					}
-					if deploymentTarget.Config == nil {
-						deploymentTarget.Config = &modelschemas.DeploymentTargetConfig{}
-					}
-					if createDeploymentTargetSchema.Config == nil {
-						continue
-					}
-					if createDeploymentTargetSchema.Config.KubeResourceVersion == "" {
-						continue
-					}
-					if deploymentTarget.Config.KubeResourceVersion != "" && deploymentTarget.Config.KubeResourceVersion != createDeploymentTargetSchema.Config.KubeResourceVersion {
-						continue
-					}
-
-					config := deploymentTarget.Config
-					config.KubeResourceUid = createDeploymentTargetSchema.Config.KubeResourceUid
-					config.KubeResourceVersion = createDeploymentTargetSchema.Config.KubeResourceVersion
-
-					_, err = services.DeploymentTargetService.Update(ctx, deploymentTarget, services.UpdateDeploymentTargetOption{
-						Config: &config,
-					})
-					if err != nil {
-						return nil, errors.Wrap(err, "update deployment target")
-					}
-					return transformersv1.ToDeploymentSchema(ctx, deployment)
-				}
-			}
+		synced, err := c.syncKubeResourceVersion(ctx, schema, bentosMapping, deploymentRevisions)
+		if err != nil {
+			return nil, err
+		}
+		if synced {
+			return transformersv1.ToDeploymentSchema(ctx, deployment)
 		}
 	} else {
 		for _, createDeploymentTargetSchema := range schema.Targets {
@@ -354,6 +769,7 @@ func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.Up
 			Type:                 createDeploymentTargetSchema.Type,
 			CanaryRules:          createDeploymentTargetSchema.CanaryRules,
 			Config:               createDeploymentTargetSchema.Config,
+			Ingress:              createDeploymentTargetSchema.Ingress,
 		})
 		if err != nil {
 			return nil, errors.Wrap(err, "create deployment target")
@@ -383,7 +799,7 @@ func (c *deploymentController) doUpdate(ctx context.Context, schema schemasv1.Up
 	return transformersv1.ToDeploymentSchema(ctx, deployment)
 }
 
-func (c *deploymentController) Get(ctx *gin.Context, schema *GetDeploymentSchema) (*schemasv1.DeploymentSchema, error) {
+func (c *deploymentController) Get(ctx *gin.Context, schema *GetDeploymentSchema) (*schemas.DeploymentSchema, error) {
 	deployment, err := schema.GetDeployment(ctx)
 	if err != nil {
 		return nil, err
@@ -394,7 +810,7 @@ func (c *deploymentController) Get(ctx *gin.Context, schema *GetDeploymentSchema
 	return transformersv1.ToDeploymentSchema(ctx, deployment)
 }
 
-func (c *deploymentController) Terminate(ctx *gin.Context, schema *GetDeploymentSchema) (*schemasv1.DeploymentSchema, error) {
+func (c *deploymentController) Terminate(ctx *gin.Context, schema *GetDeploymentSchema) (*schemas.DeploymentSchema, error) {
 	deployment, err := schema.GetDeployment(ctx)
 	if err != nil {
 		return nil, err
@@ -402,6 +818,16 @@ func (c *deploymentController) Terminate(ctx *gin.Context, schema *GetDeployment
 	if err = c.canOperate(ctx, deployment); err != nil {
 		return nil, err
 	}
+	cluster, err := services.ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated cluster")
+	}
+	if services.ClusterService.IsProtected(cluster) {
+		if err = c.requestChange(ctx, deployment, schemas.DeploymentChangeRequestKindTerminate, nil); err != nil {
+			return nil, err
+		}
+		return transformersv1.ToDeploymentSchema(ctx, deployment)
+	}
 	deployment, err = services.DeploymentService.Terminate(ctx, deployment)
 	if err != nil {
 		return nil, err
@@ -409,7 +835,45 @@ func (c *deploymentController) Terminate(ctx *gin.Context, schema *GetDeployment
 	return transformersv1.ToDeploymentSchema(ctx, deployment)
 }
 
-func (c *deploymentController) Delete(ctx *gin.Context, schema *GetDeploymentSchema) (*schemasv1.DeploymentSchema, error) {
+// Resync re-applies the active revision to the cluster to undo the edits made out of band,
+// on a protected cluster a change request is created instead
+func (c *deploymentController) Resync(ctx *gin.Context, schema *GetDeploymentSchema) (*schemas.DeploymentSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, deployment); err != nil {
+		return nil, err
+	}
+	cluster, err := services.ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated cluster")
+	}
+	if services.ClusterService.IsProtected(cluster) {
+		if err = c.requestChange(ctx, deployment, schemas.DeploymentChangeRequestKindResync, nil); err != nil {
+			return nil, err
+		}
+		return transformersv1.ToDeploymentSchema(ctx, deployment)
+	}
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	err = services.DeploymentDriftService.Resync(ctx, deployment, services.ResyncDeploymentOption{
+		CreatorId:    user.ID,
+		ApiTokenName: apiTokenName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "re-sync deployment")
+	}
+	return transformersv1.ToDeploymentSchema(ctx, deployment)
+}
+
+func (c *deploymentController) Delete(ctx *gin.Context, schema *GetDeploymentSchema) (*schemas.DeploymentSchema, error) {
 	deployment, err := schema.GetDeployment(ctx)
 	if err != nil {
 		return nil, err
@@ -571,7 +1035,7 @@ func fillListDeploymentOption(ctx context.Context, org *models.Organization, lis
 	return nil
 }
 
-func (c *deploymentController) ListClusterDeployments(ctx *gin.Context, schema *ListClusterDeploymentSchema) (*schemasv1.DeploymentListSchema, error) {
+func (c *deploymentController) ListClusterDeployments(ctx *gin.Context, schema *ListClusterDeploymentSchema) (*schemas.DeploymentListSchema, error) {
 	cluster, err := schema.GetCluster(ctx)
 	if err != nil {
 		return nil, err
@@ -606,7 +1070,7 @@ func (c *deploymentController) ListClusterDeployments(ctx *gin.Context, schema *
 	}
 
 	deploymentSchemas, err := transformersv1.ToDeploymentSchemas(ctx, deployments)
-	return &schemasv1.DeploymentListSchema{
+	return &schemas.DeploymentListSchema{
 		BaseListSchema: schemasv1.BaseListSchema{
 			Total: total,
 			Start: schema.Start,
@@ -621,7 +1085,7 @@ type ListOrganizationDeploymentSchema struct {
 	GetOrganizationSchema
 }
 
-func (c *deploymentController) ListOrganizationDeployments(ctx *gin.Context, schema *ListOrganizationDeploymentSchema) (*schemasv1.DeploymentListSchema, error) {
+func (c *deploymentController) ListOrganizationDeployments(ctx *gin.Context, schema *ListOrganizationDeploymentSchema) (*schemas.DeploymentListSchema, error) {
 	organization, err := schema.GetOrganization(ctx)
 	if err != nil {
 		return nil, err
@@ -651,7 +1115,7 @@ func (c *deploymentController) ListOrganizationDeployments(ctx *gin.Context, sch
 	}
 
 	deploymentSchemas, err := transformersv1.ToDeploymentSchemas(ctx, deployments)
-	return &schemasv1.DeploymentListSchema{
+	return &schemas.DeploymentListSchema{
 		BaseListSchema: schemasv1.BaseListSchema{
 			Total: total,
 			Start: schema.Start,
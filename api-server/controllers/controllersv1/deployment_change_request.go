@@ -0,0 +1,198 @@
+package controllersv1
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type deploymentChangeRequestController struct {
+	baseController
+}
+
+var DeploymentChangeRequestController = deploymentChangeRequestController{}
+
+type GetDeploymentChangeRequestSchema struct {
+	GetDeploymentSchema
+	RequestUid string `path:"requestUid"`
+}
+
+func (s *GetDeploymentChangeRequestSchema) GetDeploymentChangeRequest(ctx context.Context, deployment *models.Deployment) (*models.DeploymentChangeRequest, error) {
+	request, err := services.DeploymentChangeRequestService.GetByUid(ctx, s.RequestUid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get deployment change request %s", s.RequestUid)
+	}
+	if request.DeploymentId != deployment.ID {
+		return nil, errors.Errorf("deployment change request %s not found", s.RequestUid)
+	}
+	return request, nil
+}
+
+type ListDeploymentChangeRequestSchema struct {
+	schemasv1.ListQuerySchema
+	GetDeploymentSchema
+	Status schemas.DeploymentChangeRequestStatus `query:"status"`
+}
+
+func (c *deploymentChangeRequestController) List(ctx *gin.Context, schema *ListDeploymentChangeRequestSchema) (*schemas.DeploymentChangeRequestListSchema, error) {
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = DeploymentController.canView(ctx, deployment); err != nil {
+		return nil, err
+	}
+	return c.doList(ctx, schema.ListQuerySchema, services.ListDeploymentChangeRequestOption{
+		DeploymentId: utils.UintPtr(deployment.ID),
+	}, schema.Status)
+}
+
+type ListClusterDeploymentChangeRequestSchema struct {
+	schemasv1.ListQuerySchema
+	GetClusterSchema
+	Status schemas.DeploymentChangeRequestStatus `query:"status"`
+}
+
+// ListClusterChangeRequests lists the change requests of all the deployments of the cluster, it is where the reviewers find the pending ones
+func (c *deploymentChangeRequestController) ListClusterChangeRequests(ctx *gin.Context, schema *ListClusterDeploymentChangeRequestSchema) (*schemas.DeploymentChangeRequestListSchema, error) {
+	cluster, err := schema.GetCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = ClusterController.canView(ctx, cluster); err != nil {
+		return nil, err
+	}
+	return c.doList(ctx, schema.ListQuerySchema, services.ListDeploymentChangeRequestOption{
+		ClusterId: utils.UintPtr(cluster.ID),
+	}, schema.Status)
+}
+
+func (c *deploymentChangeRequestController) doList(ctx context.Context, query schemasv1.ListQuerySchema, opt services.ListDeploymentChangeRequestOption, status schemas.DeploymentChangeRequestStatus) (*schemas.DeploymentChangeRequestListSchema, error) {
+	opt.BaseListOption = services.BaseListOption{
+		Start: utils.UintPtr(query.Start),
+		Count: utils.UintPtr(query.Count),
+	}
+	if status != "" {
+		opt.Status = status.Ptr()
+	}
+	requests, total, err := services.DeploymentChangeRequestService.List(ctx, opt)
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployment change requests")
+	}
+	requestSchemas, err := transformersv1.ToDeploymentChangeRequestSchemas(ctx, requests)
+	return &schemas.DeploymentChangeRequestListSchema{
+		BaseListSchema: schemasv1.BaseListSchema{
+			Total: total,
+			Start: query.Start,
+			Count: query.Count,
+		},
+		Items: requestSchemas,
+	}, err
+}
+
+func (c *deploymentChangeRequestController) Get(ctx *gin.Context, schema *GetDeploymentChangeRequestSchema) (*schemas.DeploymentChangeRequestSchema, error) {
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = DeploymentController.canView(ctx, deployment); err != nil {
+		return nil, err
+	}
+	request, err := schema.GetDeploymentChangeRequest(ctx, deployment)
+	if err != nil {
+		return nil, err
+	}
+	return transformersv1.ToDeploymentChangeRequestSchema(ctx, request)
+}
+
+type ReviewDeploymentChangeRequestSchema struct {
+	schemas.ReviewDeploymentChangeRequestSchema
+	GetDeploymentChangeRequestSchema
+}
+
+// Approve applies the change of the request, the approver must be an admin of the cluster other than the requester
+func (c *deploymentChangeRequestController) Approve(ctx *gin.Context, schema *ReviewDeploymentChangeRequestSchema) (*schemas.DeploymentChangeRequestSchema, error) {
+	return c.review(ctx, schema, true)
+}
+
+func (c *deploymentChangeRequestController) Reject(ctx *gin.Context, schema *ReviewDeploymentChangeRequestSchema) (*schemas.DeploymentChangeRequestSchema, error) {
+	return c.review(ctx, schema, false)
+}
+
+func (c *deploymentChangeRequestController) review(ctx *gin.Context, schema *ReviewDeploymentChangeRequestSchema, approved bool) (requestSchema *schemas.DeploymentChangeRequestSchema, err error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := services.ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated cluster")
+	}
+	if err = ClusterController.canOperate(ctx, cluster); err != nil {
+		return nil, err
+	}
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	request, err := schema.GetDeploymentChangeRequest(ctx, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+
+	// nolint: ineffassign, staticcheck
+	_, ctx_, df, err := services.StartTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	err = services.DeploymentChangeRequestService.Review(ctx_, request, approved, services.ReviewDeploymentChangeRequestOption{
+		ReviewerId:   user.ID,
+		ApiTokenName: apiTokenName,
+		Comment:      schema.Comment,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if approved {
+		switch request.Kind {
+		case schemas.DeploymentChangeRequestKindDeploy:
+			_, err = DeploymentController.doDeploy(ctx_, schemas.UpdateDeploymentSchema{
+				Targets: request.Targets,
+			}, org, deployment)
+		case schemas.DeploymentChangeRequestKindTerminate:
+			_, err = services.DeploymentService.Terminate(ctx_, deployment)
+		case schemas.DeploymentChangeRequestKindResync:
+			err = services.DeploymentDriftService.Resync(ctx_, deployment, services.ResyncDeploymentOption{
+				CreatorId:    user.ID,
+				ApiTokenName: apiTokenName,
+			})
+		default:
+			err = errors.Errorf("unknown deployment change request kind %s", request.Kind)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "apply deployment change request %s", request.Uid)
+		}
+	}
+
+	return transformersv1.ToDeploymentChangeRequestSchema(ctx_, request)
+}
@@ -0,0 +1,292 @@
+package controllersv1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type deploymentManifestController struct {
+	baseController
+}
+
+var DeploymentManifestController = deploymentManifestController{}
+
+func (c *deploymentManifestController) writeManifests(ctx *gin.Context, deployments []*models.Deployment) error {
+	manifests, err := services.DeploymentManifestService.ToManifests(ctx, deployments)
+	if err != nil {
+		return errors.Wrap(err, "get deployment manifests")
+	}
+	content, err := services.DeploymentManifestService.MarshalManifests(manifests)
+	if err != nil {
+		return err
+	}
+
+	ctx.Header("Content-Type", "application/yaml")
+	ctx.Header("Content-Disposition", "attachment; filename=deployments.yaml")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	_, err = ctx.Writer.Write(content)
+	return err
+}
+
+// ExportOrganizationDeployments writes the deployments of all the clusters of the organization as a multi-document yaml stream
+func (c *deploymentManifestController) ExportOrganizationDeployments(ctx *gin.Context, schema *GetOrganizationSchema) error {
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return err
+	}
+	if err = OrganizationController.canView(ctx, org); err != nil {
+		return err
+	}
+
+	deployments, _, err := services.DeploymentService.List(ctx, services.ListDeploymentOption{
+		OrganizationId: utils.UintPtr(org.ID),
+	})
+	if err != nil {
+		return errors.Wrap(err, "list deployments")
+	}
+	return c.writeManifests(ctx, deployments)
+}
+
+// ExportClusterDeployments writes the deployments of the cluster as a multi-document yaml stream
+func (c *deploymentManifestController) ExportClusterDeployments(ctx *gin.Context, schema *GetClusterSchema) error {
+	cluster, err := schema.GetCluster(ctx)
+	if err != nil {
+		return err
+	}
+	if err = ClusterController.canView(ctx, cluster); err != nil {
+		return err
+	}
+
+	deployments, _, err := services.DeploymentService.List(ctx, services.ListDeploymentOption{
+		ClusterId: utils.UintPtr(cluster.ID),
+	})
+	if err != nil {
+		return errors.Wrap(err, "list deployments")
+	}
+	return c.writeManifests(ctx, deployments)
+}
+
+type ImportOrganizationDeploymentManifestsSchema struct {
+	schemas.ImportDeploymentManifestsSchema
+	GetOrganizationSchema
+}
+
+// ImportOrganizationDeployments applies the manifests to the clusters named in their metadata
+func (c *deploymentManifestController) ImportOrganizationDeployments(ctx *gin.Context, schema *ImportOrganizationDeploymentManifestsSchema) (*schemas.DeploymentManifestPlanSchema, error) {
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := make(map[string]*models.Cluster)
+	getCluster := func(manifest *schemas.DeploymentManifestSchema) (*models.Cluster, error) {
+		if manifest.Metadata.Cluster == "" {
+			return nil, errors.Errorf("metadata.cluster of deployment %s cannot be empty", manifest.Metadata.Name)
+		}
+		if cluster, ok := clusters[manifest.Metadata.Cluster]; ok {
+			return cluster, nil
+		}
+		cluster, err := services.ClusterService.GetByName(ctx, org.ID, manifest.Metadata.Cluster)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get cluster %s", manifest.Metadata.Cluster)
+		}
+		clusters[manifest.Metadata.Cluster] = cluster
+		return cluster, nil
+	}
+
+	return c.doImport(ctx, org, schema.ImportDeploymentManifestsSchema, getCluster)
+}
+
+type ImportClusterDeploymentManifestsSchema struct {
+	schemas.ImportDeploymentManifestsSchema
+	GetClusterSchema
+}
+
+// ImportClusterDeployments applies all the manifests to the cluster whatever their metadata.cluster is,
+// this is how the deployments of a lost cluster are rebuilt in a new one
+func (c *deploymentManifestController) ImportClusterDeployments(ctx *gin.Context, schema *ImportClusterDeploymentManifestsSchema) (*schemas.DeploymentManifestPlanSchema, error) {
+	cluster, err := schema.GetCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doImport(ctx, org, schema.ImportDeploymentManifestsSchema, func(*schemas.DeploymentManifestSchema) (*models.Cluster, error) {
+		return cluster, nil
+	})
+}
+
+type deploymentManifestImportItem struct {
+	plan    *schemas.DeploymentManifestPlanItemSchema
+	cluster *models.Cluster
+	schema  schemas.UpdateDeploymentSchema
+}
+
+// doImport plans every manifest before applying any of them, so an invalid manifest or a missing bento leaves the deployments untouched
+func (c *deploymentManifestController) doImport(ctx context.Context, org *models.Organization, schema schemas.ImportDeploymentManifestsSchema, getCluster func(*schemas.DeploymentManifestSchema) (*models.Cluster, error)) (*schemas.DeploymentManifestPlanSchema, error) {
+	items := make([]*deploymentManifestImportItem, 0, len(schema.Manifests))
+	seen := make(map[string]struct{}, len(schema.Manifests))
+	for _, manifest := range schema.Manifests {
+		if err := manifest.Validate(); err != nil {
+			return nil, err
+		}
+		cluster, err := getCluster(manifest)
+		if err != nil {
+			return nil, err
+		}
+		key := fmt.Sprintf("%s/%s/%s", cluster.Name, manifest.Metadata.Namespace, manifest.Metadata.Name)
+		if _, ok := seen[key]; ok {
+			return nil, errors.Errorf("deployment %s is declared more than once", key)
+		}
+		seen[key] = struct{}{}
+
+		item, err := c.plan(ctx, org, cluster, manifest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "plan deployment %s", key)
+		}
+		items = append(items, item)
+	}
+
+	res := &schemas.DeploymentManifestPlanSchema{
+		DryRun: schema.DryRun,
+		Items:  make([]*schemas.DeploymentManifestPlanItemSchema, 0, len(items)),
+	}
+	for _, item := range items {
+		res.Items = append(res.Items, item.plan)
+	}
+	if schema.DryRun {
+		return res, nil
+	}
+
+	for _, item := range items {
+		if item.plan.Action == schemas.DeploymentManifestPlanActionUnchanged {
+			continue
+		}
+		_, err := DeploymentController.doApply(ctx, item.schema, org, item.cluster, item.plan.Name, item.plan.Namespace)
+		if err != nil {
+			return nil, errors.Wrapf(err, "apply deployment %s/%s/%s", item.plan.Cluster, item.plan.Namespace, item.plan.Name)
+		}
+	}
+	return res, nil
+}
+
+func (c *deploymentManifestController) plan(ctx context.Context, org *models.Organization, cluster *models.Cluster, manifest *schemas.DeploymentManifestSchema) (*deploymentManifestImportItem, error) {
+	labels := manifest.Metadata.Labels
+	if labels == nil {
+		labels = make(modelschemas.LabelItemsSchema, 0)
+	}
+	description := manifest.Metadata.Description
+	targets := make([]*schemas.CreateDeploymentTargetSchema, 0, len(manifest.Spec.Targets))
+	for _, target := range manifest.Spec.Targets {
+		bentoRepositoryName, version, err := target.GetBentoRepositoryAndVersion()
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, &schemas.CreateDeploymentTargetSchema{
+			CreateDeploymentTargetSchema: schemasv1.CreateDeploymentTargetSchema{
+				DeploymentTargetTypeSchema: schemasv1.DeploymentTargetTypeSchema{
+					Type: target.Type,
+				},
+				BentoRepository: bentoRepositoryName,
+				Bento:           version,
+				CanaryRules:     target.CanaryRules,
+				Config:          target.Config,
+			},
+			Preset:  target.Preset,
+			Ingress: target.Ingress,
+		})
+	}
+	resolvedTargets, err := DeploymentController.resolveTargetPresets(ctx, cluster, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &deploymentManifestImportItem{
+		plan: &schemas.DeploymentManifestPlanItemSchema{
+			Cluster:   cluster.Name,
+			Namespace: manifest.Metadata.Namespace,
+			Name:      manifest.Metadata.Name,
+		},
+		cluster: cluster,
+		schema: schemas.UpdateDeploymentSchema{
+			UpdateDeploymentSchema: schemasv1.UpdateDeploymentSchema{
+				Labels:      &labels,
+				Description: &description,
+			},
+			Targets: resolvedTargets,
+		},
+	}
+
+	deployment, err := services.DeploymentService.GetByName(ctx, cluster.ID, manifest.Metadata.Namespace, manifest.Metadata.Name)
+	if utils.IsNotFound(err) {
+		if err = ClusterController.canUpdate(ctx, cluster); err != nil {
+			return nil, err
+		}
+		// resolving the bentos here reports a missing bento before anything is applied
+		if _, err = DeploymentController.resolveTargetBentos(ctx, org, resolvedTargets); err != nil {
+			return nil, err
+		}
+		item.plan.Action = schemas.DeploymentManifestPlanActionCreate
+		item.plan.TargetsChanged = true
+		return item, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get deployment")
+	}
+	if err = DeploymentController.canUpdate(ctx, deployment); err != nil {
+		return nil, err
+	}
+
+	unchanged, err := DeploymentController.isTargetsUnchanged(ctx, org, deployment, resolvedTargets)
+	if err != nil {
+		return nil, err
+	}
+	item.plan.TargetsChanged = !unchanged
+
+	oldLabels, err := services.LabelService.ListLabelItemsByResource(ctx, org.ID, deployment)
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployment labels")
+	}
+	if !unchanged || deployment.Description != description || !isSameLabelItems(oldLabels, labels) {
+		item.plan.Action = schemas.DeploymentManifestPlanActionUpdate
+	} else {
+		item.plan.Action = schemas.DeploymentManifestPlanActionUnchanged
+	}
+	return item, nil
+}
+
+func isSameLabelItems(a, b modelschemas.LabelItemsSchema) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	toStrings := func(items modelschemas.LabelItemsSchema) []string {
+		res := make([]string, 0, len(items))
+		for _, item := range items {
+			res = append(res, item.Key+"="+item.Value)
+		}
+		sort.Strings(res)
+		return res
+	}
+	aStrings, bStrings := toStrings(a), toStrings(b)
+	for idx := range aStrings {
+		if aStrings[idx] != bStrings[idx] {
+			return false
+		}
+	}
+	return true
+}
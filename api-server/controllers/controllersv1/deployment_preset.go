@@ -0,0 +1,250 @@
+package controllersv1
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type deploymentPresetController struct {
+	baseController
+}
+
+var DeploymentPresetController = deploymentPresetController{}
+
+func (c *deploymentPresetController) getPreset(ctx context.Context, scope *resourceScope, name string) (*models.DeploymentPreset, error) {
+	preset, err := services.DeploymentPresetService.GetByName(ctx, scope.org.ID, scope.getClusterId(), name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get deployment preset %s", name)
+	}
+	return preset, nil
+}
+
+func (c *deploymentPresetController) doList(ctx context.Context, scope *resourceScope, query schemasv1.ListQuerySchema) (*schemas.DeploymentPresetListSchema, error) {
+	if err := scope.canView(ctx); err != nil {
+		return nil, err
+	}
+	presets, total, err := services.DeploymentPresetService.List(ctx, services.ListDeploymentPresetOption{
+		BaseListOption: services.BaseListOption{
+			Start: utils.UintPtr(query.Start),
+			Count: utils.UintPtr(query.Count),
+		},
+		OrganizationId: utils.UintPtr(scope.org.ID),
+		ClusterId:      scope.getClusterId(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployment presets")
+	}
+	presetSchemas, err := transformersv1.ToDeploymentPresetSchemas(ctx, presets)
+	return &schemas.DeploymentPresetListSchema{
+		BaseListSchema: schemasv1.BaseListSchema{
+			Total: total,
+			Start: query.Start,
+			Count: query.Count,
+		},
+		Items: presetSchemas,
+	}, err
+}
+
+func (c *deploymentPresetController) doCreate(ctx context.Context, scope *resourceScope, schema schemas.CreateDeploymentPresetSchema) (*schemas.DeploymentPresetSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = scope.canOperate(ctx); err != nil {
+		return nil, err
+	}
+	preset, err := services.DeploymentPresetService.Create(ctx, services.CreateDeploymentPresetOption{
+		CreatorId:      user.ID,
+		OrganizationId: scope.org.ID,
+		ClusterId:      scope.getClusterId(),
+		Name:           schema.Name,
+		Description:    schema.Description,
+		Config:         schema.Config,
+		IsDefault:      schema.IsDefault,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create deployment preset")
+	}
+	return transformersv1.ToDeploymentPresetSchema(ctx, preset)
+}
+
+func (c *deploymentPresetController) doGet(ctx context.Context, scope *resourceScope, name string) (*schemas.DeploymentPresetSchema, error) {
+	if err := scope.canView(ctx); err != nil {
+		return nil, err
+	}
+	preset, err := c.getPreset(ctx, scope, name)
+	if err != nil {
+		return nil, err
+	}
+	return transformersv1.ToDeploymentPresetSchema(ctx, preset)
+}
+
+func (c *deploymentPresetController) doUpdate(ctx context.Context, scope *resourceScope, name string, schema schemas.UpdateDeploymentPresetSchema) (*schemas.DeploymentPresetSchema, error) {
+	if err := scope.canOperate(ctx); err != nil {
+		return nil, err
+	}
+	preset, err := c.getPreset(ctx, scope, name)
+	if err != nil {
+		return nil, err
+	}
+	opt := services.UpdateDeploymentPresetOption{
+		Description: schema.Description,
+		IsDefault:   schema.IsDefault,
+	}
+	if schema.Config != nil {
+		opt.Config = &schema.Config
+	}
+	preset, err = services.DeploymentPresetService.Update(ctx, preset, opt)
+	if err != nil {
+		return nil, errors.Wrap(err, "update deployment preset")
+	}
+	return transformersv1.ToDeploymentPresetSchema(ctx, preset)
+}
+
+func (c *deploymentPresetController) doDelete(ctx context.Context, scope *resourceScope, name string) (*schemas.DeploymentPresetSchema, error) {
+	if err := scope.canOperate(ctx); err != nil {
+		return nil, err
+	}
+	preset, err := c.getPreset(ctx, scope, name)
+	if err != nil {
+		return nil, err
+	}
+	presetSchema, err := transformersv1.ToDeploymentPresetSchema(ctx, preset)
+	if err != nil {
+		return nil, err
+	}
+	_, err = services.DeploymentPresetService.Delete(ctx, preset)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete deployment preset")
+	}
+	return presetSchema, nil
+}
+
+type ListOrganizationDeploymentPresetSchema struct {
+	schemasv1.ListQuerySchema
+	GetOrganizationSchema
+}
+
+func (c *deploymentPresetController) ListOrganizationPresets(ctx *gin.Context, schema *ListOrganizationDeploymentPresetSchema) (*schemas.DeploymentPresetListSchema, error) {
+	scope, err := getOrganizationResourceScope(ctx, &schema.GetOrganizationSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doList(ctx, scope, schema.ListQuerySchema)
+}
+
+type CreateOrganizationDeploymentPresetSchema struct {
+	schemas.CreateDeploymentPresetSchema
+	GetOrganizationSchema
+}
+
+func (c *deploymentPresetController) CreateOrganizationPreset(ctx *gin.Context, schema *CreateOrganizationDeploymentPresetSchema) (*schemas.DeploymentPresetSchema, error) {
+	scope, err := getOrganizationResourceScope(ctx, &schema.GetOrganizationSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doCreate(ctx, scope, schema.CreateDeploymentPresetSchema)
+}
+
+type GetOrganizationDeploymentPresetSchema struct {
+	GetOrganizationSchema
+	PresetName string `path:"presetName"`
+}
+
+func (c *deploymentPresetController) GetOrganizationPreset(ctx *gin.Context, schema *GetOrganizationDeploymentPresetSchema) (*schemas.DeploymentPresetSchema, error) {
+	scope, err := getOrganizationResourceScope(ctx, &schema.GetOrganizationSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doGet(ctx, scope, schema.PresetName)
+}
+
+type UpdateOrganizationDeploymentPresetSchema struct {
+	schemas.UpdateDeploymentPresetSchema
+	GetOrganizationDeploymentPresetSchema
+}
+
+func (c *deploymentPresetController) UpdateOrganizationPreset(ctx *gin.Context, schema *UpdateOrganizationDeploymentPresetSchema) (*schemas.DeploymentPresetSchema, error) {
+	scope, err := getOrganizationResourceScope(ctx, &schema.GetOrganizationSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doUpdate(ctx, scope, schema.PresetName, schema.UpdateDeploymentPresetSchema)
+}
+
+func (c *deploymentPresetController) DeleteOrganizationPreset(ctx *gin.Context, schema *GetOrganizationDeploymentPresetSchema) (*schemas.DeploymentPresetSchema, error) {
+	scope, err := getOrganizationResourceScope(ctx, &schema.GetOrganizationSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doDelete(ctx, scope, schema.PresetName)
+}
+
+type ListClusterDeploymentPresetSchema struct {
+	schemasv1.ListQuerySchema
+	GetClusterSchema
+}
+
+func (c *deploymentPresetController) ListClusterPresets(ctx *gin.Context, schema *ListClusterDeploymentPresetSchema) (*schemas.DeploymentPresetListSchema, error) {
+	scope, err := getClusterResourceScope(ctx, &schema.GetClusterSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doList(ctx, scope, schema.ListQuerySchema)
+}
+
+type CreateClusterDeploymentPresetSchema struct {
+	schemas.CreateDeploymentPresetSchema
+	GetClusterSchema
+}
+
+func (c *deploymentPresetController) CreateClusterPreset(ctx *gin.Context, schema *CreateClusterDeploymentPresetSchema) (*schemas.DeploymentPresetSchema, error) {
+	scope, err := getClusterResourceScope(ctx, &schema.GetClusterSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doCreate(ctx, scope, schema.CreateDeploymentPresetSchema)
+}
+
+type GetClusterDeploymentPresetSchema struct {
+	GetClusterSchema
+	PresetName string `path:"presetName"`
+}
+
+func (c *deploymentPresetController) GetClusterPreset(ctx *gin.Context, schema *GetClusterDeploymentPresetSchema) (*schemas.DeploymentPresetSchema, error) {
+	scope, err := getClusterResourceScope(ctx, &schema.GetClusterSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doGet(ctx, scope, schema.PresetName)
+}
+
+type UpdateClusterDeploymentPresetSchema struct {
+	schemas.UpdateDeploymentPresetSchema
+	GetClusterDeploymentPresetSchema
+}
+
+func (c *deploymentPresetController) UpdateClusterPreset(ctx *gin.Context, schema *UpdateClusterDeploymentPresetSchema) (*schemas.DeploymentPresetSchema, error) {
+	scope, err := getClusterResourceScope(ctx, &schema.GetClusterSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doUpdate(ctx, scope, schema.PresetName, schema.UpdateDeploymentPresetSchema)
+}
+
+func (c *deploymentPresetController) DeleteClusterPreset(ctx *gin.Context, schema *GetClusterDeploymentPresetSchema) (*schemas.DeploymentPresetSchema, error) {
+	scope, err := getClusterResourceScope(ctx, &schema.GetClusterSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doDelete(ctx, scope, schema.PresetName)
+}
@@ -0,0 +1,121 @@
+package controllersv1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+type PromoteDeploymentSchema struct {
+	schemas.PromoteDeploymentSchema
+	GetDeploymentSchema
+}
+
+// Promote recreates the active targets of the deployment in another cluster of the organization,
+// the destination is applied like a manifest import so that promoting twice only redeploys what changed
+func (c *deploymentController) Promote(ctx *gin.Context, schema *PromoteDeploymentSchema) (deploymentSchema *schemas.DeploymentSchema, err error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, deployment); err != nil {
+		return nil, err
+	}
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sourceCluster, err := services.ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated cluster")
+	}
+
+	if schema.PromoteDeploymentSchema.Cluster == "" {
+		return nil, errors.New("the destination cluster cannot be empty")
+	}
+	cluster, err := services.ClusterService.GetByName(ctx, org.ID, schema.PromoteDeploymentSchema.Cluster)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get cluster %s", schema.PromoteDeploymentSchema.Cluster)
+	}
+	if err = ClusterController.canUpdate(ctx, cluster); err != nil {
+		return nil, err
+	}
+
+	kubeNamespace := strings.TrimSpace(schema.PromoteDeploymentSchema.KubeNamespace)
+	if kubeNamespace == "" {
+		kubeNamespace = services.ClusterService.GetDeploymentKubeNamespace(cluster)
+	}
+	name := strings.TrimSpace(schema.Name)
+	if name == "" {
+		name = deployment.Name
+	}
+	if cluster.ID == deployment.ClusterId && kubeNamespace == deployment.KubeNamespace && name == deployment.Name {
+		return nil, errors.Errorf("deployment %s cannot be promoted to itself", deployment.Name)
+	}
+
+	manifests, err := services.DeploymentManifestService.ToManifests(ctx, []*models.Deployment{deployment})
+	if err != nil {
+		return nil, errors.Wrap(err, "get deployment manifest")
+	}
+	if len(manifests) == 0 {
+		return nil, errors.Errorf("deployment %s has no active revision to promote", deployment.Name)
+	}
+	manifest := manifests[0]
+	manifest.Metadata.Cluster = cluster.Name
+	manifest.Metadata.Namespace = kubeNamespace
+	manifest.Metadata.Name = name
+	for _, target := range manifest.Spec.Targets {
+		target.Config = services.ApplyDeploymentPromotionOverrides(target.Config, schema.Overrides)
+		target.Ingress = nil
+		if schema.Overrides != nil && target.Type != modelschemas.DeploymentTargetTypeCanary {
+			target.Ingress = schema.Overrides.Ingress
+		}
+	}
+
+	item, err := DeploymentManifestController.plan(ctx, org, cluster, manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "plan deployment %s/%s/%s", cluster.Name, kubeNamespace, name)
+	}
+
+	// nolint: ineffassign, staticcheck
+	_, ctx_, df, err := services.StartTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	deploymentSchema, err = c.doApply(ctx_, item.schema, org, cluster, name, kubeNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	_, err = services.EventService.Create(ctx_, services.CreateEventOption{
+		CreatorId:      user.ID,
+		ApiTokenName:   apiTokenName,
+		OrganizationId: &org.ID,
+		ClusterId:      &sourceCluster.ID,
+		ResourceType:   modelschemas.ResourceTypeDeployment,
+		ResourceId:     deployment.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  fmt.Sprintf("promoted to %s/%s/%s", cluster.Name, kubeNamespace, name),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+	return deploymentSchema, nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
 	"github.com/bentoml/yatai/common/utils"
@@ -80,3 +81,58 @@ func (c *deploymentRevisionController) Get(ctx *gin.Context, schema *GetDeployme
 
 	return transformersv1.ToDeploymentRevisionSchema(ctx, deploymentRevision)
 }
+
+// Rollback redeploys the targets of a previous revision, on a protected cluster a change request waiting for the approval of another admin is created instead
+func (c *deploymentRevisionController) Rollback(ctx *gin.Context, schema *GetDeploymentRevisionSchema) (*schemas.DeploymentSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := schema.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = DeploymentController.canUpdate(ctx, deployment); err != nil {
+		return nil, err
+	}
+
+	deploymentRevision, err := services.DeploymentRevisionService.GetByUid(ctx, schema.RevisionUid)
+	if err != nil {
+		return nil, errors.Wrap(err, "get deploymentRevision")
+	}
+
+	if deploymentRevision.DeploymentId != deployment.ID {
+		return nil, errors.New("deploymentRevision not found")
+	}
+
+	cluster, err := services.ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated cluster")
+	}
+	if services.ClusterService.IsProtected(cluster) {
+		targets, err := services.DeploymentRevisionService.GetRollbackTargets(ctx, deploymentRevision)
+		if err != nil {
+			return nil, err
+		}
+		if err = DeploymentController.requestChange(ctx, deployment, schemas.DeploymentChangeRequestKindDeploy, targets); err != nil {
+			return nil, err
+		}
+		return transformersv1.ToDeploymentSchema(ctx, deployment)
+	}
+
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	_, err = services.DeploymentRevisionService.Rollback(ctx, deploymentRevision, services.RollbackDeploymentRevisionOption{
+		CreatorId:    user.ID,
+		ApiTokenName: apiTokenName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "rollback deploymentRevision")
+	}
+
+	return transformersv1.ToDeploymentSchema(ctx, deployment)
+}
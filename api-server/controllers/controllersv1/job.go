@@ -0,0 +1,394 @@
+package controllersv1
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type jobController struct {
+	baseController
+}
+
+var JobController = jobController{}
+
+type GetJobSchema struct {
+	GetClusterSchema
+	JobName       string `path:"jobName"`
+	KubeNamespace string `path:"kubeNamespace"`
+}
+
+func (s *GetJobSchema) GetJob(ctx context.Context) (*models.Job, error) {
+	cluster, err := s.GetCluster(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get cluster")
+	}
+	job, err := services.JobService.GetByName(ctx, cluster.ID, s.KubeNamespace, s.JobName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get job %s", s.JobName)
+	}
+	return job, nil
+}
+
+func (c *jobController) canView(ctx context.Context, job *models.Job) error {
+	cluster, err := services.ClusterService.GetAssociatedCluster(ctx, job)
+	if err != nil {
+		return errors.Wrap(err, "get associated cluster")
+	}
+	return ClusterController.canView(ctx, cluster)
+}
+
+func (c *jobController) canUpdate(ctx context.Context, job *models.Job) error {
+	cluster, err := services.ClusterService.GetAssociatedCluster(ctx, job)
+	if err != nil {
+		return errors.Wrap(err, "get associated cluster")
+	}
+	return ClusterController.canUpdate(ctx, cluster)
+}
+
+func (c *jobController) canOperate(ctx context.Context, job *models.Job) error {
+	cluster, err := services.ClusterService.GetAssociatedCluster(ctx, job)
+	if err != nil {
+		return errors.Wrap(err, "get associated cluster")
+	}
+	return ClusterController.canOperate(ctx, cluster)
+}
+
+func (c *jobController) getBento(ctx context.Context, org *models.Organization, bentoRepositoryName, versionOrAlias string) (*models.Bento, error) {
+	bentoRepository, err := services.BentoRepositoryService.GetByName(ctx, org.ID, bentoRepositoryName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get bento repository %s", bentoRepositoryName)
+	}
+	bento, err := services.BentoService.GetByVersionOrAlias(ctx, bentoRepository, versionOrAlias)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get bento %s:%s", bentoRepositoryName, versionOrAlias)
+	}
+	return bento, nil
+}
+
+type CreateJobSchema struct {
+	schemas.CreateJobSchema
+	GetClusterSchema
+}
+
+// Create runs a one-off job right away, a cron job waits for its schedule
+func (c *jobController) Create(ctx *gin.Context, schema *CreateJobSchema) (jobSchema *schemas.JobSchema, err error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := schema.GetCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = ClusterController.canUpdate(ctx, cluster); err != nil {
+		return nil, err
+	}
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bento, err := c.getBento(ctx, org, schema.BentoRepository, schema.Bento)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeNamespace := strings.TrimSpace(schema.KubeNamespace)
+	if kubeNamespace == "" {
+		kubeNamespace = services.ClusterService.GetDeploymentKubeNamespace(cluster)
+	}
+	jobType := schema.Type
+	if jobType == "" {
+		jobType = schemas.JobTypeJob
+	}
+
+	// nolint: ineffassign, staticcheck
+	_, ctx_, df, err := services.StartTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	job, err := services.JobService.Create(ctx_, services.CreateJobOption{
+		CreatorId:     user.ID,
+		ClusterId:     cluster.ID,
+		BentoId:       bento.ID,
+		KubeNamespace: kubeNamespace,
+		Name:          schema.Name,
+		Description:   schema.Description,
+		Type:          jobType,
+		Schedule:      schema.Schedule,
+		Suspend:       schema.Suspend,
+		Config:        schema.Config,
+		Labels:        schema.Labels,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create job")
+	}
+
+	if err = c.createEvent(ctx_, user, job, "created"); err != nil {
+		return nil, err
+	}
+
+	if job.Type == schemas.JobTypeCronJob {
+		err = services.JobService.Apply(ctx_, job)
+	} else {
+		_, err = services.JobService.Run(ctx_, job, c.getRunJobOption(user))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return transformersv1.ToJobSchema(ctx_, job)
+}
+
+type UpdateJobSchema struct {
+	schemas.UpdateJobSchema
+	GetJobSchema
+}
+
+// Update changes the next runs of the job, the running ones are not touched
+func (c *jobController) Update(ctx *gin.Context, schema *UpdateJobSchema) (jobSchema *schemas.JobSchema, err error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	job, err := schema.GetJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, job); err != nil {
+		return nil, err
+	}
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var bentoId *uint
+	if schema.BentoRepository != nil || schema.Bento != nil {
+		if schema.BentoRepository == nil || schema.Bento == nil {
+			return nil, errors.New("the bento repository and the bento of a job must be updated together")
+		}
+		bento, err := c.getBento(ctx, org, *schema.BentoRepository, *schema.Bento)
+		if err != nil {
+			return nil, err
+		}
+		bentoId = utils.UintPtr(bento.ID)
+	}
+	var config **schemas.JobConfigSchema
+	if schema.Config != nil {
+		config = &schema.Config
+	}
+
+	// nolint: ineffassign, staticcheck
+	_, ctx_, df, err := services.StartTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	job, err = services.JobService.Update(ctx_, job, services.UpdateJobOption{
+		Description: schema.Description,
+		BentoId:     bentoId,
+		Schedule:    schema.Schedule,
+		Suspend:     schema.Suspend,
+		Config:      config,
+		Labels:      schema.Labels,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "update job")
+	}
+
+	if err = services.JobService.Apply(ctx_, job); err != nil {
+		return nil, err
+	}
+
+	if err = c.createEvent(ctx_, user, job, "updated"); err != nil {
+		return nil, err
+	}
+
+	return transformersv1.ToJobSchema(ctx_, job)
+}
+
+func (c *jobController) Get(ctx *gin.Context, schema *GetJobSchema) (*schemas.JobSchema, error) {
+	job, err := schema.GetJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, job); err != nil {
+		return nil, err
+	}
+	return transformersv1.ToJobSchema(ctx, job)
+}
+
+type ListJobSchema struct {
+	schemasv1.ListQuerySchema
+	GetClusterSchema
+	KubeNamespace string            `query:"kube_namespace"`
+	Type          schemas.JobType   `query:"type"`
+	Status        schemas.JobStatus `query:"status"`
+}
+
+func (c *jobController) List(ctx *gin.Context, schema *ListJobSchema) (*schemas.JobListSchema, error) {
+	cluster, err := schema.GetCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = ClusterController.canView(ctx, cluster); err != nil {
+		return nil, err
+	}
+
+	listOpt := services.ListJobOption{
+		BaseListOption: services.BaseListOption{
+			Start:  utils.UintPtr(schema.Start),
+			Count:  utils.UintPtr(schema.Count),
+			Search: schema.Search,
+		},
+		ClusterId: utils.UintPtr(cluster.ID),
+	}
+	if schema.KubeNamespace != "" {
+		listOpt.KubeNamespace = utils.StringPtr(schema.KubeNamespace)
+	}
+	if schema.Type != "" {
+		listOpt.Type = &schema.Type
+	}
+	if schema.Status != "" {
+		listOpt.Status = schema.Status.Ptr()
+	}
+
+	jobs, total, err := services.JobService.List(ctx, listOpt)
+	if err != nil {
+		return nil, errors.Wrap(err, "list jobs")
+	}
+
+	jobSchemas, err := transformersv1.ToJobSchemas(ctx, jobs)
+	return &schemas.JobListSchema{
+		BaseListSchema: schemasv1.BaseListSchema{
+			Total: total,
+			Start: schema.Start,
+			Count: schema.Count,
+		},
+		Items: jobSchemas,
+	}, err
+}
+
+// Run starts a run of the job right away, it is how a failed one-off job is retried after its backoff limit
+func (c *jobController) Run(ctx *gin.Context, schema *GetJobSchema) (*schemas.JobRunSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	job, err := schema.GetJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, job); err != nil {
+		return nil, err
+	}
+	run, err := services.JobService.Run(ctx, job, c.getRunJobOption(user))
+	if err != nil {
+		return nil, errors.Wrap(err, "run job")
+	}
+	_, err = services.JobService.SyncStatus(ctx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync job status")
+	}
+	return run, nil
+}
+
+// ListRuns returns the history of the job, it is as long as the history limits of the job config
+func (c *jobController) ListRuns(ctx *gin.Context, schema *GetJobSchema) (*schemas.JobRunListSchema, error) {
+	job, err := schema.GetJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, job); err != nil {
+		return nil, err
+	}
+	runs, err := services.JobService.ListRuns(ctx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "list job runs")
+	}
+	return &schemas.JobRunListSchema{
+		Items: runs,
+	}, nil
+}
+
+func (c *jobController) SyncStatus(ctx *gin.Context, schema *GetJobSchema) (*schemas.JobSchema, error) {
+	job, err := schema.GetJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, job); err != nil {
+		return nil, err
+	}
+	job, err = services.JobService.SyncStatus(ctx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync job status")
+	}
+	return transformersv1.ToJobSchema(ctx, job)
+}
+
+// Delete removes the job with the history of its runs
+func (c *jobController) Delete(ctx *gin.Context, schema *GetJobSchema) (*schemas.JobSchema, error) {
+	job, err := schema.GetJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, job); err != nil {
+		return nil, err
+	}
+	jobSchema, err := transformersv1.ToJobSchema(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	_, err = services.JobService.Delete(ctx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete job")
+	}
+	return jobSchema, nil
+}
+
+func (c *jobController) getRunJobOption(user *models.User) services.RunJobOption {
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	return services.RunJobOption{
+		CreatorId:    user.ID,
+		ApiTokenName: apiTokenName,
+	}
+}
+
+func (c *jobController) createEvent(ctx context.Context, user *models.User, job *models.Job, operationName string) error {
+	cluster, err := services.ClusterService.GetAssociatedCluster(ctx, job)
+	if err != nil {
+		return errors.Wrap(err, "get associated cluster")
+	}
+	runJobOption := c.getRunJobOption(user)
+	_, err = services.EventService.Create(ctx, services.CreateEventOption{
+		CreatorId:      runJobOption.CreatorId,
+		ApiTokenName:   runJobOption.ApiTokenName,
+		OrganizationId: &cluster.OrganizationId,
+		ClusterId:      &cluster.ID,
+		ResourceType:   schemas.ResourceTypeJob,
+		ResourceId:     job.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  operationName,
+	})
+	if err != nil {
+		return errors.Wrap(err, "create event")
+	}
+	return nil
+}
@@ -22,7 +22,9 @@ import (
 
 	commonconsts "github.com/bentoml/yatai-common/consts"
 	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/common/consts"
 )
 
 type logMessageType string
@@ -417,3 +419,75 @@ func (c *logController) TailClusterPodLog(ctx *gin.Context, schema *GetClusterSc
 	err = t.Start(ctx, cliset)
 	return err
 }
+
+// TailJobPodLog tails the pods of a run of the job, the latest run when no run is given,
+// a run has a pod for every retry so their lines are prefixed with the pod name
+func (c *logController) TailJobPodLog(ctx *gin.Context, schema *GetJobSchema) error {
+	var err error
+
+	ctx.Request.Header.Del("Origin")
+	conn, err := wsUpgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		logrus.Errorf("ws connect failed: %q", err.Error())
+		return err
+	}
+	defer conn.Close()
+
+	defer func() {
+		writeWsError(conn, err)
+	}()
+
+	job, err := schema.GetJob(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = JobController.canView(ctx, job); err != nil {
+		return err
+	}
+
+	cluster, err := schema.GetCluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	cliset, _, err := services.ClusterService.GetKubeCliSet(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	podName := ctx.Query("pod_name")
+	runName := ctx.Query("run_name")
+	var podNames []string
+	containerName := ctx.Query("container_name")
+
+	if podName != "" {
+		podNames = append(podNames, podName)
+
+		pod, err := cliset.CoreV1().Pods(job.KubeNamespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if pod.Labels[consts.KubeLabelYataiJob] != job.Name {
+			return errors.Errorf("pod %s not in this job", podName)
+		}
+	} else {
+		var runs []*schemas.JobRunSchema
+		runs, err = services.JobService.ListRuns(ctx, job)
+		if err != nil {
+			return err
+		}
+		for _, run := range runs {
+			if runName == "" || run.Name == runName {
+				podNames = run.PodNames
+				break
+			}
+		}
+	}
+
+	t := NewTail(conn, job.KubeNamespace, podNames, containerName, true, len(podNames) > 1)
+
+	err = t.Start(ctx, cliset)
+	return err
+}
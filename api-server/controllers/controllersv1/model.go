@@ -13,8 +13,10 @@ import (
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai-schemas/schemasv1"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/consts"
 	"github.com/bentoml/yatai/common/utils"
 )
 
@@ -171,26 +173,36 @@ func (c *modelController) Upload(ctx *gin.Context) {
 	bodySize := ctx.Request.ContentLength
 
 	err = services.ModelService.Upload(ctx, model, ctx.Request.Body, bodySize)
+	if err == nil {
+		if expectedChecksum := ctx.GetHeader(consts.YataiChecksumHeaderName); expectedChecksum != "" && expectedChecksum != model.Checksum {
+			err = errors.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, model.Checksum)
+		}
+	}
 	if err != nil {
+		uploadErr := err
 		uploadStatus = modelschemas.ModelUploadStatusFailed
+		uploadFinishedReason := uploadErr.Error()
 		now = time.Now()
 		nowPtr = &now
 		model, err = services.ModelService.Update(ctx, model, services.UpdateModelOption{
-			UploadStatus:    &uploadStatus,
-			UploadStartedAt: &nowPtr,
+			UploadStatus:         &uploadStatus,
+			UploadFinishedAt:     &nowPtr,
+			UploadFinishedReason: &uploadFinishedReason,
 		})
 		if err != nil {
 			abortWithError(ctx, err)
 			return
 		}
+		abortWithError(ctx, uploadErr)
+		return
 	}
 
 	uploadStatus = modelschemas.ModelUploadStatusSuccess
 	now = time.Now()
 	nowPtr = &now
 	_, err = services.ModelService.Update(ctx, model, services.UpdateModelOption{
-		UploadStatus:    &uploadStatus,
-		UploadStartedAt: &nowPtr,
+		UploadStatus:     &uploadStatus,
+		UploadFinishedAt: &nowPtr,
 	})
 	if err != nil {
 		abortWithError(ctx, err)
@@ -212,10 +224,13 @@ func (c *modelController) PreSignUploadUrl(ctx *gin.Context, schema *GetModelSch
 	}
 	if !isNewBentomlCli(ctx) {
 		url_, err := services.ModelService.PreSignUploadUrl(ctx, model)
-		if err != nil {
+		// the storage drivers that cannot presign urls leave the url empty, the clients then upload through the api-server
+		if err != nil && !errors.Is(err, consts.ErrNoImplemented) {
 			return nil, errors.Wrap(err, "pre sign upload url")
 		}
-		modelSchema.PresignedUploadUrl = url_.String()
+		if url_ != nil {
+			modelSchema.PresignedUploadUrl = url_.String()
+		}
 	}
 	modelSchema.PresignedUrlsDeprecated = true
 	return modelSchema, nil
@@ -238,10 +253,17 @@ func (c *modelController) Download(ctx *gin.Context) {
 		abortWithError(ctx, err)
 		return
 	}
-	if err = services.ModelService.Download(ctx, model, ctx.Writer); err != nil {
+	if model.Checksum != "" {
+		ctx.Header(consts.YataiChecksumHeaderName, model.Checksum)
+	}
+	objectInfo, err := services.ModelService.StatObject(ctx, model)
+	if err != nil {
 		abortWithError(ctx, err)
 		return
 	}
+	serveObject(ctx, objectInfo, func(opt services.StorageGetOption) error {
+		return services.ModelService.DownloadWithOption(ctx, model, ctx.Writer, opt)
+	})
 }
 
 func (c *modelController) PreSignDownloadUrl(ctx *gin.Context, schema *GetModelSchema) (*schemasv1.ModelSchema, error) {
@@ -258,10 +280,13 @@ func (c *modelController) PreSignDownloadUrl(ctx *gin.Context, schema *GetModelS
 	}
 	if !isNewBentomlCli(ctx) {
 		url_, err := services.ModelService.PreSignDownloadUrl(ctx, model)
-		if err != nil {
+		// the storage drivers that cannot presign urls leave the url empty, the clients then download through the api-server
+		if err != nil && !errors.Is(err, consts.ErrNoImplemented) {
 			return nil, errors.Wrap(err, "pre sign download url")
 		}
-		modelSchema.PresignedDownloadUrl = url_.String()
+		if url_ != nil {
+			modelSchema.PresignedDownloadUrl = url_.String()
+		}
 	}
 	modelSchema.PresignedUrlsDeprecated = true
 	return modelSchema, nil
@@ -289,6 +314,63 @@ func (c *modelController) Update(ctx *gin.Context, schema *UpdateModelSchema) (*
 	return transformersv1.ToModelSchema(ctx, model)
 }
 
+func (c *modelController) Delete(ctx *gin.Context, schema *GetModelSchema) (*schemasv1.ModelSchema, error) {
+	model, err := schema.GetModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, model); err != nil {
+		return nil, err
+	}
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	modelRepository, err := services.ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	org, err := services.OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
+	if err != nil {
+		return nil, err
+	}
+	modelSchema, err := transformersv1.ToModelSchema(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint: ineffassign, staticcheck
+	_, ctx_, df, err := services.StartTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	// the event must be created before the model is gone, it keeps the resource name in its info
+	_, err = services.EventService.Create(ctx_, services.CreateEventOption{
+		CreatorId:      user.ID,
+		ApiTokenName:   apiTokenName,
+		OrganizationId: &org.ID,
+		ResourceType:   modelschemas.ResourceTypeModel,
+		ResourceId:     model.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "deleted",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+
+	_, err = services.ModelService.Delete(ctx_, model)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete model")
+	}
+	return modelSchema, nil
+}
+
 func (c *modelController) StartUpload(ctx *gin.Context, schema *GetModelSchema) (*schemasv1.ModelSchema, error) {
 	model, err := schema.GetModel(ctx)
 	if err != nil {
@@ -313,6 +395,7 @@ func (c *modelController) StartUpload(ctx *gin.Context, schema *GetModelSchema)
 type FinishUploadModelSchema struct {
 	schemasv1.FinishUploadModelSchema
 	GetModelSchema
+	Checksum *string `json:"checksum"`
 }
 
 func (c *modelController) FinishUpload(ctx *gin.Context, schema *FinishUploadModelSchema) (*schemasv1.ModelSchema, error) {
@@ -323,12 +406,29 @@ func (c *modelController) FinishUpload(ctx *gin.Context, schema *FinishUploadMod
 	if err = c.canUpdate(ctx, model); err != nil {
 		return nil, err
 	}
+	var checksum *string
+	if schema.Status != nil && *schema.Status == modelschemas.ModelUploadStatusSuccess {
+		// the archive has been uploaded by the client directly, so read it back to make sure it is intact
+		// a read error leaves the upload as it is so that the client can retry, only a mismatch fails it
+		actualChecksum, err := services.ModelService.ComputeChecksum(ctx, model)
+		if err != nil {
+			return nil, errors.Wrap(err, "compute checksum")
+		}
+		if schema.Checksum != nil && *schema.Checksum != actualChecksum {
+			failedStatus := modelschemas.ModelUploadStatusFailed
+			schema.Status = &failedStatus
+			schema.Reason = utils.StringPtr(fmt.Sprintf("checksum mismatch: expected %s, got %s", *schema.Checksum, actualChecksum))
+		} else {
+			checksum = &actualChecksum
+		}
+	}
 	now := time.Now()
 	nowPtr := &now
 	model, err = services.ModelService.Update(ctx, model, services.UpdateModelOption{
 		UploadStatus:         schema.Status,
 		UploadFinishedAt:     &nowPtr,
 		UploadFinishedReason: schema.Reason,
+		Checksum:             checksum,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "update model")
@@ -392,7 +492,7 @@ func (c *modelController) ListImageBuilderPods(ctx *gin.Context, schema *GetMode
 	return transformersv1.ToKubePodSchemas(ctx, majorCluster.ID, pods)
 }
 
-func (c *modelController) Get(ctx *gin.Context, schema *GetModelSchema) (*schemasv1.ModelFullSchema, error) {
+func (c *modelController) Get(ctx *gin.Context, schema *GetModelSchema) (*schemas.ModelFullSchema, error) {
 	model, err := schema.GetModel(ctx)
 	if err != nil {
 		return nil, err
@@ -400,7 +500,120 @@ func (c *modelController) Get(ctx *gin.Context, schema *GetModelSchema) (*schema
 	if err = c.canView(ctx, model); err != nil {
 		return nil, err
 	}
-	return transformersv1.ToModelFullSchema(ctx, model)
+	modelSchema, err := transformersv1.ToModelFullSchema(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	return &schemas.ModelFullSchema{
+		ModelFullSchema: *modelSchema,
+		Checksum:        model.Checksum,
+	}, nil
+}
+
+type CopyModelSchema struct {
+	schemas.CopyModelSchema
+	GetModelSchema
+}
+
+func (c *modelController) Copy(ctx *gin.Context, schema *CopyModelSchema) (*schemasv1.ModelSchema, error) {
+	model, err := schema.GetModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, model); err != nil {
+		return nil, err
+	}
+	targetOrg, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if schema.TargetOrganizationName != "" {
+		targetOrg, err = services.OrganizationService.GetByName(ctx, schema.TargetOrganizationName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get organization %s", schema.TargetOrganizationName)
+		}
+	}
+	if err = OrganizationController.canUpdate(ctx, targetOrg); err != nil {
+		return nil, err
+	}
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	newModel, err := services.CopyService.CopyModel(ctx, model, services.CopyModelOption{
+		CreatorId:                 user.ID,
+		ApiTokenName:              apiTokenName,
+		TargetOrganizationId:      targetOrg.ID,
+		TargetModelRepositoryName: schema.TargetModelRepositoryName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "copy model")
+	}
+	return transformersv1.ToModelSchema(ctx, newModel)
+}
+
+func (c *modelController) VerifyChecksum(ctx *gin.Context, schema *GetModelSchema) (*schemas.ChecksumVerificationSchema, error) {
+	model, err := schema.GetModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, model); err != nil {
+		return nil, err
+	}
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	modelRepository, err := services.ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	org, err := services.OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
+	if err != nil {
+		return nil, err
+	}
+	actualChecksum, err := services.ModelService.ComputeChecksum(ctx, model)
+	if err != nil {
+		return nil, errors.Wrap(err, "compute checksum")
+	}
+	if model.Checksum == "" {
+		// models pushed before checksums were recorded, trust the current content
+		model, err = services.ModelService.Update(ctx, model, services.UpdateModelOption{
+			Checksum: &actualChecksum,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "update model checksum")
+		}
+	}
+	res := &schemas.ChecksumVerificationSchema{
+		Checksum:       model.Checksum,
+		ActualChecksum: actualChecksum,
+		Corrupted:      model.Checksum != actualChecksum,
+	}
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	createEventOpt := services.CreateEventOption{
+		CreatorId:      user.ID,
+		ApiTokenName:   apiTokenName,
+		OrganizationId: &org.ID,
+		ResourceType:   modelschemas.ResourceTypeModel,
+		ResourceId:     model.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "verified",
+	}
+	if res.Corrupted {
+		createEventOpt.Status = modelschemas.EventStatusFailed
+	}
+	if _, err = services.EventService.Create(ctx, createEventOpt); err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+	return res, nil
 }
 
 type ListModelDeploymentSchema struct {
@@ -408,7 +621,7 @@ type ListModelDeploymentSchema struct {
 	GetModelSchema
 }
 
-func (c *modelController) ListDeployment(ctx *gin.Context, schema *ListModelDeploymentSchema) (*schemasv1.DeploymentListSchema, error) {
+func (c *modelController) ListDeployment(ctx *gin.Context, schema *ListModelDeploymentSchema) (*schemas.DeploymentListSchema, error) {
 	model, err := schema.GetModel(ctx)
 	if err != nil {
 		return nil, err
@@ -441,7 +654,7 @@ func (c *modelController) ListDeployment(ctx *gin.Context, schema *ListModelDepl
 	if err != nil {
 		return nil, err
 	}
-	return &schemasv1.DeploymentListSchema{
+	return &schemas.DeploymentListSchema{
 		BaseListSchema: schemasv1.BaseListSchema{
 			Total: total,
 			Start: schema.Start,
@@ -451,6 +664,21 @@ func (c *modelController) ListDeployment(ctx *gin.Context, schema *ListModelDepl
 	}, nil
 }
 
+func (c *modelController) GetImpact(ctx *gin.Context, schema *GetModelSchema) (*schemas.ModelImpactSchema, error) {
+	model, err := schema.GetModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, model); err != nil {
+		return nil, err
+	}
+	impactBentos, err := services.ModelService.GetImpact(ctx, model)
+	if err != nil {
+		return nil, errors.Wrap(err, "get model impact")
+	}
+	return transformersv1.ToModelImpactSchema(ctx, model, impactBentos)
+}
+
 type ListModelBentoSchema struct {
 	schemasv1.ListQuerySchema
 	GetModelSchema
@@ -630,3 +858,39 @@ func (c *modelController) ListAll(ctx *gin.Context, schema *ListAllModelSchema)
 		Items: modelSchemas,
 	}, err
 }
+
+type ListUnreferencedModelSchema struct {
+	schemasv1.ListQuerySchema
+	GetOrganizationSchema
+}
+
+func (c *modelController) ListUnreferenced(ctx *gin.Context, schema *ListUnreferencedModelSchema) (*schemasv1.ModelWithRepositoryListSchema, error) {
+	organization, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = OrganizationController.canView(ctx, organization); err != nil {
+		return nil, err
+	}
+	models_, total, err := services.ModelService.List(ctx, services.ListModelOption{
+		BaseListOption: services.BaseListOption{
+			Start:  utils.UintPtr(schema.Start),
+			Count:  utils.UintPtr(schema.Count),
+			Search: schema.Search,
+		},
+		OrganizationId: utils.UintPtr(organization.ID),
+		Unreferenced:   utils.BoolPtr(true),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list unreferenced models")
+	}
+	modelSchemas, err := transformersv1.ToModelWithRepositorySchemas(ctx, models_)
+	return &schemasv1.ModelWithRepositoryListSchema{
+		BaseListSchema: schemasv1.BaseListSchema{
+			Total: total,
+			Start: schema.Start,
+			Count: schema.Count,
+		},
+		Items: modelSchemas,
+	}, err
+}
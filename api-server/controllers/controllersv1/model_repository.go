@@ -11,6 +11,7 @@ import (
 
 	"github.com/bentoml/yatai-schemas/schemasv1"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
 	"github.com/bentoml/yatai/common/utils"
@@ -126,6 +127,169 @@ func (c *modelRepositoryController) Get(ctx *gin.Context, schema *GetModelReposi
 	return transformersv1.ToModelRepositorySchema(ctx, modelRepository)
 }
 
+func (c *modelRepositoryController) GetRetentionPolicy(ctx *gin.Context, schema *GetModelRepositorySchema) (*schemas.RetentionPolicySchema, error) {
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	return modelRepository.RetentionPolicy, nil
+}
+
+type UpdateModelRepositoryRetentionPolicySchema struct {
+	schemas.UpdateRetentionPolicySchema
+	GetModelRepositorySchema
+}
+
+func (c *modelRepositoryController) UpdateRetentionPolicy(ctx *gin.Context, schema *UpdateModelRepositoryRetentionPolicySchema) (*schemas.RetentionPolicySchema, error) {
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canOperate(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	if err = schema.RetentionPolicy.Validate(); err != nil {
+		return nil, err
+	}
+	modelRepository, err = services.ModelRepositoryService.Update(ctx, modelRepository, services.UpdateModelRepositoryOption{
+		RetentionPolicy: &schema.RetentionPolicy,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "update modelRepository retention policy")
+	}
+	return modelRepository.RetentionPolicy, nil
+}
+
+func (c *modelRepositoryController) ListExpiredModels(ctx *gin.Context, schema *GetModelRepositorySchema) ([]*schemasv1.ModelSchema, error) {
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	models, err := services.RetentionService.ListExpiredModels(ctx, modelRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "list expired models")
+	}
+	return transformersv1.ToModelSchemas(ctx, models)
+}
+
+func (c *modelRepositoryController) ListAliases(ctx *gin.Context, schema *GetModelRepositorySchema) ([]*schemas.RepositoryAliasSchema, error) {
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	aliases, _, err := services.RepositoryAliasService.List(ctx, services.ListRepositoryAliasOption{
+		ResourceType: modelRepository.GetResourceType().Ptr(),
+		ResourceId:   &modelRepository.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list aliases")
+	}
+	return transformersv1.ToRepositoryAliasSchemas(ctx, aliases)
+}
+
+type GetModelRepositoryAliasSchema struct {
+	GetModelRepositorySchema
+	AliasName string `path:"aliasName"`
+}
+
+func (s *GetModelRepositoryAliasSchema) GetAlias(ctx context.Context) (*models.ModelRepository, *models.RepositoryAlias, error) {
+	modelRepository, err := s.GetModelRepository(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	alias, err := services.RepositoryAliasService.GetByName(ctx, modelRepository, s.AliasName)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "get alias %s", s.AliasName)
+	}
+	return modelRepository, alias, nil
+}
+
+type SetModelRepositoryAliasSchema struct {
+	schemas.SetRepositoryAliasSchema
+	GetModelRepositoryAliasSchema
+}
+
+func (c *modelRepositoryController) SetAlias(ctx *gin.Context, schema *SetModelRepositoryAliasSchema) (*schemas.RepositoryAliasSchema, error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	modelRepository, err := schema.GetModelRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	_, err = services.ModelService.GetByVersion(ctx, modelRepository.ID, schema.AliasName)
+	if err == nil {
+		return nil, errors.Errorf("alias %s conflicts with an existing version", schema.AliasName)
+	}
+	if !utils.IsNotFound(err) {
+		return nil, err
+	}
+	model, err := services.ModelService.GetByVersion(ctx, modelRepository.ID, schema.Version)
+	if err != nil {
+		return nil, err
+	}
+	alias, err := services.RepositoryAliasService.Set(ctx, services.SetRepositoryAliasOption{
+		CreatorId:      user.ID,
+		OrganizationId: modelRepository.OrganizationId,
+		Repository:     modelRepository,
+		Name:           schema.AliasName,
+		Target:         model,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "set alias")
+	}
+	return transformersv1.ToRepositoryAliasSchema(ctx, alias)
+}
+
+func (c *modelRepositoryController) DeleteAlias(ctx *gin.Context, schema *GetModelRepositoryAliasSchema) (*schemas.RepositoryAliasSchema, error) {
+	modelRepository, alias, err := schema.GetAlias(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canUpdate(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	aliasSchema, err := transformersv1.ToRepositoryAliasSchema(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+	_, err = services.RepositoryAliasService.Delete(ctx, alias)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete alias")
+	}
+	return aliasSchema, nil
+}
+
+func (c *modelRepositoryController) ListAliasHistories(ctx *gin.Context, schema *GetModelRepositoryAliasSchema) ([]*schemas.RepositoryAliasHistorySchema, error) {
+	modelRepository, alias, err := schema.GetAlias(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.canView(ctx, modelRepository); err != nil {
+		return nil, err
+	}
+	histories, _, err := services.RepositoryAliasService.ListHistories(ctx, services.ListRepositoryAliasHistoryOption{
+		RepositoryAliasId: &alias.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list alias histories")
+	}
+	return transformersv1.ToRepositoryAliasHistorySchemas(ctx, histories)
+}
+
 type ListModelRepositorySchema struct {
 	schemasv1.ListQuerySchema
 	GetOrganizationSchema
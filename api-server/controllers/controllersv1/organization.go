@@ -13,6 +13,7 @@ import (
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai-schemas/schemasv1"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
 	"github.com/bentoml/yatai/common/utils"
@@ -116,7 +117,7 @@ func (c *organizationController) Get(ctx *gin.Context, schema *GetOrganizationSc
 	return transformersv1.ToOrganizationFullSchema(ctx, organization)
 }
 
-func (c *organizationController) GetMajorCluster(ctx *gin.Context, schema *GetOrganizationSchema) (*schemasv1.ClusterFullSchema, error) {
+func (c *organizationController) GetMajorCluster(ctx *gin.Context, schema *GetOrganizationSchema) (*schemas.ClusterFullSchema, error) {
 	organization, err := schema.GetOrganization(ctx)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,56 @@
+package controllersv1
+
+import (
+	"context"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+// resourceScope is the organization or the cluster that the deployment presets and the secrets belong to
+type resourceScope struct {
+	org     *models.Organization
+	cluster *models.Cluster
+}
+
+func (s *resourceScope) getClusterId() *uint {
+	if s.cluster == nil {
+		return nil
+	}
+	return utils.UintPtr(s.cluster.ID)
+}
+
+func (s *resourceScope) canView(ctx context.Context) error {
+	if s.cluster != nil {
+		return ClusterController.canView(ctx, s.cluster)
+	}
+	return OrganizationController.canView(ctx, s.org)
+}
+
+// canOperate restricts the management of the resources of the scope to the admins of the organization or of the cluster
+func (s *resourceScope) canOperate(ctx context.Context) error {
+	if s.cluster != nil {
+		return ClusterController.canOperate(ctx, s.cluster)
+	}
+	return OrganizationController.canOperate(ctx, s.org)
+}
+
+func getOrganizationResourceScope(ctx context.Context, schema *GetOrganizationSchema) (*resourceScope, error) {
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &resourceScope{org: org}, nil
+}
+
+func getClusterResourceScope(ctx context.Context, schema *GetClusterSchema) (*resourceScope, error) {
+	cluster, err := schema.GetCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org, err := schema.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &resourceScope{org: org, cluster: cluster}, nil
+}
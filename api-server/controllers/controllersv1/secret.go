@@ -0,0 +1,307 @@
+package controllersv1
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/api-server/transformers/transformersv1"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type secretController struct {
+	baseController
+}
+
+var SecretController = secretController{}
+
+func (c *secretController) getSecret(ctx context.Context, scope *resourceScope, name string) (*models.Secret, error) {
+	secret, err := services.SecretService.GetByName(ctx, scope.org.ID, scope.getClusterId(), name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get secret %s", name)
+	}
+	return secret, nil
+}
+
+// createEvent records the operation on the secret, the event only keeps the name of the secret
+func (c *secretController) createEvent(ctx context.Context, user *models.User, scope *resourceScope, secret *models.Secret, operationName string) error {
+	apiTokenName := ""
+	if user.ApiToken != nil {
+		apiTokenName = user.ApiToken.Name
+	}
+	_, err := services.EventService.Create(ctx, services.CreateEventOption{
+		CreatorId:      user.ID,
+		ApiTokenName:   apiTokenName,
+		OrganizationId: &scope.org.ID,
+		ClusterId:      scope.getClusterId(),
+		ResourceType:   schemas.ResourceTypeSecret,
+		ResourceId:     secret.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  operationName,
+	})
+	return errors.Wrap(err, "create event")
+}
+
+func (c *secretController) doList(ctx context.Context, scope *resourceScope, query schemasv1.ListQuerySchema) (*schemas.SecretListSchema, error) {
+	if err := scope.canView(ctx); err != nil {
+		return nil, err
+	}
+	secrets, total, err := services.SecretService.List(ctx, services.ListSecretOption{
+		BaseListOption: services.BaseListOption{
+			Start: utils.UintPtr(query.Start),
+			Count: utils.UintPtr(query.Count),
+		},
+		OrganizationId: utils.UintPtr(scope.org.ID),
+		ClusterId:      scope.getClusterId(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list secrets")
+	}
+	secretSchemas, err := transformersv1.ToSecretSchemas(ctx, secrets)
+	return &schemas.SecretListSchema{
+		BaseListSchema: schemasv1.BaseListSchema{
+			Total: total,
+			Start: query.Start,
+			Count: query.Count,
+		},
+		Items: secretSchemas,
+	}, err
+}
+
+func (c *secretController) doCreate(ctx context.Context, scope *resourceScope, schema schemas.CreateSecretSchema) (secretSchema *schemas.SecretSchema, err error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = scope.canOperate(ctx); err != nil {
+		return nil, err
+	}
+
+	// nolint: ineffassign, staticcheck
+	_, ctx_, df, err := services.StartTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	secret, err := services.SecretService.Create(ctx_, services.CreateSecretOption{
+		CreatorId:      user.ID,
+		OrganizationId: scope.org.ID,
+		ClusterId:      scope.getClusterId(),
+		Name:           schema.Name,
+		Description:    schema.Description,
+		Data:           schema.Data,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create secret")
+	}
+	if err = c.createEvent(ctx_, user, scope, secret, "created"); err != nil {
+		return nil, err
+	}
+	return transformersv1.ToSecretSchema(ctx_, secret)
+}
+
+func (c *secretController) doGet(ctx context.Context, scope *resourceScope, name string) (*schemas.SecretSchema, error) {
+	if err := scope.canView(ctx); err != nil {
+		return nil, err
+	}
+	secret, err := c.getSecret(ctx, scope, name)
+	if err != nil {
+		return nil, err
+	}
+	return transformersv1.ToSecretSchema(ctx, secret)
+}
+
+func (c *secretController) doUpdate(ctx context.Context, scope *resourceScope, name string, schema schemas.UpdateSecretSchema) (secretSchema *schemas.SecretSchema, err error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = scope.canOperate(ctx); err != nil {
+		return nil, err
+	}
+	secret, err := c.getSecret(ctx, scope, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint: ineffassign, staticcheck
+	_, ctx_, df, err := services.StartTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	secret, err = services.SecretService.Update(ctx_, secret, services.UpdateSecretOption{
+		Description: schema.Description,
+		Data:        schema.Data,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "update secret")
+	}
+	if err = c.createEvent(ctx_, user, scope, secret, "updated"); err != nil {
+		return nil, err
+	}
+	return transformersv1.ToSecretSchema(ctx_, secret)
+}
+
+func (c *secretController) doDelete(ctx context.Context, scope *resourceScope, name string) (secretSchema *schemas.SecretSchema, err error) {
+	user, err := services.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = scope.canOperate(ctx); err != nil {
+		return nil, err
+	}
+	secret, err := c.getSecret(ctx, scope, name)
+	if err != nil {
+		return nil, err
+	}
+	secretSchema, err = transformersv1.ToSecretSchema(ctx, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint: ineffassign, staticcheck
+	_, ctx_, df, err := services.StartTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { df(err) }()
+
+	// the event must be created before the secret is gone, it keeps the resource name in its info
+	if err = c.createEvent(ctx_, user, scope, secret, "deleted"); err != nil {
+		return nil, err
+	}
+	_, err = services.SecretService.Delete(ctx_, secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete secret")
+	}
+	return secretSchema, nil
+}
+
+type ListOrganizationSecretSchema struct {
+	schemasv1.ListQuerySchema
+	GetOrganizationSchema
+}
+
+func (c *secretController) ListOrganizationSecrets(ctx *gin.Context, schema *ListOrganizationSecretSchema) (*schemas.SecretListSchema, error) {
+	scope, err := getOrganizationResourceScope(ctx, &schema.GetOrganizationSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doList(ctx, scope, schema.ListQuerySchema)
+}
+
+type CreateOrganizationSecretSchema struct {
+	schemas.CreateSecretSchema
+	GetOrganizationSchema
+}
+
+func (c *secretController) CreateOrganizationSecret(ctx *gin.Context, schema *CreateOrganizationSecretSchema) (*schemas.SecretSchema, error) {
+	scope, err := getOrganizationResourceScope(ctx, &schema.GetOrganizationSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doCreate(ctx, scope, schema.CreateSecretSchema)
+}
+
+type GetOrganizationSecretSchema struct {
+	GetOrganizationSchema
+	SecretName string `path:"secretName"`
+}
+
+func (c *secretController) GetOrganizationSecret(ctx *gin.Context, schema *GetOrganizationSecretSchema) (*schemas.SecretSchema, error) {
+	scope, err := getOrganizationResourceScope(ctx, &schema.GetOrganizationSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doGet(ctx, scope, schema.SecretName)
+}
+
+type UpdateOrganizationSecretSchema struct {
+	schemas.UpdateSecretSchema
+	GetOrganizationSecretSchema
+}
+
+func (c *secretController) UpdateOrganizationSecret(ctx *gin.Context, schema *UpdateOrganizationSecretSchema) (*schemas.SecretSchema, error) {
+	scope, err := getOrganizationResourceScope(ctx, &schema.GetOrganizationSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doUpdate(ctx, scope, schema.SecretName, schema.UpdateSecretSchema)
+}
+
+func (c *secretController) DeleteOrganizationSecret(ctx *gin.Context, schema *GetOrganizationSecretSchema) (*schemas.SecretSchema, error) {
+	scope, err := getOrganizationResourceScope(ctx, &schema.GetOrganizationSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doDelete(ctx, scope, schema.SecretName)
+}
+
+type ListClusterSecretSchema struct {
+	schemasv1.ListQuerySchema
+	GetClusterSchema
+}
+
+func (c *secretController) ListClusterSecrets(ctx *gin.Context, schema *ListClusterSecretSchema) (*schemas.SecretListSchema, error) {
+	scope, err := getClusterResourceScope(ctx, &schema.GetClusterSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doList(ctx, scope, schema.ListQuerySchema)
+}
+
+type CreateClusterSecretSchema struct {
+	schemas.CreateSecretSchema
+	GetClusterSchema
+}
+
+func (c *secretController) CreateClusterSecret(ctx *gin.Context, schema *CreateClusterSecretSchema) (*schemas.SecretSchema, error) {
+	scope, err := getClusterResourceScope(ctx, &schema.GetClusterSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doCreate(ctx, scope, schema.CreateSecretSchema)
+}
+
+type GetClusterSecretSchema struct {
+	GetClusterSchema
+	SecretName string `path:"secretName"`
+}
+
+func (c *secretController) GetClusterSecret(ctx *gin.Context, schema *GetClusterSecretSchema) (*schemas.SecretSchema, error) {
+	scope, err := getClusterResourceScope(ctx, &schema.GetClusterSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doGet(ctx, scope, schema.SecretName)
+}
+
+type UpdateClusterSecretSchema struct {
+	schemas.UpdateSecretSchema
+	GetClusterSecretSchema
+}
+
+func (c *secretController) UpdateClusterSecret(ctx *gin.Context, schema *UpdateClusterSecretSchema) (*schemas.SecretSchema, error) {
+	scope, err := getClusterResourceScope(ctx, &schema.GetClusterSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doUpdate(ctx, scope, schema.SecretName, schema.UpdateSecretSchema)
+}
+
+func (c *secretController) DeleteClusterSecret(ctx *gin.Context, schema *GetClusterSecretSchema) (*schemas.SecretSchema, error) {
+	scope, err := getClusterResourceScope(ctx, &schema.GetClusterSchema)
+	if err != nil {
+		return nil, err
+	}
+	return c.doDelete(ctx, scope, schema.SecretName)
+}
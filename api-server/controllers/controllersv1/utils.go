@@ -1,10 +1,17 @@
 package controllersv1
 
 import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
 
 	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/services"
+	"github.com/bentoml/yatai/common/utils"
 )
 
 func writeWsError(conn *websocket.Conn, err error) {
@@ -22,3 +29,55 @@ func writeWsError(conn *websocket.Conn, err error) {
 		logrus.Errorf("ws write error: %q", err_.Error())
 	}
 }
+
+// serveObject writes the stored object with support for conditional and range requests,
+// download is called with the options to pass to the storage driver
+func serveObject(ctx *gin.Context, objectInfo *services.StorageObjectInfo, download func(opt services.StorageGetOption) error) {
+	etag := strconv.Quote(objectInfo.ETag)
+	ctx.Header("ETag", etag)
+	ctx.Header("Accept-Ranges", "bytes")
+	ctx.Header("Last-Modified", objectInfo.LastModified.UTC().Format(http.TimeFormat))
+
+	if ifNoneMatch := ctx.GetHeader("If-None-Match"); ifNoneMatch != "" && utils.MatchETag(ifNoneMatch, objectInfo.ETag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	opt := services.StorageGetOption{
+		// make sure the object is not replaced between the stat and the get
+		MatchETag: objectInfo.ETag,
+	}
+
+	rangeHeader := ctx.GetHeader("Range")
+	// the range is only served for the exact bytes it was asked against, otherwise the whole object is sent
+	if ifRange := ctx.GetHeader("If-Range"); ifRange != "" && !utils.StrongMatchETag(ifRange, objectInfo.ETag) {
+		rangeHeader = ""
+	}
+	start, end, ok, err := utils.ParseByteRange(rangeHeader, objectInfo.Size)
+	if err != nil {
+		ctx.Header("Content-Range", fmt.Sprintf("bytes */%d", objectInfo.Size))
+		ctx.AbortWithStatusJSON(http.StatusRequestedRangeNotSatisfiable, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.Header("Content-Type", "application/octet-stream")
+	if ok {
+		opt.Range = &services.StorageObjectRange{
+			Start: start,
+			End:   end,
+		}
+		ctx.Header("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, objectInfo.Size))
+		ctx.Header("Content-Length", strconv.FormatInt(end-start+1, 10))
+		ctx.Status(http.StatusPartialContent)
+	} else {
+		ctx.Header("Content-Length", strconv.FormatInt(objectInfo.Size, 10))
+		ctx.Status(http.StatusOK)
+	}
+
+	if err = download(opt); err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+}
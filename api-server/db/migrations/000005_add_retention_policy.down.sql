@@ -0,0 +1,5 @@
+ALTER TABLE "bento_repository"
+DROP COLUMN "retention_policy";
+
+ALTER TABLE "model_repository"
+DROP COLUMN "retention_policy";
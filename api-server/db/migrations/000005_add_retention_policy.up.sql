@@ -0,0 +1,5 @@
+ALTER TABLE "bento_repository"
+ADD COLUMN "retention_policy" JSONB DEFAULT NULL;
+
+ALTER TABLE "model_repository"
+ADD COLUMN "retention_policy" JSONB DEFAULT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE "bento"
+DROP COLUMN "checksum";
+
+ALTER TABLE "model"
+DROP COLUMN "checksum";
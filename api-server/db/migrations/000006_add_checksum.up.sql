@@ -0,0 +1,5 @@
+ALTER TABLE "bento"
+ADD COLUMN "checksum" VARCHAR(128) DEFAULT NULL;
+
+ALTER TABLE "model"
+ADD COLUMN "checksum" VARCHAR(128) DEFAULT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "repository_alias_history";
+
+DROP TABLE IF EXISTS "repository_alias";
@@ -0,0 +1,30 @@
+ALTER TYPE "resource_type" ADD VALUE 'repository_alias';
+
+CREATE TABLE IF NOT EXISTS "repository_alias" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    name VARCHAR(128) NOT NULL,
+    organization_id INTEGER NOT NULL REFERENCES "organization"("id") ON DELETE CASCADE,
+    resource_type resource_type NOT NULL,
+    resource_id INTEGER NOT NULL,
+    target_id INTEGER NOT NULL,
+    creator_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX "uk_repositoryAlias_resourceType_resourceId_name" ON "repository_alias" ("resource_type", "resource_id", "name");
+CREATE INDEX "idx_repositoryAlias_resourceType_targetId" ON "repository_alias" ("resource_type", "target_id");
+
+CREATE TABLE IF NOT EXISTS "repository_alias_history" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    repository_alias_id INTEGER NOT NULL REFERENCES "repository_alias"("id") ON DELETE CASCADE,
+    target_id INTEGER NOT NULL,
+    version VARCHAR(128) NOT NULL,
+    creator_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,23 @@
+ALTER TYPE "resource_type" ADD VALUE 'image_build';
+
+CREATE TABLE IF NOT EXISTS "image_build" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    resource_type resource_type NOT NULL,
+    resource_id INTEGER NOT NULL,
+    attempt INTEGER NOT NULL DEFAULT 1,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    pod_name VARCHAR(256) NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    logs TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    next_retry_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    creator_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX "idx_imageBuild_resourceType_resourceId" ON "image_build" ("resource_type", "resource_id");
+CREATE INDEX "idx_imageBuild_status_nextRetryAt" ON "image_build" ("status", "next_retry_at");
@@ -0,0 +1,24 @@
+ALTER TYPE "resource_type" ADD VALUE 'canary_rollout';
+
+CREATE TABLE IF NOT EXISTS "canary_rollout" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    deployment_id INTEGER NOT NULL REFERENCES "deployment"("id") ON DELETE CASCADE,
+    deployment_revision_id INTEGER NOT NULL REFERENCES "deployment_revision"("id") ON DELETE CASCADE,
+    deployment_target_id INTEGER NOT NULL REFERENCES "deployment_target"("id") ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL DEFAULT 'running',
+    steps JSONB NOT NULL,
+    analysis JSONB NOT NULL,
+    current_step INTEGER NOT NULL DEFAULT 0,
+    step_started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    step_baseline_pod_restarts INTEGER NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    finished_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    creator_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX "idx_canaryRollout_deploymentId" ON "canary_rollout" ("deployment_id");
+CREATE INDEX "idx_canaryRollout_status" ON "canary_rollout" ("status");
@@ -0,0 +1,20 @@
+ALTER TYPE "resource_type" ADD VALUE 'deployment_preset';
+
+CREATE TABLE IF NOT EXISTS "deployment_preset" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    name VARCHAR(128) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    organization_id INTEGER NOT NULL REFERENCES "organization"("id") ON DELETE CASCADE,
+    cluster_id INTEGER REFERENCES "cluster"("id") ON DELETE CASCADE,
+    config TEXT NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    creator_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- the organization presets have no cluster, COALESCE keeps their names unique as well
+CREATE UNIQUE INDEX "uk_deploymentPreset_orgId_clusterId_name" ON "deployment_preset" ("organization_id", COALESCE("cluster_id", 0), "name");
+CREATE UNIQUE INDEX "uk_deploymentPreset_orgId_clusterId_isDefault" ON "deployment_preset" ("organization_id", COALESCE("cluster_id", 0)) WHERE "is_default";
@@ -0,0 +1,18 @@
+ALTER TYPE "resource_type" ADD VALUE 'secret';
+
+CREATE TABLE IF NOT EXISTS "secret" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    name VARCHAR(128) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    organization_id INTEGER NOT NULL REFERENCES "organization"("id") ON DELETE CASCADE,
+    cluster_id INTEGER REFERENCES "cluster"("id") ON DELETE CASCADE,
+    -- the key/value pairs encrypted with the secrets encryption key of the config
+    encrypted_data TEXT NOT NULL,
+    creator_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX "uk_secret_orgId_clusterId_name" ON "secret" ("organization_id", COALESCE("cluster_id", 0), "name");
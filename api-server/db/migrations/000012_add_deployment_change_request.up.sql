@@ -0,0 +1,22 @@
+ALTER TYPE "resource_type" ADD VALUE 'deployment_change_request';
+
+CREATE TABLE IF NOT EXISTS "deployment_change_request" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    cluster_id INTEGER NOT NULL REFERENCES "cluster"("id") ON DELETE CASCADE,
+    deployment_id INTEGER NOT NULL REFERENCES "deployment"("id") ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    targets JSONB,
+    diff JSONB,
+    reviewer_id INTEGER REFERENCES "user"("id") ON DELETE SET NULL,
+    review_comment TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    creator_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX "idx_deploymentChangeRequest_clusterId_status" ON "deployment_change_request" ("cluster_id", "status");
+CREATE INDEX "idx_deploymentChangeRequest_deploymentId" ON "deployment_change_request" ("deployment_id");
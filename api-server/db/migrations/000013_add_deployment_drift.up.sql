@@ -0,0 +1 @@
+ALTER TABLE "deployment" ADD COLUMN IF NOT EXISTS "drift" JSONB;
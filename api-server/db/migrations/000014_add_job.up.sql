@@ -0,0 +1,26 @@
+ALTER TYPE "resource_type" ADD VALUE 'job';
+
+CREATE TABLE IF NOT EXISTS "job" (
+    id SERIAL PRIMARY KEY,
+    uid VARCHAR(32) UNIQUE NOT NULL DEFAULT generate_object_id(),
+    name VARCHAR(128) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    cluster_id INTEGER NOT NULL REFERENCES "cluster"("id") ON DELETE CASCADE,
+    kube_namespace VARCHAR(128) NOT NULL,
+    -- the bentos of the jobs cannot be deleted, the runs of the jobs use their images
+    bento_id INTEGER NOT NULL REFERENCES "bento"("id") ON DELETE RESTRICT,
+    type VARCHAR(32) NOT NULL,
+    -- the cron expression of the scheduled jobs
+    schedule VARCHAR(128) NOT NULL DEFAULT '',
+    suspend BOOLEAN NOT NULL DEFAULT FALSE,
+    config JSONB,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    status_updated_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    creator_id INTEGER NOT NULL REFERENCES "user"("id") ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX "uk_job_clusterId_kubeNamespace_name" ON "job" ("cluster_id", "kube_namespace", "name");
+CREATE INDEX "idx_job_bentoId" ON "job" ("bento_id");
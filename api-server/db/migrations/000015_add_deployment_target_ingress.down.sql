@@ -0,0 +1 @@
+ALTER TABLE "deployment_target" DROP COLUMN IF EXISTS "ingress";
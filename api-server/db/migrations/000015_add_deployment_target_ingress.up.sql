@@ -0,0 +1 @@
+ALTER TABLE "deployment_target" ADD COLUMN IF NOT EXISTS "ingress" JSONB;
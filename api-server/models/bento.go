@@ -22,6 +22,7 @@ type Bento struct {
 	UploadFinishedReason      string                            `json:"upload_finished_reason"`
 	Manifest                  *modelschemas.BentoManifestSchema `json:"manifest" type:"jsonb"`
 	BuildAt                   time.Time                         `json:"build_at"`
+	Checksum                  string                            `json:"checksum"`
 }
 
 func (b *Bento) GetName() string {
@@ -1,12 +1,16 @@
 package models
 
-import "github.com/bentoml/yatai-schemas/modelschemas"
+import (
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
 
 type BentoRepository struct {
 	ResourceMixin
 	CreatorAssociate
 	OrganizationAssociate
-	Description string `json:"description"`
+	Description     string                         `json:"description"`
+	RetentionPolicy *schemas.RetentionPolicySchema `json:"retention_policy" type:"jsonb"`
 }
 
 func (b *BentoRepository) GetResourceType() modelschemas.ResourceType {
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+// CanaryRollout steps the traffic weight of the canary target of a deployment revision through a schedule
+type CanaryRollout struct {
+	BaseModel
+	CreatorAssociate
+	DeploymentAssociate
+	DeploymentRevisionAssociate
+
+	// DeploymentTargetId refers to the canary target whose weight is stepped
+	DeploymentTargetId uint                                `json:"deployment_target_id"`
+	Status             schemas.CanaryRolloutStatus         `json:"status"`
+	Steps              schemas.CanaryRolloutStepsSchema    `json:"steps"`
+	Analysis           schemas.CanaryRolloutAnalysisSchema `json:"analysis"`
+	CurrentStep        uint                                `json:"current_step"`
+	StepStartedAt      time.Time                           `json:"step_started_at"`
+	// StepBaselinePodRestarts is the restart count of the canary pods when the current step started
+	StepBaselinePodRestarts uint       `json:"step_baseline_pod_restarts"`
+	Reason                  string     `json:"reason"`
+	FinishedAt              *time.Time `json:"finished_at"`
+}
+
+func (r *CanaryRollout) GetName() string {
+	return r.Uid
+}
+
+func (r *CanaryRollout) GetResourceType() modelschemas.ResourceType {
+	return schemas.ResourceTypeCanaryRollout
+}
+
+func (r *CanaryRollout) GetCurrentWeight() uint {
+	if int(r.CurrentStep) >= len(r.Steps) {
+		return 0
+	}
+	return r.Steps[r.CurrentStep].Weight
+}
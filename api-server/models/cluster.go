@@ -1,15 +1,18 @@
 package models
 
-import "github.com/bentoml/yatai-schemas/modelschemas"
+import (
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
 
 type Cluster struct {
 	ResourceMixin
 	CreatorAssociate
 	OrganizationAssociate
 
-	Description string                            `json:"description"`
-	KubeConfig  string                            `json:"kube_config"`
-	Config      *modelschemas.ClusterConfigSchema `json:"config"`
+	Description string                       `json:"description"`
+	KubeConfig  string                       `json:"kube_config"`
+	Config      *schemas.ClusterConfigSchema `json:"config"`
 }
 
 func (c *Cluster) GetResourceType() modelschemas.ResourceType {
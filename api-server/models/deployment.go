@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
 )
 
 type Deployment struct {
@@ -17,6 +18,8 @@ type Deployment struct {
 	StatusUpdatedAt *time.Time                    `json:"status_updated_at"`
 	KubeDeployToken string                        `json:"kube_deploy_token"`
 	KubeNamespace   string                        `json:"kube_namespace"`
+	// Drift is what the last drift detection found in the cluster, it is nil when the cluster matches the active revision
+	Drift *schemas.DeploymentDriftSchema `json:"drift"`
 }
 
 func (d *Deployment) GetResourceType() modelschemas.ResourceType {
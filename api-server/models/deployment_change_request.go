@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+// DeploymentChangeRequest holds a deployment change on a protected cluster until another admin of the cluster reviews it
+type DeploymentChangeRequest struct {
+	BaseModel
+	CreatorAssociate
+	ClusterAssociate
+	DeploymentAssociate
+
+	Kind    schemas.DeploymentChangeRequestKind          `json:"kind"`
+	Status  schemas.DeploymentChangeRequestStatus        `json:"status"`
+	Targets schemas.DeploymentChangeRequestTargetsSchema `json:"targets"`
+	Diff    schemas.DeploymentChangeRequestDiffSchema    `json:"diff"`
+	// ReviewerId is the admin who approved or rejected the request
+	ReviewerId    *uint      `json:"reviewer_id"`
+	ReviewComment string     `json:"review_comment"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+}
+
+func (r *DeploymentChangeRequest) GetName() string {
+	return r.Uid
+}
+
+func (r *DeploymentChangeRequest) GetResourceType() modelschemas.ResourceType {
+	return schemas.ResourceTypeDeploymentChangeRequest
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+// DeploymentPreset is a named deployment target config, the presets of a cluster shadow the organization presets of the same name
+type DeploymentPreset struct {
+	BaseModel
+	CreatorAssociate
+	OrganizationAssociate
+	NullableClusterAssociate
+
+	Name        string                               `json:"name"`
+	Description string                               `json:"description"`
+	Config      *modelschemas.DeploymentTargetConfig `json:"config"`
+	// IsDefault marks the preset used by the targets created without a config, there is at most one per organization and per cluster
+	IsDefault bool `json:"is_default"`
+}
+
+func (p *DeploymentPreset) GetName() string {
+	return p.Name
+}
+
+func (p *DeploymentPreset) GetResourceType() modelschemas.ResourceType {
+	return schemas.ResourceTypeDeploymentPreset
+}
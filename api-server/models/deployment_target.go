@@ -1,6 +1,9 @@
 package models
 
-import "github.com/bentoml/yatai-schemas/modelschemas"
+import (
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
 
 type DeploymentTarget struct {
 	BaseModel
@@ -12,6 +15,8 @@ type DeploymentTarget struct {
 	Type        modelschemas.DeploymentTargetType         `json:"type"`
 	CanaryRules *modelschemas.DeploymentTargetCanaryRules `json:"canary_rules"`
 	Config      *modelschemas.DeploymentTargetConfig      `json:"config"`
+	// Ingress exposes the target on custom hostnames, only the stable target can have one
+	Ingress *schemas.DeploymentTargetIngressSchema `json:"ingress"`
 }
 
 func (s *DeploymentTarget) GetName() string {
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+// ImageBuild is one attempt to build the image of a bento
+type ImageBuild struct {
+	BaseModel
+	CreatorAssociate
+
+	// ResourceType and ResourceId refer to the bento being built, yatai-deployment builds no model images
+	ResourceType modelschemas.ResourceType `json:"resource_type"`
+	ResourceId   uint                      `json:"resource_id"`
+
+	Attempt       uint                          `json:"attempt"`
+	Status        modelschemas.ImageBuildStatus `json:"status"`
+	PodName       string                        `json:"pod_name"`
+	FailureReason string                        `json:"failure_reason"`
+	// Logs are the builder pod logs captured when the build finished, they outlive the pod
+	Logs        string     `json:"logs"`
+	StartedAt   *time.Time `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	NextRetryAt *time.Time `json:"next_retry_at"`
+}
+
+func (b *ImageBuild) GetName() string {
+	return fmt.Sprintf("%s-%d-%d", b.ResourceType, b.ResourceId, b.Attempt)
+}
+
+func (b *ImageBuild) GetResourceType() modelschemas.ResourceType {
+	return schemas.ResourceTypeImageBuild
+}
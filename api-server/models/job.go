@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+// Job runs a bento image to completion as a kubernetes job, or on a schedule as a kubernetes cron job
+type Job struct {
+	ResourceMixin
+	CreatorAssociate
+	ClusterAssociate
+	BentoAssociate
+
+	Description   string          `json:"description"`
+	KubeNamespace string          `json:"kube_namespace"`
+	Type          schemas.JobType `json:"type"`
+	// Schedule is the cron expression of the scheduled jobs
+	Schedule        string                   `json:"schedule"`
+	Suspend         bool                     `json:"suspend"`
+	Config          *schemas.JobConfigSchema `json:"config"`
+	Status          schemas.JobStatus        `json:"status"`
+	StatusUpdatedAt *time.Time               `json:"status_updated_at"`
+}
+
+func (j *Job) GetResourceType() modelschemas.ResourceType {
+	return schemas.ResourceTypeJob
+}
@@ -21,6 +21,7 @@ type Model struct {
 	UploadFinishedReason      string                            `json:"upload_finished_reason"`
 	Manifest                  *modelschemas.ModelManifestSchema `json:"manifest" type:"jsonb"`
 	BuildAt                   time.Time                         `json:"build_at"`
+	Checksum                  string                            `json:"checksum"`
 }
 
 func (b *Model) GetName() string {
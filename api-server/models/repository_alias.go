@@ -0,0 +1,46 @@
+package models
+
+import (
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+// RepositoryAlias is a movable name in a bento or model repository that points at one of its versions
+type RepositoryAlias struct {
+	BaseModel
+	CreatorAssociate
+	OrganizationAssociate
+
+	// ResourceType and ResourceId refer to the bento repository or the model repository
+	ResourceType modelschemas.ResourceType `json:"resource_type"`
+	ResourceId   uint                      `json:"resource_id"`
+
+	Name string `json:"name"`
+	// TargetId is the id of the bento or model the alias points at
+	TargetId uint `json:"target_id"`
+}
+
+func (r *RepositoryAlias) GetName() string {
+	return r.Name
+}
+
+func (r *RepositoryAlias) GetResourceType() modelschemas.ResourceType {
+	return schemas.ResourceTypeRepositoryAlias
+}
+
+type RepositoryAliasHistory struct {
+	BaseModel
+	CreatorAssociate
+
+	RepositoryAliasId uint   `json:"repository_alias_id"`
+	TargetId          uint   `json:"target_id"`
+	Version           string `json:"version"`
+}
+
+func (r *RepositoryAliasHistory) GetName() string {
+	return r.Version
+}
+
+func (r *RepositoryAliasHistory) GetResourceType() modelschemas.ResourceType {
+	return schemas.ResourceTypeRepositoryAlias
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+// Secret is a set of key/value pairs referenced by the envs of the deployments, the secrets of a cluster shadow the organization secrets of the same name
+type Secret struct {
+	BaseModel
+	CreatorAssociate
+	OrganizationAssociate
+	NullableClusterAssociate
+
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// EncryptedData is the json encoded key/value pairs encrypted with the secrets encryption key of the config
+	EncryptedData string `json:"-"`
+}
+
+func (s *Secret) GetName() string {
+	return s.Name
+}
+
+func (s *Secret) GetResourceType() modelschemas.ResourceType {
+	return schemas.ResourceTypeSecret
+}
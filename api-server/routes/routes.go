@@ -141,6 +141,11 @@ func NewRouter() (*fizz.Fizz, error) {
 		fizz.Summary("Tail cluster pod log"),
 	}, tonic.Handler(controllersv1.LogController.TailClusterPodLog, 200))
 
+	wsRootGroup.GET("/clusters/:clusterName/namespaces/:kubeNamespace/jobs/:jobName/tail", []fizz.OperationOption{
+		fizz.ID("Tail job pod log"),
+		fizz.Summary("Tail the pod log of a job run"),
+	}, tonic.Handler(controllersv1.LogController.TailJobPodLog, 200))
+
 	wsRootGroup.GET("/clusters/:clusterName/namespaces/:kubeNamespace/deployments/:deploymentName/terminal", []fizz.OperationOption{
 		fizz.ID("Deployment pod terminal"),
 		fizz.Summary("Deployment pod terminal"),
@@ -238,6 +243,11 @@ func NewRouter() (*fizz.Fizz, error) {
 		fizz.Summary("List all models"),
 	}, tonic.Handler(controllersv1.ModelController.ListAll, 200))
 
+	apiRootGroup.GET("/models/unreferenced", []fizz.OperationOption{
+		fizz.ID("List unreferenced models"),
+		fizz.Summary("List models that are not referenced by any bento"),
+	}, tonic.Handler(controllersv1.ModelController.ListUnreferenced, 200))
+
 	publicApiRootGroup.POST("/setup", []fizz.OperationOption{
 		fizz.ID("Setup admin user, org, cluster for selfhosted mode"),
 		fizz.Summary("Setup admin user, org, cluster for selfhosted mode"),
@@ -461,6 +471,16 @@ func organizationRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("List organization deployments"),
 	}, tonic.Handler(controllersv1.DeploymentController.ListOrganizationDeployments, 200))
 
+	grp.GET("/deployments/manifests", []fizz.OperationOption{
+		fizz.ID("Export organization deployments"),
+		fizz.Summary("Export the deployments of all the clusters as yaml manifests"),
+	}, tonic.Handler(controllersv1.DeploymentManifestController.ExportOrganizationDeployments, 200))
+
+	grp.POST("/deployments/manifests/import", []fizz.OperationOption{
+		fizz.ID("Import organization deployments"),
+		fizz.Summary("Apply deployment manifests to the clusters named in their metadata and report the plan"),
+	}, tonic.Handler(controllersv1.DeploymentManifestController.ImportOrganizationDeployments, 200))
+
 	grp.GET("/orgs", []fizz.OperationOption{
 		fizz.ID("List organizations"),
 		fizz.Summary("List organizations"),
@@ -471,6 +491,9 @@ func organizationRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Create organization"),
 	}, tonic.Handler(controllersv1.OrganizationController.Create, 200))
 
+	organizationDeploymentPresetRoutes(grp)
+	organizationSecretRoutes(grp)
+
 	// clusterRoutes(resourceGrp)
 	// bentoRepositoryRoutes(resourceGrp)
 	// modelRepositoryRoutes(resourceGrp)
@@ -557,6 +580,14 @@ func clusterRoutes(grp *fizz.RouterGroup) {
 
 	yataiComponentRoutes(resourceGrp)
 	deploymentRoutes(resourceGrp)
+	jobRoutes(resourceGrp)
+	clusterDeploymentPresetRoutes(resourceGrp)
+	clusterSecretRoutes(resourceGrp)
+
+	resourceGrp.GET("/deployment_change_requests", []fizz.OperationOption{
+		fizz.ID("List cluster deployment change requests"),
+		fizz.Summary("List the change requests of the deployments in the cluster"),
+	}, tonic.Handler(controllersv1.DeploymentChangeRequestController.ListClusterChangeRequests, 200))
 }
 
 func bentoRepositoryRoutes(grp *fizz.RouterGroup) {
@@ -574,6 +605,46 @@ func bentoRepositoryRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Update a bento repository"),
 	}, tonic.Handler(controllersv1.BentoRepositoryController.Update, 200))
 
+	resourceGrp.GET("/retention_policy", []fizz.OperationOption{
+		fizz.ID("Get a bento repository retention policy"),
+		fizz.Summary("Get a bento repository retention policy"),
+	}, tonic.Handler(controllersv1.BentoRepositoryController.GetRetentionPolicy, 200))
+
+	resourceGrp.PUT("/retention_policy", []fizz.OperationOption{
+		fizz.ID("Update a bento repository retention policy"),
+		fizz.Summary("Update a bento repository retention policy"),
+	}, tonic.Handler(controllersv1.BentoRepositoryController.UpdateRetentionPolicy, 200))
+
+	resourceGrp.GET("/retention_policy/dry_run", []fizz.OperationOption{
+		fizz.ID("List bentos that would be removed by the bento repository retention policy"),
+		fizz.Summary("List bentos that would be removed by the bento repository retention policy"),
+	}, tonic.Handler(controllersv1.BentoRepositoryController.ListExpiredBentos, 200))
+
+	resourceGrp.GET("/aliases", []fizz.OperationOption{
+		fizz.ID("List bento repository aliases"),
+		fizz.Summary("List bento repository aliases"),
+	}, tonic.Handler(controllersv1.BentoRepositoryController.ListAliases, 200))
+
+	resourceGrp.PUT("/aliases/:aliasName", []fizz.OperationOption{
+		fizz.ID("Set a bento repository alias"),
+		fizz.Summary("Point a bento repository alias at a version"),
+	}, tonic.Handler(controllersv1.BentoRepositoryController.SetAlias, 200))
+
+	resourceGrp.DELETE("/aliases/:aliasName", []fizz.OperationOption{
+		fizz.ID("Delete a bento repository alias"),
+		fizz.Summary("Delete a bento repository alias"),
+	}, tonic.Handler(controllersv1.BentoRepositoryController.DeleteAlias, 200))
+
+	resourceGrp.GET("/aliases/:aliasName/histories", []fizz.OperationOption{
+		fizz.ID("List bento repository alias histories"),
+		fizz.Summary("List bento repository alias histories"),
+	}, tonic.Handler(controllersv1.BentoRepositoryController.ListAliasHistories, 200))
+
+	resourceGrp.GET("/diff", []fizz.OperationOption{
+		fizz.ID("Diff two bentos"),
+		fizz.Summary("Compare the manifests and the labels of two bento versions"),
+	}, tonic.Handler(controllersv1.BentoRepositoryController.Diff, 200))
+
 	resourceGrp.GET("/deployments", []fizz.OperationOption{
 		fizz.ID("List bento repository deployments"),
 		fizz.Summary("List bento repository deployments"),
@@ -607,6 +678,21 @@ func bentoRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Update a bento"),
 	}, tonic.Handler(controllersv1.BentoController.Update, 200))
 
+	resourceGrp.DELETE("", []fizz.OperationOption{
+		fizz.ID("Delete a bento"),
+		fizz.Summary("Delete a bento"),
+	}, tonic.Handler(controllersv1.BentoController.Delete, 200))
+
+	resourceGrp.POST("/verify_checksum", []fizz.OperationOption{
+		fizz.ID("Verify a bento checksum"),
+		fizz.Summary("Verify a bento checksum"),
+	}, tonic.Handler(controllersv1.BentoController.VerifyChecksum, 200))
+
+	resourceGrp.POST("/copy", []fizz.OperationOption{
+		fizz.ID("Copy a bento"),
+		fizz.Summary("Copy a bento to another repository or organization"),
+	}, tonic.Handler(controllersv1.BentoController.Copy, 200))
+
 	resourceGrp.PATCH("/update_image_build_status_syncing_at", []fizz.OperationOption{
 		fizz.ID("Update a bento image build status syncing_at"),
 		fizz.Summary("Update a bento image build status syncing_at"),
@@ -652,6 +738,16 @@ func bentoRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("List bento image builder pods"),
 	}, tonic.Handler(controllersv1.BentoController.ListImageBuilderPods, 200))
 
+	resourceGrp.GET("/image_builds", []fizz.OperationOption{
+		fizz.ID("List bento image builds"),
+		fizz.Summary("List bento image builds"),
+	}, tonic.Handler(controllersv1.BentoController.ListImageBuilds, 200))
+
+	resourceGrp.POST("/rebuild_image", []fizz.OperationOption{
+		fizz.ID("Rebuild a bento image"),
+		fizz.Summary("Rebuild a bento image"),
+	}, tonic.Handler(controllersv1.BentoController.RebuildImage, 200))
+
 	grp.GET("", []fizz.OperationOption{
 		fizz.ID("List bentos"),
 		fizz.Summary("List bentos"),
@@ -677,6 +773,130 @@ func yataiComponentRoutes(grp *fizz.RouterGroup) {
 	}, tonic.Handler(controllersv1.YataiComponentController.Register, 200))
 }
 
+func organizationDeploymentPresetRoutes(grp *fizz.RouterGroup) {
+	grp = grp.Group("/deployment_presets", "organization deployment presets", "organization deployment presets")
+
+	resourceGrp := grp.Group("/:presetName", "organization deployment preset resource", "organization deployment preset resource")
+
+	resourceGrp.GET("", []fizz.OperationOption{
+		fizz.ID("Get an organization deployment preset"),
+		fizz.Summary("Get an organization deployment preset"),
+	}, tonic.Handler(controllersv1.DeploymentPresetController.GetOrganizationPreset, 200))
+
+	resourceGrp.PATCH("", []fizz.OperationOption{
+		fizz.ID("Update an organization deployment preset"),
+		fizz.Summary("Update an organization deployment preset"),
+	}, tonic.Handler(controllersv1.DeploymentPresetController.UpdateOrganizationPreset, 200))
+
+	resourceGrp.DELETE("", []fizz.OperationOption{
+		fizz.ID("Delete an organization deployment preset"),
+		fizz.Summary("Delete an organization deployment preset"),
+	}, tonic.Handler(controllersv1.DeploymentPresetController.DeleteOrganizationPreset, 200))
+
+	grp.GET("", []fizz.OperationOption{
+		fizz.ID("List organization deployment presets"),
+		fizz.Summary("List organization deployment presets"),
+	}, tonic.Handler(controllersv1.DeploymentPresetController.ListOrganizationPresets, 200))
+
+	grp.POST("", []fizz.OperationOption{
+		fizz.ID("Create an organization deployment preset"),
+		fizz.Summary("Create an organization deployment preset"),
+	}, tonic.Handler(controllersv1.DeploymentPresetController.CreateOrganizationPreset, 200))
+}
+
+func clusterDeploymentPresetRoutes(grp *fizz.RouterGroup) {
+	grp = grp.Group("/deployment_presets", "cluster deployment presets", "cluster deployment presets")
+
+	resourceGrp := grp.Group("/:presetName", "cluster deployment preset resource", "cluster deployment preset resource")
+
+	resourceGrp.GET("", []fizz.OperationOption{
+		fizz.ID("Get a cluster deployment preset"),
+		fizz.Summary("Get a cluster deployment preset"),
+	}, tonic.Handler(controllersv1.DeploymentPresetController.GetClusterPreset, 200))
+
+	resourceGrp.PATCH("", []fizz.OperationOption{
+		fizz.ID("Update a cluster deployment preset"),
+		fizz.Summary("Update a cluster deployment preset"),
+	}, tonic.Handler(controllersv1.DeploymentPresetController.UpdateClusterPreset, 200))
+
+	resourceGrp.DELETE("", []fizz.OperationOption{
+		fizz.ID("Delete a cluster deployment preset"),
+		fizz.Summary("Delete a cluster deployment preset"),
+	}, tonic.Handler(controllersv1.DeploymentPresetController.DeleteClusterPreset, 200))
+
+	grp.GET("", []fizz.OperationOption{
+		fizz.ID("List cluster deployment presets"),
+		fizz.Summary("List cluster deployment presets, the organization presets are not included"),
+	}, tonic.Handler(controllersv1.DeploymentPresetController.ListClusterPresets, 200))
+
+	grp.POST("", []fizz.OperationOption{
+		fizz.ID("Create a cluster deployment preset"),
+		fizz.Summary("Create a cluster deployment preset"),
+	}, tonic.Handler(controllersv1.DeploymentPresetController.CreateClusterPreset, 200))
+}
+
+func organizationSecretRoutes(grp *fizz.RouterGroup) {
+	grp = grp.Group("/secrets", "organization secrets", "organization secrets")
+
+	resourceGrp := grp.Group("/:secretName", "organization secret resource", "organization secret resource")
+
+	resourceGrp.GET("", []fizz.OperationOption{
+		fizz.ID("Get an organization secret"),
+		fizz.Summary("Get an organization secret, the values are never returned"),
+	}, tonic.Handler(controllersv1.SecretController.GetOrganizationSecret, 200))
+
+	resourceGrp.PATCH("", []fizz.OperationOption{
+		fizz.ID("Update an organization secret"),
+		fizz.Summary("Update an organization secret"),
+	}, tonic.Handler(controllersv1.SecretController.UpdateOrganizationSecret, 200))
+
+	resourceGrp.DELETE("", []fizz.OperationOption{
+		fizz.ID("Delete an organization secret"),
+		fizz.Summary("Delete an organization secret"),
+	}, tonic.Handler(controllersv1.SecretController.DeleteOrganizationSecret, 200))
+
+	grp.GET("", []fizz.OperationOption{
+		fizz.ID("List organization secrets"),
+		fizz.Summary("List organization secrets"),
+	}, tonic.Handler(controllersv1.SecretController.ListOrganizationSecrets, 200))
+
+	grp.POST("", []fizz.OperationOption{
+		fizz.ID("Create an organization secret"),
+		fizz.Summary("Create an organization secret"),
+	}, tonic.Handler(controllersv1.SecretController.CreateOrganizationSecret, 200))
+}
+
+func clusterSecretRoutes(grp *fizz.RouterGroup) {
+	grp = grp.Group("/secrets", "cluster secrets", "cluster secrets")
+
+	resourceGrp := grp.Group("/:secretName", "cluster secret resource", "cluster secret resource")
+
+	resourceGrp.GET("", []fizz.OperationOption{
+		fizz.ID("Get a cluster secret"),
+		fizz.Summary("Get a cluster secret, the values are never returned"),
+	}, tonic.Handler(controllersv1.SecretController.GetClusterSecret, 200))
+
+	resourceGrp.PATCH("", []fizz.OperationOption{
+		fizz.ID("Update a cluster secret"),
+		fizz.Summary("Update a cluster secret"),
+	}, tonic.Handler(controllersv1.SecretController.UpdateClusterSecret, 200))
+
+	resourceGrp.DELETE("", []fizz.OperationOption{
+		fizz.ID("Delete a cluster secret"),
+		fizz.Summary("Delete a cluster secret"),
+	}, tonic.Handler(controllersv1.SecretController.DeleteClusterSecret, 200))
+
+	grp.GET("", []fizz.OperationOption{
+		fizz.ID("List cluster secrets"),
+		fizz.Summary("List cluster secrets, the organization secrets are not included"),
+	}, tonic.Handler(controllersv1.SecretController.ListClusterSecrets, 200))
+
+	grp.POST("", []fizz.OperationOption{
+		fizz.ID("Create a cluster secret"),
+		fizz.Summary("Create a cluster secret"),
+	}, tonic.Handler(controllersv1.SecretController.CreateClusterSecret, 200))
+}
+
 func deploymentRoutes(grp *fizz.RouterGroup) {
 	namespacedGrp := grp.Group("/namespaces/:kubeNamespace/deployments", "deployments", "deployments")
 	grp = grp.Group("/deployments", "cluster deployments", "cluster deployments")
@@ -693,6 +913,16 @@ func deploymentRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Update a deployment"),
 	}, tonic.Handler(controllersv1.DeploymentController.Update, 200))
 
+	resourceGrp.PUT("/apply", []fizz.OperationOption{
+		fizz.ID("Apply a deployment"),
+		fizz.Summary("Create the deployment or update it to the desired spec, a revision is only created when the targets change"),
+	}, tonic.Handler(controllersv1.DeploymentController.Apply, 200))
+
+	resourceGrp.PATCH("/dry_run", []fizz.OperationOption{
+		fizz.ID("Dry run a deployment update"),
+		fizz.Summary("Render and validate a deployment update against the cluster without applying it"),
+	}, tonic.Handler(controllersv1.DeploymentController.UpdateDryRun, 200))
+
 	resourceGrp.POST("/sync_status", []fizz.OperationOption{
 		fizz.ID("Sync a deployment status"),
 		fizz.Summary("Sync a deployment status"),
@@ -703,6 +933,16 @@ func deploymentRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Terminate a deployment"),
 	}, tonic.Handler(controllersv1.DeploymentController.Terminate, 200))
 
+	resourceGrp.POST("/resync", []fizz.OperationOption{
+		fizz.ID("Re-sync a deployment"),
+		fizz.Summary("Re-apply the active revision to the cluster to undo the drift"),
+	}, tonic.Handler(controllersv1.DeploymentController.Resync, 200))
+
+	resourceGrp.POST("/promote", []fizz.OperationOption{
+		fizz.ID("Promote a deployment"),
+		fizz.Summary("Recreate the deployment in another cluster with the same targets and bentos"),
+	}, tonic.Handler(controllersv1.DeploymentController.Promote, 200))
+
 	resourceGrp.DELETE("", []fizz.OperationOption{
 		fizz.ID("Delete a deployment"),
 		fizz.Summary("Delete a deployment"),
@@ -723,7 +963,71 @@ func deploymentRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Create deployment"),
 	}, tonic.Handler(controllersv1.DeploymentController.Create, 200))
 
+	grp.POST("/dry_run", []fizz.OperationOption{
+		fizz.ID("Dry run a deployment creation"),
+		fizz.Summary("Render and validate a new deployment against the cluster without creating it"),
+	}, tonic.Handler(controllersv1.DeploymentController.CreateDryRun, 200))
+
+	grp.GET("/manifests", []fizz.OperationOption{
+		fizz.ID("Export cluster deployments"),
+		fizz.Summary("Export the deployments of the cluster as yaml manifests"),
+	}, tonic.Handler(controllersv1.DeploymentManifestController.ExportClusterDeployments, 200))
+
+	grp.POST("/manifests/import", []fizz.OperationOption{
+		fizz.ID("Import cluster deployments"),
+		fizz.Summary("Apply deployment manifests to the cluster and report the plan"),
+	}, tonic.Handler(controllersv1.DeploymentManifestController.ImportClusterDeployments, 200))
+
 	deploymentRevisionRoutes(resourceGrp)
+	canaryRolloutRoutes(resourceGrp)
+	deploymentChangeRequestRoutes(resourceGrp)
+}
+
+func jobRoutes(grp *fizz.RouterGroup) {
+	namespacedGrp := grp.Group("/namespaces/:kubeNamespace/jobs", "jobs", "jobs")
+	grp = grp.Group("/jobs", "cluster jobs", "cluster jobs")
+
+	resourceGrp := namespacedGrp.Group("/:jobName", "job resource", "job resource")
+
+	resourceGrp.GET("", []fizz.OperationOption{
+		fizz.ID("Get a job"),
+		fizz.Summary("Get a job"),
+	}, tonic.Handler(controllersv1.JobController.Get, 200))
+
+	resourceGrp.PATCH("", []fizz.OperationOption{
+		fizz.ID("Update a job"),
+		fizz.Summary("Update a job"),
+	}, tonic.Handler(controllersv1.JobController.Update, 200))
+
+	resourceGrp.DELETE("", []fizz.OperationOption{
+		fizz.ID("Delete a job"),
+		fizz.Summary("Delete a job with the history of its runs"),
+	}, tonic.Handler(controllersv1.JobController.Delete, 200))
+
+	resourceGrp.POST("/run", []fizz.OperationOption{
+		fizz.ID("Run a job"),
+		fizz.Summary("Start a run of the job right away"),
+	}, tonic.Handler(controllersv1.JobController.Run, 200))
+
+	resourceGrp.GET("/runs", []fizz.OperationOption{
+		fizz.ID("List job runs"),
+		fizz.Summary("List the runs of the job that are kept in the cluster"),
+	}, tonic.Handler(controllersv1.JobController.ListRuns, 200))
+
+	resourceGrp.POST("/sync_status", []fizz.OperationOption{
+		fizz.ID("Sync a job status"),
+		fizz.Summary("Sync a job status"),
+	}, tonic.Handler(controllersv1.JobController.SyncStatus, 200))
+
+	grp.GET("", []fizz.OperationOption{
+		fizz.ID("List cluster jobs"),
+		fizz.Summary("List cluster jobs"),
+	}, tonic.Handler(controllersv1.JobController.List, 200))
+
+	grp.POST("", []fizz.OperationOption{
+		fizz.ID("Create job"),
+		fizz.Summary("Create a job, a one-off job runs right away"),
+	}, tonic.Handler(controllersv1.JobController.Create, 200))
 }
 
 func deploymentRevisionRoutes(grp *fizz.RouterGroup) {
@@ -736,12 +1040,74 @@ func deploymentRevisionRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Get a deployment revision"),
 	}, tonic.Handler(controllersv1.DeploymentRevisionController.Get, 200))
 
+	resourceGrp.POST("/rollback", []fizz.OperationOption{
+		fizz.ID("Rollback to a deployment revision"),
+		fizz.Summary("Redeploy the targets of a previous deployment revision as a new revision"),
+	}, tonic.Handler(controllersv1.DeploymentRevisionController.Rollback, 200))
+
 	grp.GET("", []fizz.OperationOption{
 		fizz.ID("List deployment revisions"),
 		fizz.Summary("List deployment revisions"),
 	}, tonic.Handler(controllersv1.DeploymentRevisionController.List, 200))
 }
 
+func canaryRolloutRoutes(grp *fizz.RouterGroup) {
+	grp = grp.Group("/canary_rollouts", "canary rollouts", "canary rollouts")
+
+	resourceGrp := grp.Group("/:rolloutUid", "canary rollout resource", "canary rollout resource")
+
+	resourceGrp.GET("", []fizz.OperationOption{
+		fizz.ID("Get a canary rollout"),
+		fizz.Summary("Get a canary rollout"),
+	}, tonic.Handler(controllersv1.CanaryRolloutController.Get, 200))
+
+	resourceGrp.POST("/promote", []fizz.OperationOption{
+		fizz.ID("Promote a canary rollout"),
+		fizz.Summary("Skip the remaining steps and promote the canary to stable"),
+	}, tonic.Handler(controllersv1.CanaryRolloutController.Promote, 200))
+
+	resourceGrp.POST("/abort", []fizz.OperationOption{
+		fizz.ID("Abort a canary rollout"),
+		fizz.Summary("Route all the traffic back to the stable target"),
+	}, tonic.Handler(controllersv1.CanaryRolloutController.Abort, 200))
+
+	grp.GET("", []fizz.OperationOption{
+		fizz.ID("List canary rollouts"),
+		fizz.Summary("List canary rollouts"),
+	}, tonic.Handler(controllersv1.CanaryRolloutController.List, 200))
+
+	grp.POST("", []fizz.OperationOption{
+		fizz.ID("Start a canary rollout"),
+		fizz.Summary("Step the weight of the canary target of the active revision through a schedule"),
+	}, tonic.Handler(controllersv1.CanaryRolloutController.Create, 200))
+}
+
+func deploymentChangeRequestRoutes(grp *fizz.RouterGroup) {
+	grp = grp.Group("/change_requests", "deployment change requests", "deployment change requests")
+
+	resourceGrp := grp.Group("/:requestUid", "deployment change request resource", "deployment change request resource")
+
+	resourceGrp.GET("", []fizz.OperationOption{
+		fizz.ID("Get a deployment change request"),
+		fizz.Summary("Get a deployment change request"),
+	}, tonic.Handler(controllersv1.DeploymentChangeRequestController.Get, 200))
+
+	resourceGrp.POST("/approve", []fizz.OperationOption{
+		fizz.ID("Approve a deployment change request"),
+		fizz.Summary("Approve a deployment change request and apply the change"),
+	}, tonic.Handler(controllersv1.DeploymentChangeRequestController.Approve, 200))
+
+	resourceGrp.POST("/reject", []fizz.OperationOption{
+		fizz.ID("Reject a deployment change request"),
+		fizz.Summary("Reject a deployment change request"),
+	}, tonic.Handler(controllersv1.DeploymentChangeRequestController.Reject, 200))
+
+	grp.GET("", []fizz.OperationOption{
+		fizz.ID("List deployment change requests"),
+		fizz.Summary("List deployment change requests"),
+	}, tonic.Handler(controllersv1.DeploymentChangeRequestController.List, 200))
+}
+
 func terminalRecordRoutes(grp *fizz.RouterGroup) {
 	grp = grp.Group("/terminal_records", "terminal records", "terminal records")
 
@@ -773,6 +1139,21 @@ func modelRepositoryRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Update a model repository"),
 	}, tonic.Handler(controllersv1.ModelRepositoryController.Update, 200))
 
+	resourceGrp.GET("/retention_policy", []fizz.OperationOption{
+		fizz.ID("Get a model repository retention policy"),
+		fizz.Summary("Get a model repository retention policy"),
+	}, tonic.Handler(controllersv1.ModelRepositoryController.GetRetentionPolicy, 200))
+
+	resourceGrp.PUT("/retention_policy", []fizz.OperationOption{
+		fizz.ID("Update a model repository retention policy"),
+		fizz.Summary("Update a model repository retention policy"),
+	}, tonic.Handler(controllersv1.ModelRepositoryController.UpdateRetentionPolicy, 200))
+
+	resourceGrp.GET("/retention_policy/dry_run", []fizz.OperationOption{
+		fizz.ID("List models that would be removed by the model repository retention policy"),
+		fizz.Summary("List models that would be removed by the model repository retention policy"),
+	}, tonic.Handler(controllersv1.ModelRepositoryController.ListExpiredModels, 200))
+
 	grp.GET("", []fizz.OperationOption{
 		fizz.ID("List model repositories"),
 		fizz.Summary("List model repositories"),
@@ -801,6 +1182,26 @@ func modelRoutes(grp *fizz.RouterGroup) {
 		fizz.Summary("Update a model"),
 	}, tonic.Handler(controllersv1.ModelController.Update, 200))
 
+	resourceGrp.DELETE("", []fizz.OperationOption{
+		fizz.ID("Delete a model"),
+		fizz.Summary("Delete a model"),
+	}, tonic.Handler(controllersv1.ModelController.Delete, 200))
+
+	resourceGrp.POST("/verify_checksum", []fizz.OperationOption{
+		fizz.ID("Verify a model checksum"),
+		fizz.Summary("Verify a model checksum"),
+	}, tonic.Handler(controllersv1.ModelController.VerifyChecksum, 200))
+
+	resourceGrp.POST("/copy", []fizz.OperationOption{
+		fizz.ID("Copy a model"),
+		fizz.Summary("Copy a model to another repository or organization"),
+	}, tonic.Handler(controllersv1.ModelController.Copy, 200))
+
+	resourceGrp.GET("/impact", []fizz.OperationOption{
+		fizz.ID("Get a model impact"),
+		fizz.Summary("List the bentos and the active deployments that depend on a model"),
+	}, tonic.Handler(controllersv1.ModelController.GetImpact, 200))
+
 	resourceGrp.GET("/bentos", []fizz.OperationOption{
 		fizz.ID("List model bentos"),
 		fizz.Summary("List model bentos"),
@@ -0,0 +1,84 @@
+package schemas
+
+import "github.com/bentoml/yatai-schemas/modelschemas"
+
+type StringChangeSchema struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type SizeChangeSchema struct {
+	From  uint  `json:"from"`
+	To    uint  `json:"to"`
+	Delta int64 `json:"delta"`
+}
+
+type ModelChangeSchema struct {
+	Name         string   `json:"name"`
+	FromVersions []string `json:"from_versions"`
+	ToVersions   []string `json:"to_versions"`
+}
+
+type ModelsDiffSchema struct {
+	// Added and Removed are model tags
+	Added   []string            `json:"added"`
+	Removed []string            `json:"removed"`
+	Changed []ModelChangeSchema `json:"changed"`
+}
+
+type ApiChangeSchema struct {
+	Name string                      `json:"name"`
+	From modelschemas.BentoApiSchema `json:"from"`
+	To   modelschemas.BentoApiSchema `json:"to"`
+}
+
+type ApisDiffSchema struct {
+	Added   []string          `json:"added"`
+	Removed []string          `json:"removed"`
+	Changed []ApiChangeSchema `json:"changed"`
+}
+
+type RunnerChangeSchema struct {
+	Name string                         `json:"name"`
+	From modelschemas.BentoRunnerSchema `json:"from"`
+	To   modelschemas.BentoRunnerSchema `json:"to"`
+}
+
+type RunnersDiffSchema struct {
+	Added   []string             `json:"added"`
+	Removed []string             `json:"removed"`
+	Changed []RunnerChangeSchema `json:"changed"`
+}
+
+type LabelChangeSchema struct {
+	Key  string `json:"key"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type LabelsDiffSchema struct {
+	Added   modelschemas.LabelItemsSchema `json:"added"`
+	Removed modelschemas.LabelItemsSchema `json:"removed"`
+	Changed []LabelChangeSchema           `json:"changed"`
+}
+
+// BentoManifestDiffSchema describes what changes when going from one bento version to another, unchanged scalar fields are omitted
+type BentoManifestDiffSchema struct {
+	From           string              `json:"from"`
+	To             string              `json:"to"`
+	Service        *StringChangeSchema `json:"service,omitempty"`
+	BentomlVersion *StringChangeSchema `json:"bentoml_version,omitempty"`
+	Size           *SizeChangeSchema   `json:"size,omitempty"`
+	Models         ModelsDiffSchema    `json:"models"`
+	Apis           ApisDiffSchema      `json:"apis"`
+	Runners        RunnersDiffSchema   `json:"runners"`
+	Labels         LabelsDiffSchema    `json:"labels"`
+}
+
+func (s *BentoManifestDiffSchema) IsEmpty() bool {
+	return s.Service == nil && s.BentomlVersion == nil && s.Size == nil &&
+		len(s.Models.Added)+len(s.Models.Removed)+len(s.Models.Changed) == 0 &&
+		len(s.Apis.Added)+len(s.Apis.Removed)+len(s.Apis.Changed) == 0 &&
+		len(s.Runners.Added)+len(s.Runners.Removed)+len(s.Runners.Changed) == 0 &&
+		len(s.Labels.Added)+len(s.Labels.Removed)+len(s.Labels.Changed) == 0
+}
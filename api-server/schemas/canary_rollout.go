@@ -0,0 +1,111 @@
+package schemas
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+)
+
+type CanaryRolloutStatus string
+
+const (
+	CanaryRolloutStatusRunning  CanaryRolloutStatus = "running"
+	CanaryRolloutStatusPromoted CanaryRolloutStatus = "promoted"
+	CanaryRolloutStatusAborted  CanaryRolloutStatus = "aborted"
+)
+
+type CanaryRolloutStepSchema struct {
+	// Weight is the percentage of the traffic routed to the canary
+	Weight uint `json:"weight"`
+	// DurationSeconds is how long the canary is observed at this weight before moving on
+	DurationSeconds uint `json:"duration_seconds"`
+}
+
+// CanaryRolloutStepsSchema is the weight schedule of a rollout, the canary is promoted after the last step
+type CanaryRolloutStepsSchema []CanaryRolloutStepSchema
+
+func (s CanaryRolloutStepsSchema) Validate() error {
+	if len(s) == 0 {
+		return errors.New("steps cannot be empty")
+	}
+	var lastWeight uint
+	for idx, step := range s {
+		if step.Weight == 0 || step.Weight > 100 {
+			return errors.Errorf("the weight of step %d must be between 1 and 100", idx)
+		}
+		if step.Weight <= lastWeight {
+			return errors.Errorf("the weight of step %d must be greater than the weight of the previous step", idx)
+		}
+		if step.DurationSeconds == 0 {
+			return errors.Errorf("the duration_seconds of step %d must be greater than 0", idx)
+		}
+		lastWeight = step.Weight
+	}
+	return nil
+}
+
+func (s *CanaryRolloutStepsSchema) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), s)
+}
+
+func (s CanaryRolloutStepsSchema) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// CanaryRolloutAnalysisSchema holds the thresholds of the health signals checked at every step,
+// the rollout is aborted as soon as one of them is exceeded
+type CanaryRolloutAnalysisSchema struct {
+	// MaxPodRestarts is the number of canary container restarts tolerated during a step
+	MaxPodRestarts uint `json:"max_pod_restarts"`
+	// MaxWarningEvents is the number of warning kube events of the canary pods tolerated during a step
+	MaxWarningEvents uint `json:"max_warning_events"`
+	// PrometheusQuery is an optional instant query, the rollout is aborted when its highest sample exceeds PrometheusMaxValue
+	PrometheusQuery    string   `json:"prometheus_query,omitempty"`
+	PrometheusMaxValue *float64 `json:"prometheus_max_value,omitempty"`
+}
+
+func (a *CanaryRolloutAnalysisSchema) Validate() error {
+	if a.PrometheusQuery != "" && a.PrometheusMaxValue == nil {
+		return errors.New("prometheus_max_value is required when prometheus_query is set")
+	}
+	return nil
+}
+
+func (a *CanaryRolloutAnalysisSchema) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), a)
+}
+
+func (a CanaryRolloutAnalysisSchema) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+type CreateCanaryRolloutSchema struct {
+	Steps    CanaryRolloutStepsSchema    `json:"steps"`
+	Analysis CanaryRolloutAnalysisSchema `json:"analysis"`
+}
+
+type CanaryRolloutSchema struct {
+	schemasv1.BaseSchema
+	Creator                 *schemasv1.UserSchema       `json:"creator"`
+	DeploymentRevisionUid   string                      `json:"deployment_revision_uid"`
+	DeploymentTargetUid     string                      `json:"deployment_target_uid"`
+	Status                  CanaryRolloutStatus         `json:"status"`
+	Steps                   CanaryRolloutStepsSchema    `json:"steps"`
+	Analysis                CanaryRolloutAnalysisSchema `json:"analysis"`
+	CurrentStep             uint                        `json:"current_step"`
+	CurrentWeight           uint                        `json:"current_weight"`
+	StepStartedAt           time.Time                   `json:"step_started_at"`
+	StepBaselinePodRestarts uint                        `json:"step_baseline_pod_restarts"`
+	Reason                  string                      `json:"reason"`
+	FinishedAt              *time.Time                  `json:"finished_at"`
+}
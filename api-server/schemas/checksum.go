@@ -0,0 +1,19 @@
+package schemas
+
+import "github.com/bentoml/yatai-schemas/schemasv1"
+
+type BentoFullSchema struct {
+	schemasv1.BentoFullSchema
+	Checksum string `json:"checksum"`
+}
+
+type ModelFullSchema struct {
+	schemasv1.ModelFullSchema
+	Checksum string `json:"checksum"`
+}
+
+type ChecksumVerificationSchema struct {
+	Checksum       string `json:"checksum"`
+	ActualChecksum string `json:"actual_checksum"`
+	Corrupted      bool   `json:"corrupted"`
+}
@@ -0,0 +1,48 @@
+package schemas
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+)
+
+// ClusterConfigSchema adds the cluster settings that are not part of yatai-schemas yet, they are stored in the same config column
+type ClusterConfigSchema struct {
+	modelschemas.ClusterConfigSchema
+	// Protected clusters only deploy or terminate the deployments after another admin of the cluster approves the change
+	Protected bool `json:"protected"`
+	// BentoImageRepository is where yatai-deployment pushes the bento images, the jobs of the cluster run <repository>:yatai.<bento repository>.<version>
+	BentoImageRepository string `json:"bento_image_repository"`
+}
+
+func (c *ClusterConfigSchema) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(value.(string)), c)
+}
+
+func (c *ClusterConfigSchema) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+type ClusterFullSchema struct {
+	schemasv1.ClusterFullSchema
+	// Config shadows the config of the embedded schema so that the protected flag is returned
+	Config **ClusterConfigSchema `json:"config"`
+}
+
+type CreateClusterSchema struct {
+	schemasv1.CreateClusterSchema
+	Config *ClusterConfigSchema `json:"config"`
+}
+
+type UpdateClusterSchema struct {
+	schemasv1.UpdateClusterSchema
+	Config **ClusterConfigSchema `json:"config"`
+}
@@ -0,0 +1,15 @@
+package schemas
+
+type CopyBentoSchema struct {
+	// TargetOrganizationName defaults to the organization of the bento
+	TargetOrganizationName string `json:"target_organization_name"`
+	// TargetBentoRepositoryName defaults to the repository name of the bento
+	TargetBentoRepositoryName string `json:"target_bento_repository_name"`
+}
+
+type CopyModelSchema struct {
+	// TargetOrganizationName defaults to the organization of the model
+	TargetOrganizationName string `json:"target_organization_name"`
+	// TargetModelRepositoryName defaults to the repository name of the model
+	TargetModelRepositoryName string `json:"target_model_repository_name"`
+}
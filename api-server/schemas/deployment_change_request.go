@@ -0,0 +1,85 @@
+package schemas
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+)
+
+type DeploymentChangeRequestKind string
+
+const (
+	// DeploymentChangeRequestKindDeploy deploys a new revision with the targets of the request
+	DeploymentChangeRequestKindDeploy    DeploymentChangeRequestKind = "deploy"
+	DeploymentChangeRequestKindTerminate DeploymentChangeRequestKind = "terminate"
+	// DeploymentChangeRequestKindResync re-applies the active revision to undo the edits made out of band
+	DeploymentChangeRequestKindResync DeploymentChangeRequestKind = "resync"
+)
+
+type DeploymentChangeRequestStatus string
+
+const (
+	DeploymentChangeRequestStatusPending  DeploymentChangeRequestStatus = "pending"
+	DeploymentChangeRequestStatusApproved DeploymentChangeRequestStatus = "approved"
+	DeploymentChangeRequestStatusRejected DeploymentChangeRequestStatus = "rejected"
+	// DeploymentChangeRequestStatusSuperseded is given to a pending request when a newer change of the deployment is requested
+	DeploymentChangeRequestStatusSuperseded DeploymentChangeRequestStatus = "superseded"
+)
+
+func (s DeploymentChangeRequestStatus) Ptr() *DeploymentChangeRequestStatus {
+	return &s
+}
+
+// DeploymentChangeRequestTargetsSchema are the targets deployed when the request is approved, their presets are already resolved
+type DeploymentChangeRequestTargetsSchema []*CreateDeploymentTargetSchema
+
+func (s *DeploymentChangeRequestTargetsSchema) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), s)
+}
+
+func (s DeploymentChangeRequestTargetsSchema) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// DeploymentChangeRequestDiffSchema compares the active targets of the deployment with the requested ones
+type DeploymentChangeRequestDiffSchema []*KubeObjectDiffItemSchema
+
+func (s *DeploymentChangeRequestDiffSchema) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), s)
+}
+
+func (s DeploymentChangeRequestDiffSchema) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+type DeploymentChangeRequestSchema struct {
+	schemasv1.BaseSchema
+	Creator        *schemasv1.UserSchema                `json:"creator"`
+	Reviewer       *schemasv1.UserSchema                `json:"reviewer"`
+	Cluster        string                               `json:"cluster"`
+	KubeNamespace  string                               `json:"kube_namespace"`
+	DeploymentName string                               `json:"deployment_name"`
+	Kind           DeploymentChangeRequestKind          `json:"kind"`
+	Status         DeploymentChangeRequestStatus        `json:"status"`
+	Targets        DeploymentChangeRequestTargetsSchema `json:"targets"`
+	Diff           DeploymentChangeRequestDiffSchema    `json:"diff"`
+	ReviewComment  string                               `json:"review_comment"`
+	ReviewedAt     *time.Time                           `json:"reviewed_at"`
+}
+
+type DeploymentChangeRequestListSchema struct {
+	schemasv1.BaseListSchema
+	Items []*DeploymentChangeRequestSchema `json:"items"`
+}
+
+type ReviewDeploymentChangeRequestSchema struct {
+	Comment string `json:"comment"`
+}
@@ -0,0 +1,58 @@
+package schemas
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+)
+
+type DeploymentDriftType string
+
+const (
+	// DeploymentDriftTypeModified means the live BentoDeployment was edited out of band, e.g. with kubectl edit
+	DeploymentDriftTypeModified DeploymentDriftType = "modified"
+	// DeploymentDriftTypeMissing means the BentoDeployment of the active revision does not exist in the cluster
+	DeploymentDriftTypeMissing DeploymentDriftType = "missing"
+	// DeploymentDriftTypeUnmanaged means a BentoDeployment exists in the cluster without an active revision backing it
+	DeploymentDriftTypeUnmanaged DeploymentDriftType = "unmanaged"
+)
+
+type DeploymentDriftItemSchema struct {
+	Type             DeploymentDriftType `json:"type"`
+	KubeResourceName string              `json:"kube_resource_name"`
+	// Diff compares the spec of the live object with the recorded one, the values of the secret envs are redacted
+	Diff []*KubeObjectDiffItemSchema `json:"diff,omitempty"`
+}
+
+type DeploymentDriftSchema struct {
+	DeploymentRevisionUid string                       `json:"deployment_revision_uid,omitempty"`
+	Items                 []*DeploymentDriftItemSchema `json:"items"`
+	DetectedAt            time.Time                    `json:"detected_at"`
+}
+
+func (s *DeploymentDriftSchema) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), s)
+}
+
+func (s *DeploymentDriftSchema) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// DeploymentSchema adds the drift detected in the cluster to the deployment schema of yatai-schemas
+type DeploymentSchema struct {
+	schemasv1.DeploymentSchema
+	Drift *DeploymentDriftSchema `json:"drift"`
+}
+
+type DeploymentListSchema struct {
+	schemasv1.BaseListSchema
+	Items []*DeploymentSchema `json:"items"`
+}
@@ -0,0 +1,33 @@
+package schemas
+
+type KubeObjectDiffOperation string
+
+const (
+	KubeObjectDiffOperationAdded   KubeObjectDiffOperation = "added"
+	KubeObjectDiffOperationRemoved KubeObjectDiffOperation = "removed"
+	KubeObjectDiffOperationChanged KubeObjectDiffOperation = "changed"
+)
+
+type KubeObjectDiffItemSchema struct {
+	// Path is the dotted json path of the field, list items are addressed by their index
+	Path      string                  `json:"path"`
+	Operation KubeObjectDiffOperation `json:"operation"`
+	From      interface{}             `json:"from,omitempty"`
+	To        interface{}             `json:"to,omitempty"`
+}
+
+type DeploymentTargetDryRunSchema struct {
+	BentoRepository string `json:"bento_repository"`
+	Bento           string `json:"bento"`
+	// Desired and Live are the BentoDeployment custom resources, Live is null when the deployment does not exist in the cluster yet
+	Desired interface{} `json:"desired"`
+	Live    interface{} `json:"live"`
+	// Diff compares the spec of the live object with the spec of the desired one
+	Diff           []*KubeObjectDiffItemSchema `json:"diff"`
+	AdmissionError string                      `json:"admission_error,omitempty"`
+	Warnings       []string                    `json:"warnings"`
+}
+
+type DeploymentDryRunSchema struct {
+	Targets []*DeploymentTargetDryRunSchema `json:"targets"`
+}
@@ -0,0 +1,113 @@
+package schemas
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	KubeAnnotationCertManagerIssuer        = "cert-manager.io/issuer"
+	KubeAnnotationCertManagerClusterIssuer = "cert-manager.io/cluster-issuer"
+)
+
+// DeploymentTargetIngressTLSSchema serves the hostnames over https, the certificate is either an existing kubernetes tls secret
+// or issued by cert-manager into the secret
+type DeploymentTargetIngressTLSSchema struct {
+	// SecretName is the kubernetes tls secret in the namespace of the deployment, it defaults to <deployment name>-tls when an issuer is given
+	SecretName string `json:"secret_name,omitempty"`
+	// Issuer and ClusterIssuer are the cert-manager issuers asked to issue the certificate, at most one of them can be given
+	Issuer        string `json:"issuer,omitempty"`
+	ClusterIssuer string `json:"cluster_issuer,omitempty"`
+}
+
+// DeploymentTargetIngressSchema exposes a deployment target on custom hostnames, it is applied as an ingress managed by yatai
+// next to the one of the default hostname
+type DeploymentTargetIngressSchema struct {
+	Hostnames []string `json:"hostnames"`
+	// PathPrefix only routes the requests under the prefix to the target, it defaults to /
+	PathPrefix string                            `json:"path_prefix,omitempty"`
+	TLS        *DeploymentTargetIngressTLSSchema `json:"tls,omitempty"`
+	// Annotations are added to the ingress, e.g. the rate limits of the ingress controller
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+func (s *DeploymentTargetIngressSchema) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), s)
+}
+
+func (s *DeploymentTargetIngressSchema) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *DeploymentTargetIngressSchema) GetPathPrefix() string {
+	if s.PathPrefix == "" {
+		return "/"
+	}
+	return s.PathPrefix
+}
+
+func (s *DeploymentTargetIngressSchema) Validate() error {
+	if len(s.Hostnames) == 0 {
+		return errors.New("the ingress must have at least one hostname")
+	}
+	seen := make(map[string]struct{}, len(s.Hostnames))
+	for _, hostname := range s.Hostnames {
+		if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+			// a wildcard hostname is only allowed as the leftmost label
+			if !strings.HasPrefix(hostname, "*.") || len(validation.IsDNS1123Subdomain(hostname[2:])) > 0 {
+				return errors.Errorf("invalid hostname %q: %s", hostname, strings.Join(errs, ", "))
+			}
+		}
+		if _, ok := seen[hostname]; ok {
+			return errors.Errorf("duplicated hostname %q", hostname)
+		}
+		seen[hostname] = struct{}{}
+	}
+	if s.PathPrefix != "" && !strings.HasPrefix(s.PathPrefix, "/") {
+		return errors.Errorf("the path prefix %q must start with /", s.PathPrefix)
+	}
+	if s.TLS != nil {
+		if s.TLS.Issuer != "" && s.TLS.ClusterIssuer != "" {
+			return errors.New("the tls can have either an issuer or a cluster issuer, not both")
+		}
+		if s.TLS.SecretName == "" && s.TLS.Issuer == "" && s.TLS.ClusterIssuer == "" {
+			return errors.New("the tls must have a secret name or a cert-manager issuer")
+		}
+		if s.TLS.SecretName != "" {
+			if errs := validation.IsDNS1123Subdomain(s.TLS.SecretName); len(errs) > 0 {
+				return errors.Errorf("invalid tls secret name %q: %s", s.TLS.SecretName, strings.Join(errs, ", "))
+			}
+		}
+	}
+	for key := range s.Annotations {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return errors.Errorf("invalid annotation %q: %s", key, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// GetURLs returns the urls the hostnames are served on
+func (s *DeploymentTargetIngressSchema) GetURLs() []string {
+	scheme := "http"
+	if s.TLS != nil {
+		scheme = "https"
+	}
+	pathPrefix := strings.TrimSuffix(s.GetPathPrefix(), "/")
+	urls := make([]string, 0, len(s.Hostnames))
+	for _, hostname := range s.Hostnames {
+		urls = append(urls, fmt.Sprintf("%s://%s%s", scheme, hostname, pathPrefix))
+	}
+	return urls
+}
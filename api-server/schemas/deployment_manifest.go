@@ -0,0 +1,104 @@
+package schemas
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+)
+
+const (
+	DeploymentManifestApiVersionV1 = "yatai.ai/v1"
+	DeploymentManifestKind         = "Deployment"
+)
+
+type DeploymentManifestMetadataSchema struct {
+	Cluster     string                        `json:"cluster"`
+	Name        string                        `json:"name"`
+	Namespace   string                        `json:"namespace"`
+	Labels      modelschemas.LabelItemsSchema `json:"labels,omitempty"`
+	Description string                        `json:"description,omitempty"`
+}
+
+type DeploymentManifestTargetSchema struct {
+	Type modelschemas.DeploymentTargetType `json:"type"`
+	// Bento is the tag of the bento, in the form of <bento repository>:<version>
+	Bento string `json:"bento"`
+	// Preset is the name of the deployment preset the config is applied on top of
+	Preset      string                                    `json:"preset,omitempty"`
+	CanaryRules *modelschemas.DeploymentTargetCanaryRules `json:"canary_rules,omitempty"`
+	Config      *modelschemas.DeploymentTargetConfig      `json:"config,omitempty"`
+	Ingress     *DeploymentTargetIngressSchema            `json:"ingress,omitempty"`
+}
+
+func (t *DeploymentManifestTargetSchema) GetBentoRepositoryAndVersion() (string, string, error) {
+	parts := strings.SplitN(t.Bento, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid bento tag %q, it must be in the form of <bento repository>:<version>", t.Bento)
+	}
+	return parts[0], parts[1], nil
+}
+
+type DeploymentManifestSpecSchema struct {
+	Targets []*DeploymentManifestTargetSchema `json:"targets"`
+}
+
+// DeploymentManifestSchema is the declarative form of a deployment, it is what the deployments are exported to and imported from
+type DeploymentManifestSchema struct {
+	ApiVersion string                           `json:"apiVersion"`
+	Kind       string                           `json:"kind"`
+	Metadata   DeploymentManifestMetadataSchema `json:"metadata"`
+	Spec       DeploymentManifestSpecSchema     `json:"spec"`
+}
+
+func (m *DeploymentManifestSchema) Validate() error {
+	if m.ApiVersion != DeploymentManifestApiVersionV1 {
+		return errors.Errorf("unsupported apiVersion %q, only %s is supported", m.ApiVersion, DeploymentManifestApiVersionV1)
+	}
+	if m.Kind != DeploymentManifestKind {
+		return errors.Errorf("unsupported kind %q, only %s is supported", m.Kind, DeploymentManifestKind)
+	}
+	if m.Metadata.Name == "" {
+		return errors.New("metadata.name cannot be empty")
+	}
+	if m.Metadata.Namespace == "" {
+		return errors.Errorf("metadata.namespace of deployment %s cannot be empty", m.Metadata.Name)
+	}
+	if len(m.Spec.Targets) == 0 {
+		return errors.Errorf("spec.targets of deployment %s cannot be empty", m.Metadata.Name)
+	}
+	for idx, target := range m.Spec.Targets {
+		if _, _, err := target.GetBentoRepositoryAndVersion(); err != nil {
+			return errors.Wrapf(err, "spec.targets[%d] of deployment %s", idx, m.Metadata.Name)
+		}
+	}
+	return nil
+}
+
+type DeploymentManifestPlanAction string
+
+const (
+	DeploymentManifestPlanActionCreate    DeploymentManifestPlanAction = "create"
+	DeploymentManifestPlanActionUpdate    DeploymentManifestPlanAction = "update"
+	DeploymentManifestPlanActionUnchanged DeploymentManifestPlanAction = "unchanged"
+)
+
+type DeploymentManifestPlanItemSchema struct {
+	Cluster   string                       `json:"cluster"`
+	Namespace string                       `json:"namespace"`
+	Name      string                       `json:"name"`
+	Action    DeploymentManifestPlanAction `json:"action"`
+	// TargetsChanged tells whether a new revision is deployed, an update without it only touches the labels or the description
+	TargetsChanged bool `json:"targets_changed"`
+}
+
+type DeploymentManifestPlanSchema struct {
+	DryRun bool                                `json:"dry_run"`
+	Items  []*DeploymentManifestPlanItemSchema `json:"items"`
+}
+
+type ImportDeploymentManifestsSchema struct {
+	Manifests []*DeploymentManifestSchema `json:"manifests"`
+	DryRun    bool                        `json:"dry_run"`
+}
@@ -0,0 +1,49 @@
+package schemas
+
+import (
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+)
+
+type DeploymentPresetSchema struct {
+	schemasv1.BaseSchema
+	Creator *schemasv1.UserSchema `json:"creator"`
+	// Cluster is empty for the organization presets
+	Cluster     string                               `json:"cluster,omitempty"`
+	Name        string                               `json:"name"`
+	Description string                               `json:"description"`
+	Config      *modelschemas.DeploymentTargetConfig `json:"config"`
+	IsDefault   bool                                 `json:"is_default"`
+}
+
+type DeploymentPresetListSchema struct {
+	schemasv1.BaseListSchema
+	Items []*DeploymentPresetSchema `json:"items"`
+}
+
+type CreateDeploymentPresetSchema struct {
+	Name        string                               `json:"name"`
+	Description string                               `json:"description"`
+	Config      *modelschemas.DeploymentTargetConfig `json:"config"`
+	IsDefault   bool                                 `json:"is_default"`
+}
+
+type UpdateDeploymentPresetSchema struct {
+	Description *string                              `json:"description"`
+	Config      *modelschemas.DeploymentTargetConfig `json:"config"`
+	IsDefault   *bool                                `json:"is_default"`
+}
+
+// CreateDeploymentTargetSchema lets a deployment target reference a preset by name,
+// the fields set in its config are applied on top of the config of the preset
+type CreateDeploymentTargetSchema struct {
+	schemasv1.CreateDeploymentTargetSchema
+	Preset  string                         `json:"preset,omitempty"`
+	Ingress *DeploymentTargetIngressSchema `json:"ingress,omitempty"`
+}
+
+// UpdateDeploymentSchema shadows the targets of the embedded schema so that they can reference a deployment preset and have an ingress
+type UpdateDeploymentSchema struct {
+	schemasv1.UpdateDeploymentSchema
+	Targets []*CreateDeploymentTargetSchema `json:"targets"`
+}
@@ -0,0 +1,27 @@
+package schemas
+
+import (
+	"github.com/bentoml/yatai-schemas/modelschemas"
+)
+
+// DeploymentPromotionOverridesSchema replaces parts of the target configs in the destination cluster,
+// it only applies to the api server of the bento, the runners keep their configs
+type DeploymentPromotionOverridesSchema struct {
+	Resources *modelschemas.DeploymentTargetResources `json:"resources,omitempty"`
+	// Envs are merged into the envs of the targets by key
+	Envs        *[]*modelschemas.LabelItemSchema `json:"envs,omitempty"`
+	MinReplicas *int32                           `json:"min_replicas,omitempty"`
+	MaxReplicas *int32                           `json:"max_replicas,omitempty"`
+	// Ingress exposes the promoted stable targets on custom hostnames, the ingress of the source is never carried over
+	// because its hostnames and certificates belong to the source cluster
+	Ingress *DeploymentTargetIngressSchema `json:"ingress,omitempty"`
+}
+
+type PromoteDeploymentSchema struct {
+	Cluster string `json:"cluster"`
+	// KubeNamespace defaults to the deployment namespace of the destination cluster
+	KubeNamespace string `json:"kube_namespace"`
+	// Name defaults to the name of the promoted deployment
+	Name      string                              `json:"name"`
+	Overrides *DeploymentPromotionOverridesSchema `json:"overrides"`
+}
@@ -0,0 +1,21 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+)
+
+type ImageBuildSchema struct {
+	schemasv1.BaseSchema
+	Creator       *schemasv1.UserSchema         `json:"creator"`
+	Attempt       uint                          `json:"attempt"`
+	Status        modelschemas.ImageBuildStatus `json:"status"`
+	PodName       string                        `json:"pod_name"`
+	FailureReason string                        `json:"failure_reason"`
+	Logs          string                        `json:"logs"`
+	StartedAt     *time.Time                    `json:"started_at"`
+	FinishedAt    *time.Time                    `json:"finished_at"`
+	NextRetryAt   *time.Time                    `json:"next_retry_at"`
+}
@@ -0,0 +1,131 @@
+package schemas
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+)
+
+type JobType string
+
+const (
+	// JobTypeJob runs the bento once every time the job is run
+	JobTypeJob JobType = "job"
+	// JobTypeCronJob runs the bento on the schedule of the job
+	JobTypeCronJob JobType = "cron_job"
+)
+
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+	// JobStatusScheduled is given to a cron job that has not run yet
+	JobStatusScheduled JobStatus = "scheduled"
+	JobStatusSuspended JobStatus = "suspended"
+	// JobStatusUnknown is given to a job whose kubernetes object cannot be found
+	JobStatusUnknown JobStatus = "unknown"
+)
+
+func (s JobStatus) Ptr() *JobStatus {
+	return &s
+}
+
+// JobConfigSchema is how the bento container of a job runs
+type JobConfigSchema struct {
+	// Image overrides the bento image, it is required when the cluster has no bento image repository
+	Image string `json:"image,omitempty"`
+	// ImagePullSecrets are the kubernetes secrets of the namespace the image is pulled with, the bento image is also pulled with the yatai-regcred secret of yatai-deployment
+	ImagePullSecrets []string `json:"image_pull_secrets,omitempty"`
+	// Args are passed to the entrypoint of the bento image, e.g. ["python", "batch.py"]
+	Args      []string                                `json:"args,omitempty"`
+	Resources *modelschemas.DeploymentTargetResources `json:"resources,omitempty"`
+	Envs      []*modelschemas.LabelItemSchema         `json:"envs,omitempty"`
+	// BackoffLimit is how many times a failed run is retried, kubernetes retries 6 times when it is not set
+	BackoffLimit          *int32 `json:"backoff_limit,omitempty"`
+	ActiveDeadlineSeconds *int64 `json:"active_deadline_seconds,omitempty"`
+	// SuccessfulRunsHistoryLimit and FailedRunsHistoryLimit are how many finished runs are kept with their pods and logs
+	SuccessfulRunsHistoryLimit *int32 `json:"successful_runs_history_limit,omitempty"`
+	FailedRunsHistoryLimit     *int32 `json:"failed_runs_history_limit,omitempty"`
+}
+
+func (c *JobConfigSchema) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), c)
+}
+
+func (c *JobConfigSchema) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+type JobSchema struct {
+	schemasv1.ResourceSchema
+	Creator         *schemasv1.UserSchema                `json:"creator"`
+	Cluster         string                               `json:"cluster"`
+	KubeNamespace   string                               `json:"kube_namespace"`
+	Description     string                               `json:"description"`
+	Bento           *schemasv1.BentoWithRepositorySchema `json:"bento"`
+	Type            JobType                              `json:"type"`
+	Schedule        string                               `json:"schedule"`
+	Suspend         bool                                 `json:"suspend"`
+	Config          *JobConfigSchema                     `json:"config"`
+	Status          JobStatus                            `json:"status"`
+	StatusUpdatedAt *time.Time                           `json:"status_updated_at"`
+}
+
+type JobListSchema struct {
+	schemasv1.BaseListSchema
+	Items []*JobSchema `json:"items"`
+}
+
+type CreateJobSchema struct {
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	KubeNamespace   string `json:"kube_namespace"`
+	BentoRepository string `json:"bento_repository"`
+	// Bento is the version or an alias of the bento
+	Bento    string                        `json:"bento"`
+	Type     JobType                       `json:"type"`
+	Schedule string                        `json:"schedule"`
+	Suspend  bool                          `json:"suspend"`
+	Config   *JobConfigSchema              `json:"config"`
+	Labels   modelschemas.LabelItemsSchema `json:"labels"`
+}
+
+type UpdateJobSchema struct {
+	Description     *string                        `json:"description"`
+	BentoRepository *string                        `json:"bento_repository"`
+	Bento           *string                        `json:"bento"`
+	Schedule        *string                        `json:"schedule"`
+	Suspend         *bool                          `json:"suspend"`
+	Config          *JobConfigSchema               `json:"config"`
+	Labels          *modelschemas.LabelItemsSchema `json:"labels"`
+}
+
+// JobRunSchema is a kubernetes job created for the yatai job, by running it or by its schedule
+type JobRunSchema struct {
+	Name          string     `json:"name"`
+	Status        JobStatus  `json:"status"`
+	StartedAt     *time.Time `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+	FailureReason string     `json:"failure_reason"`
+	// Active, Succeeded and Failed count the pods of the run, Failed grows with the retries
+	Active    int32    `json:"active"`
+	Succeeded int32    `json:"succeeded"`
+	Failed    int32    `json:"failed"`
+	PodNames  []string `json:"pod_names"`
+}
+
+type JobRunListSchema struct {
+	Items []*JobRunSchema `json:"items"`
+}
@@ -0,0 +1,25 @@
+package schemas
+
+import (
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai-schemas/schemasv1"
+)
+
+type ModelImpactDeploymentSchema struct {
+	Cluster              string                            `json:"cluster"`
+	KubeNamespace        string                            `json:"kube_namespace"`
+	Deployment           string                            `json:"deployment"`
+	Status               modelschemas.DeploymentStatus     `json:"status"`
+	DeploymentTargetUid  string                            `json:"deployment_target_uid"`
+	DeploymentTargetType modelschemas.DeploymentTargetType `json:"deployment_target_type"`
+}
+
+type ModelImpactBentoSchema struct {
+	Bento       *schemasv1.BentoWithRepositorySchema `json:"bento"`
+	Deployments []*ModelImpactDeploymentSchema       `json:"deployments"`
+}
+
+type ModelImpactSchema struct {
+	Model  *schemasv1.ModelSchema    `json:"model"`
+	Bentos []*ModelImpactBentoSchema `json:"bentos"`
+}
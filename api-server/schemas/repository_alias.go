@@ -0,0 +1,20 @@
+package schemas
+
+import "github.com/bentoml/yatai-schemas/schemasv1"
+
+type RepositoryAliasSchema struct {
+	schemasv1.BaseSchema
+	Creator *schemasv1.UserSchema `json:"creator"`
+	Name    string                `json:"name"`
+	Version string                `json:"version"`
+}
+
+type RepositoryAliasHistorySchema struct {
+	schemasv1.BaseSchema
+	Creator *schemasv1.UserSchema `json:"creator"`
+	Version string                `json:"version"`
+}
+
+type SetRepositoryAliasSchema struct {
+	Version string `json:"version"`
+}
@@ -0,0 +1,14 @@
+package schemas
+
+import "github.com/bentoml/yatai-schemas/modelschemas"
+
+// resource types that are not part of yatai-schemas yet
+const (
+	ResourceTypeRepositoryAlias         modelschemas.ResourceType = "repository_alias"
+	ResourceTypeImageBuild              modelschemas.ResourceType = "image_build"
+	ResourceTypeCanaryRollout           modelschemas.ResourceType = "canary_rollout"
+	ResourceTypeDeploymentPreset        modelschemas.ResourceType = "deployment_preset"
+	ResourceTypeSecret                  modelschemas.ResourceType = "secret"
+	ResourceTypeDeploymentChangeRequest modelschemas.ResourceType = "deployment_change_request"
+	ResourceTypeJob                     modelschemas.ResourceType = "job"
+)
@@ -0,0 +1,60 @@
+package schemas
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+)
+
+// RetentionPolicySchema describes which versions of a bento or model repository
+// can be garbage collected. A version is collected only when it matches every rule
+// that is set: it is not one of the newest KeepLast versions and it is older than
+// MaxAgeDays. Versions carrying any of the KeepLabels are never collected.
+type RetentionPolicySchema struct {
+	KeepLast   *uint                         `json:"keep_last,omitempty"`
+	MaxAgeDays *uint                         `json:"max_age_days,omitempty"`
+	KeepLabels modelschemas.LabelItemsSchema `json:"keep_labels,omitempty"`
+}
+
+func (p *RetentionPolicySchema) IsEmpty() bool {
+	return p == nil || (p.KeepLast == nil && p.MaxAgeDays == nil)
+}
+
+func (p *RetentionPolicySchema) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.KeepLast != nil && *p.KeepLast == 0 {
+		return errors.New("keep_last must be greater than 0")
+	}
+	if p.MaxAgeDays != nil && *p.MaxAgeDays == 0 {
+		return errors.New("max_age_days must be greater than 0")
+	}
+	for _, label := range p.KeepLabels {
+		if label.Key == "" {
+			return errors.New("the key of keep_labels cannot be empty")
+		}
+	}
+	return nil
+}
+
+func (p *RetentionPolicySchema) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), p)
+}
+
+func (p *RetentionPolicySchema) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
+
+type UpdateRetentionPolicySchema struct {
+	RetentionPolicy *RetentionPolicySchema `json:"retention_policy"`
+}
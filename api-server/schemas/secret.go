@@ -0,0 +1,69 @@
+package schemas
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+)
+
+// SecretSchema never carries the values of the secret, only their keys
+type SecretSchema struct {
+	schemasv1.BaseSchema
+	Creator *schemasv1.UserSchema `json:"creator"`
+	// Cluster is empty for the organization secrets
+	Cluster     string   `json:"cluster,omitempty"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Keys        []string `json:"keys"`
+}
+
+type SecretListSchema struct {
+	schemasv1.BaseListSchema
+	Items []*SecretSchema `json:"items"`
+}
+
+type CreateSecretSchema struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Data        map[string]string `json:"data"`
+}
+
+type UpdateSecretSchema struct {
+	Description *string `json:"description"`
+	// Data replaces all the key/value pairs of the secret when it is set
+	Data map[string]string `json:"data"`
+}
+
+// SecretEnvValuePrefix marks the env values that reference a secret key, e.g. secret://openai/api_key
+const SecretEnvValuePrefix = "secret://"
+
+// SecretKeyRef references a key of a yatai secret, or of a kubernetes secret in the namespace of the deployment when there is no yatai secret with the name
+type SecretKeyRef struct {
+	Name string
+	Key  string
+}
+
+func (r *SecretKeyRef) String() string {
+	return SecretEnvValuePrefix + r.Name + "/" + r.Key
+}
+
+// ParseSecretKeyRef returns nil when the env value is a plain value, only a secret name and a secret key that are both valid
+// make a reference, so that a plain value that happens to start with the prefix is left as it is
+func ParseSecretKeyRef(value string) *SecretKeyRef {
+	if !strings.HasPrefix(value, SecretEnvValuePrefix) {
+		return nil
+	}
+	pieces := strings.SplitN(strings.TrimPrefix(value, SecretEnvValuePrefix), "/", 2)
+	if len(pieces) != 2 {
+		return nil
+	}
+	if len(validation.IsDNS1123Subdomain(pieces[0])) > 0 || len(validation.IsConfigMapKey(pieces[1])) > 0 {
+		return nil
+	}
+	return &SecretKeyRef{
+		Name: pieces[0],
+		Key:  pieces[1],
+	}
+}
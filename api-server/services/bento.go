@@ -2,17 +2,16 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/huandu/xstrings"
 	"github.com/iancoleman/strcase"
-	"github.com/minio/minio-go/v7"
 	"github.com/pkg/errors"
 	"github.com/rs/xid"
 	"github.com/sirupsen/logrus"
@@ -51,6 +50,7 @@ type UpdateBentoOption struct {
 	UploadStartedAt           **time.Time
 	UploadFinishedAt          **time.Time
 	UploadFinishedReason      *string
+	Checksum                  *string
 	Labels                    *modelschemas.LabelItemsSchema
 	Manifest                  **modelschemas.BentoManifestSchema
 }
@@ -58,15 +58,18 @@ type UpdateBentoOption struct {
 type ListBentoOption struct {
 	BaseListOption
 	BaseListByLabelsOption
-	OrganizationId    *uint
-	BentoRepositoryId *uint
-	Versions          *[]string
-	ModelIds          *[]uint
-	CreatorId         *uint
-	CreatorIds        *[]uint
-	Order             *string
-	Names             *[]string
-	Ids               *[]uint
+	OrganizationId      *uint
+	BentoRepositoryId   *uint
+	Versions            *[]string
+	ModelIds            *[]uint
+	ManifestModelTag    *string
+	CreatorId           *uint
+	CreatorIds          *[]uint
+	Order               *string
+	Names               *[]string
+	Ids                 *[]uint
+	UploadStatus        *modelschemas.BentoUploadStatus
+	UploadStartedBefore *time.Time
 }
 
 func (s *bentoService) Create(ctx context.Context, opt CreateBentoOption) (bento *models.Bento, err error) {
@@ -131,275 +134,200 @@ func (s *bentoService) Create(ctx context.Context, opt CreateBentoOption) (bento
 	return
 }
 
-func (s *bentoService) PreSignUploadUrl(ctx context.Context, bento *models.Bento) (url *url.URL, err error) {
+func (s *bentoService) getStorageDriver(ctx context.Context, bento *models.Bento) (StorageDriver, error) {
 	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
 	if err != nil {
-		return
+		return nil, err
 	}
 	org, err := OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
 	if err != nil {
-		return
-	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
-	if err != nil {
-		return
-	}
-	minioClient, err := s3Config.GetMinioClient()
-	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
-		return
+		return nil, err
 	}
+	return OrganizationService.GetStorageDriver(ctx, org, StorageBucketTypeBentos)
+}
 
-	bucketName, err := s.GetS3BucketName(ctx, bento)
+func (s *bentoService) PreSignUploadUrl(ctx context.Context, bento *models.Bento) (*url.URL, error) {
+	driver, err := s.getStorageDriver(ctx, bento)
 	if err != nil {
-		return
+		return nil, err
 	}
-
-	err = s3Config.MakeSureBucket(ctx, bucketName)
+	objectName, err := s.getObjectName(ctx, bento)
 	if err != nil {
-		return
+		return nil, err
 	}
+	return driver.PreSignPutUrl(ctx, objectName, time.Hour)
+}
 
-	objectName, err := s.getS3ObjectName(ctx, bento)
+func (s *bentoService) StartMultipartUpload(ctx context.Context, bento *models.Bento) (string, error) {
+	driver, err := s.getStorageDriver(ctx, bento)
 	if err != nil {
-		return
+		return "", err
 	}
-
-	url, err = minioClient.PresignedPutObject(ctx, bucketName, objectName, time.Hour)
+	objectName, err := s.getObjectName(ctx, bento)
 	if err != nil {
-		err = errors.Wrap(err, "presigned put object")
-		return
-	}
-	if s3Config.Endpoint != s3Config.EndpointInCluster {
-		url.Host = s3Config.Endpoint
+		return "", err
 	}
-	return
+	return driver.StartMultipartUpload(ctx, objectName)
 }
 
-func (s *bentoService) StartMultipartUpload(ctx context.Context, bento *models.Bento) (uploadId string, err error) {
-	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
-	if err != nil {
-		return
-	}
-	org, err := OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
+func (s *bentoService) PreSignMultipartUploadUrl(ctx context.Context, bento *models.Bento, partNumber int, uploadId string) (*url.URL, error) {
+	driver, err := s.getStorageDriver(ctx, bento)
 	if err != nil {
-		return
-	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
-	if err != nil {
-		return
+		return nil, err
 	}
-	minioCore, err := s3Config.GetMinioCore()
+	objectName, err := s.getObjectName(ctx, bento)
 	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
-		return
+		return nil, err
 	}
+	return driver.PreSignMultipartUploadUrl(ctx, objectName, uploadId, partNumber, time.Hour)
+}
 
-	bucketName, err := s.GetS3BucketName(ctx, bento)
+func (s *bentoService) UploadMultipartPart(ctx context.Context, bento *models.Bento, partNumber int, uploadId string, reader io.Reader, partSize int64) (string, error) {
+	driver, err := s.getStorageDriver(ctx, bento)
 	if err != nil {
-		return
+		return "", err
 	}
-
-	err = s3Config.MakeSureBucket(ctx, bucketName)
+	objectName, err := s.getObjectName(ctx, bento)
 	if err != nil {
-		return
+		return "", err
 	}
+	return driver.PutMultipartUploadPart(ctx, objectName, uploadId, partNumber, reader, partSize)
+}
 
-	objectName, err := s.getS3ObjectName(ctx, bento)
+func (s *bentoService) CompleteMultipartUpload(ctx context.Context, bento *models.Bento, uploadId string, parts []StorageCompletePart) (err error) {
+	driver, err := s.getStorageDriver(ctx, bento)
 	if err != nil {
 		return
 	}
-
-	uploadId, err = minioCore.NewMultipartUpload(ctx, bucketName, objectName, minio.PutObjectOptions{})
+	objectName, err := s.getObjectName(ctx, bento)
 	if err != nil {
-		err = errors.Wrap(err, "new multipart upload")
 		return
 	}
+	err = driver.CompleteMultipartUpload(ctx, objectName, uploadId, parts)
 	return
 }
 
-func (s *bentoService) PreSignMultipartUploadUrl(ctx context.Context, bento *models.Bento, partNumber int, uploadId string) (url_ *url.URL, err error) {
-	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
-	if err != nil {
-		return
-	}
-	org, err := OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
-	if err != nil {
-		return
-	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
-	if err != nil {
-		return
-	}
-	minioCore, err := s3Config.GetMinioCore()
-	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
-		return
-	}
-
-	bucketName, err := s.GetS3BucketName(ctx, bento)
+func (s *bentoService) Upload(ctx context.Context, bento *models.Bento, reader io.Reader, objectSize int64) (err error) {
+	driver, err := s.getStorageDriver(ctx, bento)
 	if err != nil {
 		return
 	}
-
-	err = s3Config.MakeSureBucket(ctx, bucketName)
+	objectName, err := s.getObjectName(ctx, bento)
 	if err != nil {
 		return
 	}
 
-	objectName, err := s.getS3ObjectName(ctx, bento)
+	hash := sha256.New()
+	err = driver.Put(ctx, objectName, io.TeeReader(reader, hash), objectSize)
 	if err != nil {
 		return
 	}
 
-	queryValues := make(url.Values)
-	queryValues.Set("partNumber", strconv.Itoa(partNumber))
-	queryValues.Set("uploadId", uploadId)
-
-	url_, err = minioCore.Presign(ctx, http.MethodPut, bucketName, objectName, time.Hour, queryValues)
-	if err != nil {
-		err = errors.Wrap(err, "presigned put object")
-		return
-	}
-	if s3Config.Endpoint != s3Config.EndpointInCluster {
-		url_.Host = s3Config.Endpoint
-	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	_, err = s.Update(ctx, bento, UpdateBentoOption{
+		Checksum: &checksum,
+	})
 	return
 }
 
-func (s *bentoService) CompleteMultipartUpload(ctx context.Context, bento *models.Bento, uploadId string, parts []minio.CompletePart) (err error) {
-	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+func (s *bentoService) PreSignDownloadUrl(ctx context.Context, bento *models.Bento) (*url.URL, error) {
+	driver, err := s.getStorageDriver(ctx, bento)
 	if err != nil {
-		return
-	}
-	org, err := OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
-	if err != nil {
-		return
-	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
-	if err != nil {
-		return
-	}
-	minioCore, err := s3Config.GetMinioCore()
-	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
-		return
-	}
-
-	bucketName, err := s.GetS3BucketName(ctx, bento)
-	if err != nil {
-		return
-	}
-
-	err = s3Config.MakeSureBucket(ctx, bucketName)
-	if err != nil {
-		return
-	}
-
-	objectName, err := s.getS3ObjectName(ctx, bento)
-	if err != nil {
-		return
+		return nil, err
 	}
-
-	_, err = minioCore.CompleteMultipartUpload(ctx, bucketName, objectName, uploadId, parts, minio.PutObjectOptions{})
+	objectName, err := s.getObjectName(ctx, bento)
 	if err != nil {
-		err = errors.Wrap(err, "new multipart upload")
-		return
+		return nil, err
 	}
-	return
+	return driver.PreSignGetUrl(ctx, objectName, time.Hour)
 }
 
-func (s *bentoService) Upload(ctx context.Context, bento *models.Bento, reader io.Reader, objectSize int64) (err error) {
-	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
-	if err != nil {
-		return
-	}
-	org, err := OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
+func (s *bentoService) StatObject(ctx context.Context, bento *models.Bento) (*StorageObjectInfo, error) {
+	driver, err := s.getStorageDriver(ctx, bento)
 	if err != nil {
-		return
-	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
-	if err != nil {
-		return
+		return nil, err
 	}
-	minioClient, err := s3Config.GetMinioClient()
+	objectName, err := s.getObjectName(ctx, bento)
 	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
-		return
+		return nil, err
 	}
+	return driver.Stat(ctx, objectName)
+}
 
-	bucketName, err := s.GetS3BucketName(ctx, bento)
-	if err != nil {
-		return
-	}
+func (s *bentoService) Download(ctx context.Context, bento *models.Bento, writer io.Writer) error {
+	return s.DownloadWithOption(ctx, bento, writer, StorageGetOption{})
+}
 
-	err = s3Config.MakeSureBucket(ctx, bucketName)
+func (s *bentoService) DownloadWithOption(ctx context.Context, bento *models.Bento, writer io.Writer, opt StorageGetOption) error {
+	driver, err := s.getStorageDriver(ctx, bento)
 	if err != nil {
-		return
+		return err
 	}
-
-	objectName, err := s.getS3ObjectName(ctx, bento)
+	objectName, err := s.getObjectName(ctx, bento)
 	if err != nil {
-		return
+		return err
 	}
+	return driver.Get(ctx, objectName, writer, opt)
+}
 
-	logrus.Debugf("uploading to s3: %s/%s", bucketName, objectName)
-	_, err = minioClient.PutObject(ctx, bucketName, objectName, reader, objectSize, minio.PutObjectOptions{ContentType: "application/octet-stream"})
+// ComputeChecksum reads the stored object back and returns its hex encoded sha256
+func (s *bentoService) ComputeChecksum(ctx context.Context, bento *models.Bento) (checksum string, err error) {
+	hash := sha256.New()
+	err = s.Download(ctx, bento, hash)
 	if err != nil {
-		err = errors.Wrap(err, "put object")
 		return
 	}
-
-	logrus.Debugf("uploaded to s3: %s/%s", bucketName, objectName)
+	checksum = hex.EncodeToString(hash.Sum(nil))
 	return
 }
 
-func (s *bentoService) PreSignDownloadUrl(ctx context.Context, bento *models.Bento) (url *url.URL, err error) {
-	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
-	if err != nil {
-		return
-	}
-	org, err := OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
+func (s *bentoService) Delete(ctx context.Context, bento *models.Bento) (b *models.Bento, err error) {
+	// the deployment targets are deleted with the bento, so a bento of any revision is kept for the history and the rollbacks
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		BaseListOption: BaseListOption{
+			Start: utils.UintPtr(0),
+			Count: utils.UintPtr(1),
+		},
+		BentoIds: &[]uint{bento.ID},
+	})
 	if err != nil {
+		err = errors.Wrap(err, "list deployment targets")
 		return
 	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
-	if err != nil {
+	if len(deploymentTargets) > 0 {
+		deployment, err_ := DeploymentService.GetAssociatedDeployment(ctx, deploymentTargets[0])
+		if err_ != nil {
+			err = err_
+			return
+		}
+		err = errors.Errorf("bento %s is used by a revision of deployment %s, cannot delete it", bento.Version, deployment.Name)
 		return
 	}
-	minioClient, err := s3Config.GetMinioClient()
+	jobs, _, err := JobService.List(ctx, ListJobOption{
+		BaseListOption: BaseListOption{
+			Start: utils.UintPtr(0),
+			Count: utils.UintPtr(1),
+		},
+		BentoIds: &[]uint{bento.ID},
+	})
 	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
+		err = errors.Wrap(err, "list jobs")
 		return
 	}
-
-	bucketName, err := s.GetS3BucketName(ctx, bento)
-	if err != nil {
+	if len(jobs) > 0 {
+		err = errors.Errorf("bento %s is used by job %s, cannot delete it", bento.Version, jobs[0].Name)
 		return
 	}
-
-	err = s3Config.MakeSureBucket(ctx, bucketName)
+	aliasNames, err := RepositoryAliasService.ListAliasNamesByTargetIds(ctx, modelschemas.ResourceTypeBentoRepository, []uint{bento.ID})
 	if err != nil {
+		err = errors.Wrap(err, "list aliases")
 		return
 	}
-
-	objectName, err := s.getS3ObjectName(ctx, bento)
-	if err != nil {
+	if names := aliasNames[bento.ID]; len(names) > 0 {
+		err = errors.Errorf("bento %s is pointed at by alias %s, cannot delete it", bento.Version, strings.Join(names, ", "))
 		return
 	}
 
-	url, err = minioClient.PresignedGetObject(ctx, bucketName, objectName, time.Hour, nil)
-	if err != nil {
-		err = errors.Wrap(err, "presigned get object")
-		return
-	}
-	if s3Config.Endpoint != s3Config.EndpointInCluster {
-		url.Host = s3Config.Endpoint
-	}
-	return
-}
-
-func (s *bentoService) Download(ctx context.Context, bento *models.Bento, writer io.Writer) (err error) {
 	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
 	if err != nil {
 		return
@@ -408,46 +336,47 @@ func (s *bentoService) Download(ctx context.Context, bento *models.Bento, writer
 	if err != nil {
 		return
 	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
+	driver, err := OrganizationService.GetStorageDriver(ctx, org, StorageBucketTypeBentos)
 	if err != nil {
 		return
 	}
-	minioClient, err := s3Config.GetMinioClient()
+	objectName, err := s.getObjectName(ctx, bento)
 	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
 		return
 	}
 
-	bucketName, err := s.GetS3BucketName(ctx, bento)
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
 	if err != nil {
 		return
 	}
+	defer func() { df(err) }()
 
-	err = s3Config.MakeSureBucket(ctx, bucketName)
+	err = LabelService.DeleteByResource(ctx, org.ID, bento)
 	if err != nil {
 		return
 	}
-
-	objectName, err := s.getS3ObjectName(ctx, bento)
+	err = ImageBuildService.DeleteByResource(ctx, bento)
 	if err != nil {
 		return
 	}
-
-	obj, err := minioClient.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
+	err = db.Unscoped().Delete(bento).Error
 	if err != nil {
-		err = errors.Wrap(err, "get object")
 		return
 	}
 
-	_, err = io.Copy(writer, obj)
-	if err != nil {
-		err = errors.Wrap(err, "copy object")
-	}
+	// the archive cannot be restored, so it is only deleted once the row is gone for good
+	runAfterCommit(ctx, func() {
+		if err := driver.Delete(ctx, objectName); err != nil {
+			logrus.Errorf("delete the archive %s of bento %s: %v", objectName, bento.Version, err)
+		}
+	})
 
+	b = bento
 	return
 }
 
-func (s *bentoService) getS3ObjectName(ctx context.Context, bento *models.Bento) (string, error) {
+func (s *bentoService) getObjectName(ctx context.Context, bento *models.Bento) (string, error) {
 	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
 	if err != nil {
 		return "", err
@@ -456,28 +385,11 @@ func (s *bentoService) getS3ObjectName(ctx context.Context, bento *models.Bento)
 	if err != nil {
 		return "", err
 	}
-	objectName := fmt.Sprintf("bentos/%s/%s/%s.tar.gz", org.Name, bentoRepository.Name, bento.Version)
-	return objectName, nil
+	return s.formatObjectName(org, bentoRepository.Name, bento.Version), nil
 }
 
-func (s *bentoService) GetS3BucketName(ctx context.Context, bento *models.Bento) (string, error) {
-	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
-	if err != nil {
-		return "", err
-	}
-	org, err := OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
-	if err != nil {
-		return "", err
-	}
-
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
-	if err != nil {
-		return "", err
-	}
-
-	s3BucketName := s3Config.BentosBucketName
-
-	return s3BucketName, nil
+func (s *bentoService) formatObjectName(org *models.Organization, bentoRepositoryName, version string) string {
+	return fmt.Sprintf("bentos/%s/%s/%s.tar.gz", org.Name, bentoRepositoryName, version)
 }
 
 func (s *bentoService) GetTag(ctx context.Context, bento *models.Bento) (modelschemas.Tag, error) {
@@ -547,6 +459,14 @@ func (s *bentoService) Update(ctx context.Context, bento *models.Bento, opt Upda
 			}
 		}()
 	}
+	if opt.Checksum != nil {
+		updaters["checksum"] = *opt.Checksum
+		defer func() {
+			if err == nil {
+				bento.Checksum = *opt.Checksum
+			}
+		}()
+	}
 	if opt.Manifest != nil {
 		updaters["manifest"] = *opt.Manifest
 		defer func() {
@@ -647,6 +567,22 @@ func (s *bentoService) GetByVersion(ctx context.Context, bentoRepositoryId uint,
 	return &bento, nil
 }
 
+// GetByVersionOrAlias looks the version up first and falls back to the aliases of the repository
+func (s *bentoService) GetByVersionOrAlias(ctx context.Context, bentoRepository *models.BentoRepository, versionOrAlias string) (*models.Bento, error) {
+	bento, err := s.GetByVersion(ctx, bentoRepository.ID, versionOrAlias)
+	if err == nil || !utils.IsNotFound(err) {
+		return bento, err
+	}
+	alias, err_ := RepositoryAliasService.GetByName(ctx, bentoRepository, versionOrAlias)
+	if err_ != nil {
+		if utils.IsNotFound(err_) {
+			return nil, err
+		}
+		return nil, errors.Wrapf(err_, "get alias %s", versionOrAlias)
+	}
+	return s.Get(ctx, alias.TargetId)
+}
+
 func (s *bentoService) ListByUids(ctx context.Context, uids []string) ([]*models.Bento, error) {
 	bentos := make([]*models.Bento, 0, len(uids))
 	if len(uids) == 0 {
@@ -709,6 +645,9 @@ func (s *bentoService) List(ctx context.Context, opt ListBentoOption) ([]*models
 	if opt.ModelIds != nil {
 		query = query.Joins("LEFT JOIN bento_model_rel ON bento_model_rel.bento_id = bento.id").Where("bento_model_rel.model_id in (?)", *opt.ModelIds)
 	}
+	if opt.ManifestModelTag != nil {
+		query = query.Where("jsonb_exists(bento.manifest->'models', ?)", *opt.ManifestModelTag)
+	}
 	if opt.OrganizationId != nil {
 		query = query.Where("bento_repository.organization_id = ?", *opt.OrganizationId)
 	}
@@ -730,6 +669,12 @@ func (s *bentoService) List(ctx context.Context, opt ListBentoOption) ([]*models
 	if opt.CreatorIds != nil {
 		query = query.Where("bento.creator_id in (?)", *opt.CreatorIds)
 	}
+	if opt.UploadStatus != nil {
+		query = query.Where("bento.upload_status = ?", *opt.UploadStatus)
+	}
+	if opt.UploadStartedBefore != nil {
+		query = query.Where("bento.upload_started_at < ?", *opt.UploadStartedBefore)
+	}
 	query = opt.BindQueryWithKeywords(query, "bento_repository")
 	query = opt.BindQueryWithLabels(query, modelschemas.ResourceTypeBento)
 	query = query.Select("distinct(bento.*)")
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/huandu/xstrings"
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+// Diff compares the manifests and the labels of two bentos of the same repository
+func (s *bentoService) Diff(ctx context.Context, from, to *models.Bento) (*schemas.BentoManifestDiffSchema, error) {
+	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	fromLabels, err := LabelService.ListLabelItemsByResource(ctx, bentoRepository.OrganizationId, from)
+	if err != nil {
+		return nil, errors.Wrapf(err, "list labels of bento %s", from.Version)
+	}
+	toLabels, err := LabelService.ListLabelItemsByResource(ctx, bentoRepository.OrganizationId, to)
+	if err != nil {
+		return nil, errors.Wrapf(err, "list labels of bento %s", to.Version)
+	}
+	diff := DiffBentoManifests(from.Manifest, to.Manifest, fromLabels, toLabels)
+	diff.From = from.Version
+	diff.To = to.Version
+	return diff, nil
+}
+
+// DiffBentoManifests returns the structured difference between two manifests, every list in the result is sorted
+func DiffBentoManifests(from, to *modelschemas.BentoManifestSchema, fromLabels, toLabels modelschemas.LabelItemsSchema) *schemas.BentoManifestDiffSchema {
+	if from == nil {
+		from = &modelschemas.BentoManifestSchema{}
+	}
+	if to == nil {
+		to = &modelschemas.BentoManifestSchema{}
+	}
+	diff := &schemas.BentoManifestDiffSchema{
+		Models:  diffManifestModels(from.Models, to.Models),
+		Apis:    diffManifestApis(from.Apis, to.Apis),
+		Runners: diffManifestRunners(from.Runners, to.Runners),
+		Labels:  diffLabels(fromLabels, toLabels),
+	}
+	if from.Service != to.Service {
+		diff.Service = &schemas.StringChangeSchema{From: from.Service, To: to.Service}
+	}
+	if from.BentomlVersion != to.BentomlVersion {
+		diff.BentomlVersion = &schemas.StringChangeSchema{From: from.BentomlVersion, To: to.BentomlVersion}
+	}
+	if from.SizeBytes != to.SizeBytes {
+		diff.Size = &schemas.SizeChangeSchema{
+			From:  from.SizeBytes,
+			To:    to.SizeBytes,
+			Delta: int64(to.SizeBytes) - int64(from.SizeBytes),
+		}
+	}
+	return diff
+}
+
+func groupModelVersionsByName(tags []string) map[string][]string {
+	res := make(map[string][]string)
+	for _, tag := range tags {
+		name, _, version := xstrings.Partition(tag, ":")
+		res[name] = append(res[name], version)
+	}
+	for _, versions := range res {
+		sort.Strings(versions)
+	}
+	return res
+}
+
+func diffManifestModels(from, to []string) schemas.ModelsDiffSchema {
+	res := schemas.ModelsDiffSchema{
+		Added:   make([]string, 0),
+		Removed: make([]string, 0),
+		Changed: make([]schemas.ModelChangeSchema, 0),
+	}
+	fromVersions := groupModelVersionsByName(from)
+	toVersions := groupModelVersionsByName(to)
+	for name, versions := range toVersions {
+		oldVersions, ok := fromVersions[name]
+		if !ok {
+			for _, version := range versions {
+				res.Added = append(res.Added, name+":"+version)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(oldVersions, versions) {
+			res.Changed = append(res.Changed, schemas.ModelChangeSchema{
+				Name:         name,
+				FromVersions: oldVersions,
+				ToVersions:   versions,
+			})
+		}
+	}
+	for name, versions := range fromVersions {
+		if _, ok := toVersions[name]; ok {
+			continue
+		}
+		for _, version := range versions {
+			res.Removed = append(res.Removed, name+":"+version)
+		}
+	}
+	sort.Strings(res.Added)
+	sort.Strings(res.Removed)
+	sort.Slice(res.Changed, func(i, j int) bool {
+		return res.Changed[i].Name < res.Changed[j].Name
+	})
+	return res
+}
+
+func diffManifestApis(from, to map[string]modelschemas.BentoApiSchema) schemas.ApisDiffSchema {
+	res := schemas.ApisDiffSchema{
+		Added:   make([]string, 0),
+		Removed: make([]string, 0),
+		Changed: make([]schemas.ApiChangeSchema, 0),
+	}
+	for name, api := range to {
+		oldApi, ok := from[name]
+		if !ok {
+			res.Added = append(res.Added, name)
+			continue
+		}
+		if oldApi != api {
+			res.Changed = append(res.Changed, schemas.ApiChangeSchema{
+				Name: name,
+				From: oldApi,
+				To:   api,
+			})
+		}
+	}
+	for name := range from {
+		if _, ok := to[name]; !ok {
+			res.Removed = append(res.Removed, name)
+		}
+	}
+	sort.Strings(res.Added)
+	sort.Strings(res.Removed)
+	sort.Slice(res.Changed, func(i, j int) bool {
+		return res.Changed[i].Name < res.Changed[j].Name
+	})
+	return res
+}
+
+func diffManifestRunners(from, to []modelschemas.BentoRunnerSchema) schemas.RunnersDiffSchema {
+	res := schemas.RunnersDiffSchema{
+		Added:   make([]string, 0),
+		Removed: make([]string, 0),
+		Changed: make([]schemas.RunnerChangeSchema, 0),
+	}
+	fromRunners := make(map[string]modelschemas.BentoRunnerSchema, len(from))
+	for _, runner := range from {
+		fromRunners[runner.Name] = runner
+	}
+	toRunnerNames := make(map[string]struct{}, len(to))
+	for _, runner := range to {
+		toRunnerNames[runner.Name] = struct{}{}
+		oldRunner, ok := fromRunners[runner.Name]
+		if !ok {
+			res.Added = append(res.Added, runner.Name)
+			continue
+		}
+		if !reflect.DeepEqual(oldRunner, runner) {
+			res.Changed = append(res.Changed, schemas.RunnerChangeSchema{
+				Name: runner.Name,
+				From: oldRunner,
+				To:   runner,
+			})
+		}
+	}
+	for _, runner := range from {
+		if _, ok := toRunnerNames[runner.Name]; !ok {
+			res.Removed = append(res.Removed, runner.Name)
+		}
+	}
+	sort.Strings(res.Added)
+	sort.Strings(res.Removed)
+	sort.Slice(res.Changed, func(i, j int) bool {
+		return res.Changed[i].Name < res.Changed[j].Name
+	})
+	return res
+}
+
+func diffLabels(from, to modelschemas.LabelItemsSchema) schemas.LabelsDiffSchema {
+	res := schemas.LabelsDiffSchema{
+		Added:   make(modelschemas.LabelItemsSchema, 0),
+		Removed: make(modelschemas.LabelItemsSchema, 0),
+		Changed: make([]schemas.LabelChangeSchema, 0),
+	}
+	fromValues := make(map[string]string, len(from))
+	for _, label := range from {
+		fromValues[label.Key] = label.Value
+	}
+	toKeys := make(map[string]struct{}, len(to))
+	for _, label := range to {
+		toKeys[label.Key] = struct{}{}
+		oldValue, ok := fromValues[label.Key]
+		if !ok {
+			res.Added = append(res.Added, label)
+			continue
+		}
+		if oldValue != label.Value {
+			res.Changed = append(res.Changed, schemas.LabelChangeSchema{
+				Key:  label.Key,
+				From: oldValue,
+				To:   label.Value,
+			})
+		}
+	}
+	for _, label := range from {
+		if _, ok := toKeys[label.Key]; !ok {
+			res.Removed = append(res.Removed, label)
+		}
+	}
+	sort.Slice(res.Added, func(i, j int) bool {
+		return res.Added[i].Key < res.Added[j].Key
+	})
+	sort.Slice(res.Removed, func(i, j int) bool {
+		return res.Removed[i].Key < res.Removed[j].Key
+	})
+	sort.Slice(res.Changed, func(i, j int) bool {
+		return res.Changed[i].Key < res.Changed[j].Key
+	})
+	return res
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+func TestDiffBentoManifests(t *testing.T) {
+	from := &modelschemas.BentoManifestSchema{
+		BentomlVersion: "1.0.0",
+		Apis: map[string]modelschemas.BentoApiSchema{
+			"predict":  {Route: "/predict", Input: "NumpyNdarray", Output: "NumpyNdarray"},
+			"classify": {Route: "/classify", Input: "JSON", Output: "JSON"},
+		},
+		Models:    []string{"iris:v1", "scaler:v1"},
+		Runners:   []modelschemas.BentoRunnerSchema{{Name: "iris", Models: []string{"iris:v1"}}},
+		SizeBytes: 100,
+	}
+	to := &modelschemas.BentoManifestSchema{
+		BentomlVersion: "1.0.5",
+		Apis: map[string]modelschemas.BentoApiSchema{
+			"predict": {Route: "/predict", Input: "JSON", Output: "NumpyNdarray"},
+			"healthz": {Route: "/healthz", Input: "Text", Output: "Text"},
+		},
+		Models:    []string{"iris:v2", "encoder:v1"},
+		Runners:   []modelschemas.BentoRunnerSchema{{Name: "iris", Models: []string{"iris:v2"}}, {Name: "encoder"}},
+		SizeBytes: 80,
+	}
+	diff := DiffBentoManifests(from, to, modelschemas.LabelItemsSchema{
+		{Key: "stage", Value: "dev"},
+		{Key: "owner", Value: "ml"},
+	}, modelschemas.LabelItemsSchema{
+		{Key: "stage", Value: "prod"},
+	})
+
+	if diff.Service != nil {
+		t.Errorf("service should be unchanged, got %+v", diff.Service)
+	}
+	if !reflect.DeepEqual(diff.BentomlVersion, &schemas.StringChangeSchema{From: "1.0.0", To: "1.0.5"}) {
+		t.Errorf("unexpected bentoml version change %+v", diff.BentomlVersion)
+	}
+	if diff.Size == nil || diff.Size.Delta != -20 {
+		t.Errorf("unexpected size change %+v", diff.Size)
+	}
+	expectedModels := schemas.ModelsDiffSchema{
+		Added:   []string{"encoder:v1"},
+		Removed: []string{"scaler:v1"},
+		Changed: []schemas.ModelChangeSchema{{Name: "iris", FromVersions: []string{"v1"}, ToVersions: []string{"v2"}}},
+	}
+	if !reflect.DeepEqual(diff.Models, expectedModels) {
+		t.Errorf("unexpected models diff %+v", diff.Models)
+	}
+	if !reflect.DeepEqual(diff.Apis.Added, []string{"healthz"}) || !reflect.DeepEqual(diff.Apis.Removed, []string{"classify"}) {
+		t.Errorf("unexpected apis diff %+v", diff.Apis)
+	}
+	if len(diff.Apis.Changed) != 1 || diff.Apis.Changed[0].Name != "predict" {
+		t.Errorf("unexpected changed apis %+v", diff.Apis.Changed)
+	}
+	if !reflect.DeepEqual(diff.Runners.Added, []string{"encoder"}) || len(diff.Runners.Changed) != 1 {
+		t.Errorf("unexpected runners diff %+v", diff.Runners)
+	}
+	if len(diff.Labels.Removed) != 1 || diff.Labels.Removed[0].Key != "owner" {
+		t.Errorf("unexpected removed labels %+v", diff.Labels.Removed)
+	}
+	if !reflect.DeepEqual(diff.Labels.Changed, []schemas.LabelChangeSchema{{Key: "stage", From: "dev", To: "prod"}}) {
+		t.Errorf("unexpected changed labels %+v", diff.Labels.Changed)
+	}
+
+	if !DiffBentoManifests(from, from, nil, nil).IsEmpty() {
+		t.Error("diff of a manifest with itself should be empty")
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/common/consts"
 )
 
@@ -28,20 +29,22 @@ type CreateBentoRepositoryOption struct {
 }
 
 type UpdateBentoRepositoryOption struct {
-	Description *string
-	Labels      *modelschemas.LabelItemsSchema
+	Description     *string
+	Labels          *modelschemas.LabelItemsSchema
+	RetentionPolicy **schemas.RetentionPolicySchema
 }
 
 type ListBentoRepositoryOption struct {
 	BaseListOption
 	BaseListByLabelsOption
-	OrganizationId *uint
-	CreatorId      *uint
-	CreatorIds     *[]uint
-	LastUpdaterIds *[]uint
-	Order          *string
-	Names          *[]string
-	Ids            *[]uint
+	OrganizationId     *uint
+	CreatorId          *uint
+	CreatorIds         *[]uint
+	LastUpdaterIds     *[]uint
+	Order              *string
+	Names              *[]string
+	Ids                *[]uint
+	HasRetentionPolicy *bool
 }
 
 func (*bentoRepositoryService) Create(ctx context.Context, opt CreateBentoRepositoryOption) (*models.BentoRepository, error) {
@@ -75,6 +78,14 @@ func (s *bentoRepositoryService) Update(ctx context.Context, bentoRepository *mo
 			}
 		}()
 	}
+	if opt.RetentionPolicy != nil {
+		updaters["retention_policy"] = *opt.RetentionPolicy
+		defer func() {
+			if err == nil {
+				bentoRepository.RetentionPolicy = *opt.RetentionPolicy
+			}
+		}()
+	}
 
 	if len(updaters) == 0 {
 		return bentoRepository, nil
@@ -156,6 +167,13 @@ func (s *bentoRepositoryService) List(ctx context.Context, opt ListBentoReposito
 	if opt.CreatorIds != nil {
 		query = query.Where("bento_repository.creator_id in (?)", *opt.CreatorIds)
 	}
+	if opt.HasRetentionPolicy != nil {
+		if *opt.HasRetentionPolicy {
+			query = query.Where("bento_repository.retention_policy IS NOT NULL")
+		} else {
+			query = query.Where("bento_repository.retention_policy IS NULL")
+		}
+	}
 	query = query.Joins("LEFT JOIN bento ON bento.bento_repository_id = bento_repository.id")
 	query = query.Joins("LEFT OUTER JOIN bento b2 ON b2.bento_repository_id = bento_repository.id AND bento.id < b2.id")
 	query = query.Where("b2.id IS NULL")
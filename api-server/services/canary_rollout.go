@@ -0,0 +1,711 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	commonconsts "github.com/bentoml/yatai-common/consts"
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type canaryRolloutService struct{}
+
+var CanaryRolloutService = canaryRolloutService{}
+
+func (s *canaryRolloutService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.CanaryRollout{})
+}
+
+type CreateCanaryRolloutOption struct {
+	CreatorId    uint
+	ApiTokenName string
+	Deployment   *models.Deployment
+	Steps        schemas.CanaryRolloutStepsSchema
+	Analysis     schemas.CanaryRolloutAnalysisSchema
+}
+
+type UpdateCanaryRolloutOption struct {
+	Status                  *schemas.CanaryRolloutStatus
+	CurrentStep             *uint
+	StepStartedAt           *time.Time
+	StepBaselinePodRestarts *uint
+	Reason                  *string
+	FinishedAt              **time.Time
+}
+
+type ListCanaryRolloutOption struct {
+	BaseListOption
+	DeploymentId *uint
+	Status       *schemas.CanaryRolloutStatus
+}
+
+// FinishCanaryRolloutOption tells who promotes or aborts a rollout, the creator of the rollout is used by the controller loop
+type FinishCanaryRolloutOption struct {
+	CreatorId    uint
+	ApiTokenName string
+	Reason       string
+}
+
+// Create starts stepping the weight of the canary target of the active revision, the first step is applied right away
+func (s *canaryRolloutService) Create(ctx context.Context, opt CreateCanaryRolloutOption) (rollout *models.CanaryRollout, err error) {
+	if err = opt.Steps.Validate(); err != nil {
+		return
+	}
+	if err = opt.Analysis.Validate(); err != nil {
+		return
+	}
+	if opt.Analysis.PrometheusQuery != "" && PrometheusService.GetEndpoint() == "" {
+		err = errors.New("prometheus_query is set but the prometheus endpoint is not configured")
+		return
+	}
+
+	running, err := s.GetRunningByDeployment(ctx, opt.Deployment)
+	isNotFound := utils.IsNotFound(err)
+	if err != nil && !isNotFound {
+		return
+	}
+	if !isNotFound {
+		err = errors.Errorf("canary rollout %s of deployment %s is still running", running.Uid, opt.Deployment.Name)
+		return
+	}
+
+	deploymentRevision, deploymentTarget, err := s.getActiveCanaryTarget(ctx, opt.Deployment)
+	if err != nil {
+		return
+	}
+	pods, err := s.listCanaryPods(ctx, opt.Deployment)
+	if err != nil {
+		err = errors.Wrap(err, "list canary pods")
+		return
+	}
+	// the BentoDeployment of yatai-deployment runs a single bento per deployment, the canary target only gets pods
+	// once it is deployed by its own workload, without them there is no health signal to step the weight on
+	if len(pods) == 0 {
+		err = errors.Errorf("the canary target of deployment %s has no pods, a canary rollout needs the canary to be running", opt.Deployment.Name)
+		return
+	}
+
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	rollout = &models.CanaryRollout{
+		CreatorAssociate: models.CreatorAssociate{
+			CreatorId: opt.CreatorId,
+		},
+		DeploymentAssociate: models.DeploymentAssociate{
+			DeploymentId: opt.Deployment.ID,
+		},
+		DeploymentRevisionAssociate: models.DeploymentRevisionAssociate{
+			DeploymentRevisionId: deploymentRevision.ID,
+		},
+		DeploymentTargetId:      deploymentTarget.ID,
+		Status:                  schemas.CanaryRolloutStatusRunning,
+		Steps:                   opt.Steps,
+		Analysis:                opt.Analysis,
+		StepStartedAt:           time.Now(),
+		StepBaselinePodRestarts: s.countPodRestarts(pods),
+	}
+	err = db.Create(rollout).Error
+	if err != nil {
+		return
+	}
+
+	err = s.applyWeight(ctx, deploymentRevision, deploymentTarget, rollout.GetCurrentWeight())
+	if err != nil {
+		err = errors.Wrap(err, "apply canary weight")
+		return
+	}
+
+	err = s.createEvent(ctx, rollout, opt.CreatorId, opt.ApiTokenName, modelschemas.EventStatusSuccess, fmt.Sprintf("canary rollout started at weight %d%%", rollout.GetCurrentWeight()))
+	return
+}
+
+func (s *canaryRolloutService) Update(ctx context.Context, rollout *models.CanaryRollout, opt UpdateCanaryRolloutOption) (*models.CanaryRollout, error) {
+	var err error
+	updaters := make(map[string]interface{})
+
+	if opt.Status != nil {
+		updaters["status"] = *opt.Status
+		defer func() {
+			if err == nil {
+				rollout.Status = *opt.Status
+			}
+		}()
+	}
+	if opt.CurrentStep != nil {
+		updaters["current_step"] = *opt.CurrentStep
+		defer func() {
+			if err == nil {
+				rollout.CurrentStep = *opt.CurrentStep
+			}
+		}()
+	}
+	if opt.StepStartedAt != nil {
+		updaters["step_started_at"] = *opt.StepStartedAt
+		defer func() {
+			if err == nil {
+				rollout.StepStartedAt = *opt.StepStartedAt
+			}
+		}()
+	}
+	if opt.StepBaselinePodRestarts != nil {
+		updaters["step_baseline_pod_restarts"] = *opt.StepBaselinePodRestarts
+		defer func() {
+			if err == nil {
+				rollout.StepBaselinePodRestarts = *opt.StepBaselinePodRestarts
+			}
+		}()
+	}
+	if opt.Reason != nil {
+		updaters["reason"] = *opt.Reason
+		defer func() {
+			if err == nil {
+				rollout.Reason = *opt.Reason
+			}
+		}()
+	}
+	if opt.FinishedAt != nil {
+		updaters["finished_at"] = *opt.FinishedAt
+		defer func() {
+			if err == nil {
+				rollout.FinishedAt = *opt.FinishedAt
+			}
+		}()
+	}
+
+	if len(updaters) == 0 {
+		return rollout, nil
+	}
+
+	err = s.getBaseDB(ctx).Where("id = ?", rollout.ID).Updates(updaters).Error
+
+	return rollout, err
+}
+
+func (s *canaryRolloutService) Get(ctx context.Context, id uint) (*models.CanaryRollout, error) {
+	var rollout models.CanaryRollout
+	err := getBaseQuery(ctx, s).Where("id = ?", id).First(&rollout).Error
+	if err != nil {
+		return nil, err
+	}
+	if rollout.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &rollout, nil
+}
+
+func (s *canaryRolloutService) GetByUid(ctx context.Context, uid string) (*models.CanaryRollout, error) {
+	var rollout models.CanaryRollout
+	err := getBaseQuery(ctx, s).Where("uid = ?", uid).First(&rollout).Error
+	if err != nil {
+		return nil, err
+	}
+	if rollout.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &rollout, nil
+}
+
+func (s *canaryRolloutService) GetRunningByDeployment(ctx context.Context, deployment *models.Deployment) (*models.CanaryRollout, error) {
+	var rollout models.CanaryRollout
+	err := getBaseQuery(ctx, s).
+		Where("deployment_id = ?", deployment.ID).
+		Where("status = ?", schemas.CanaryRolloutStatusRunning).
+		First(&rollout).Error
+	if err != nil {
+		return nil, err
+	}
+	if rollout.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &rollout, nil
+}
+
+func (s *canaryRolloutService) List(ctx context.Context, opt ListCanaryRolloutOption) ([]*models.CanaryRollout, uint, error) {
+	query := getBaseQuery(ctx, s)
+	if opt.DeploymentId != nil {
+		query = query.Where("deployment_id = ?", *opt.DeploymentId)
+	}
+	if opt.Status != nil {
+		query = query.Where("status = ?", *opt.Status)
+	}
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	rollouts := make([]*models.CanaryRollout, 0)
+	query = opt.BindQueryWithLimit(query)
+	err = query.Order("id DESC").Find(&rollouts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return rollouts, uint(total), err
+}
+
+// Promote replaces the active revision with a revision whose only target is the canary promoted to stable,
+// on a protected cluster the new revision is requested in a change request waiting for the approval of another admin
+func (s *canaryRolloutService) Promote(ctx context.Context, rollout *models.CanaryRollout, opt FinishCanaryRolloutOption) (err error) {
+	if rollout.Status != schemas.CanaryRolloutStatusRunning {
+		return errors.Errorf("canary rollout %s is already %s", rollout.Uid, rollout.Status)
+	}
+	deploymentTarget, err := DeploymentTargetService.Get(ctx, rollout.DeploymentTargetId)
+	if err != nil {
+		return errors.Wrap(err, "get canary deployment target")
+	}
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, rollout)
+	if err != nil {
+		return errors.Wrap(err, "get deployment")
+	}
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return errors.Wrap(err, "get associated cluster")
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	err = s.finish(ctx, rollout, schemas.CanaryRolloutStatusPromoted, opt.Reason)
+	if err != nil {
+		return
+	}
+
+	if ClusterService.IsProtected(cluster) {
+		targets, err_ := DeploymentTargetService.ToCreateDeploymentTargetSchemas(ctx, []*models.DeploymentTarget{deploymentTarget})
+		if err_ != nil {
+			err = err_
+			return
+		}
+		targets[0].Type = modelschemas.DeploymentTargetTypeStable
+		targets[0].CanaryRules = nil
+		request, err_ := DeploymentChangeRequestService.Create(ctx, CreateDeploymentChangeRequestOption{
+			CreatorId:    opt.CreatorId,
+			ApiTokenName: opt.ApiTokenName,
+			Deployment:   deployment,
+			Kind:         schemas.DeploymentChangeRequestKindDeploy,
+			Targets:      targets,
+		})
+		if err_ != nil {
+			err = errors.Wrap(err_, "create deployment change request")
+			return
+		}
+		return s.createEvent(ctx, rollout, opt.CreatorId, opt.ApiTokenName, modelschemas.EventStatusSuccess, fmt.Sprintf("canary rollout promoted to stable, waiting for the approval of change request %s", request.Uid))
+	}
+
+	deploymentRevision, err := DeploymentRevisionService.Create(ctx, CreateDeploymentRevisionOption{
+		CreatorId:    opt.CreatorId,
+		DeploymentId: rollout.DeploymentId,
+		Status:       modelschemas.DeploymentRevisionStatusActive,
+	})
+	if err != nil {
+		return errors.Wrap(err, "create deployment revision")
+	}
+	var config *modelschemas.DeploymentTargetConfig
+	if deploymentTarget.Config != nil {
+		config_ := *deploymentTarget.Config
+		// the kube resource belongs to the revision being replaced
+		config_.KubeResourceUid = ""
+		config_.KubeResourceVersion = ""
+		config = &config_
+	}
+	stableDeploymentTarget, err := DeploymentTargetService.Create(ctx, CreateDeploymentTargetOption{
+		CreatorId:            opt.CreatorId,
+		DeploymentId:         rollout.DeploymentId,
+		DeploymentRevisionId: deploymentRevision.ID,
+		BentoId:              deploymentTarget.BentoId,
+		Type:                 modelschemas.DeploymentTargetTypeStable,
+		Config:               config,
+	})
+	if err != nil {
+		return errors.Wrap(err, "create deployment target")
+	}
+	err = DeploymentRevisionService.Deploy(ctx, deploymentRevision, []*models.DeploymentTarget{stableDeploymentTarget}, false)
+	if err != nil {
+		return errors.Wrap(err, "deploy deployment revision")
+	}
+
+	return s.createEvent(ctx, rollout, opt.CreatorId, opt.ApiTokenName, modelschemas.EventStatusSuccess, fmt.Sprintf("canary rollout promoted to stable in revision %s", deploymentRevision.Uid))
+}
+
+// Abort routes all the traffic back to the stable target, the canary target is left in place for inspection
+func (s *canaryRolloutService) Abort(ctx context.Context, rollout *models.CanaryRollout, opt FinishCanaryRolloutOption) (err error) {
+	if rollout.Status != schemas.CanaryRolloutStatusRunning {
+		return errors.Errorf("canary rollout %s is already %s", rollout.Uid, rollout.Status)
+	}
+	deploymentRevision, err := DeploymentRevisionService.GetAssociatedDeploymentRevision(ctx, rollout)
+	if err != nil {
+		return errors.Wrap(err, "get deployment revision")
+	}
+	deploymentTarget, err := DeploymentTargetService.Get(ctx, rollout.DeploymentTargetId)
+	if err != nil {
+		return errors.Wrap(err, "get canary deployment target")
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	weight := rollout.GetCurrentWeight()
+	err = s.finish(ctx, rollout, schemas.CanaryRolloutStatusAborted, opt.Reason)
+	if err != nil {
+		return
+	}
+
+	// a replaced revision has no traffic left to take back
+	if deploymentRevision.Status == modelschemas.DeploymentRevisionStatusActive {
+		err = s.applyWeight(ctx, deploymentRevision, deploymentTarget, 0)
+		if err != nil {
+			return errors.Wrap(err, "apply canary weight")
+		}
+	}
+	return s.createEvent(ctx, rollout, opt.CreatorId, opt.ApiTokenName, modelschemas.EventStatusFailed, fmt.Sprintf("canary rollout aborted at weight %d%%", weight))
+}
+
+// Progress checks the health signals of the canary, it aborts the rollout as soon as one of them is unhealthy,
+// otherwise it moves to the next step or promotes the canary once the current step has lasted long enough
+func (s *canaryRolloutService) Progress(ctx context.Context, rollout *models.CanaryRollout) (err error) {
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, rollout)
+	if err != nil {
+		return errors.Wrap(err, "get deployment")
+	}
+	deploymentRevision, err := DeploymentRevisionService.GetAssociatedDeploymentRevision(ctx, rollout)
+	if err != nil {
+		return errors.Wrap(err, "get deployment revision")
+	}
+	finishOpt := FinishCanaryRolloutOption{
+		CreatorId: rollout.CreatorId,
+	}
+	if deploymentRevision.Status != modelschemas.DeploymentRevisionStatusActive {
+		finishOpt.Reason = fmt.Sprintf("deployment revision %s was replaced", deploymentRevision.Uid)
+		return s.Abort(ctx, rollout, finishOpt)
+	}
+
+	pods, err := s.listCanaryPods(ctx, deployment)
+	if err != nil {
+		return errors.Wrap(err, "list canary pods")
+	}
+	// the canary is never promoted without a signal
+	if len(pods) == 0 {
+		finishOpt.Reason = "the canary target has no pods left to check"
+		return s.Abort(ctx, rollout, finishOpt)
+	}
+	reason, err := s.checkHealth(ctx, rollout, deployment, pods)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		finishOpt.Reason = reason
+		return s.Abort(ctx, rollout, finishOpt)
+	}
+
+	step := rollout.Steps[rollout.CurrentStep]
+	if time.Since(rollout.StepStartedAt) < time.Duration(step.DurationSeconds)*time.Second {
+		return nil
+	}
+	if int(rollout.CurrentStep)+1 >= len(rollout.Steps) {
+		finishOpt.Reason = "every step was healthy"
+		return s.Promote(ctx, rollout, finishOpt)
+	}
+
+	deploymentTarget, err := DeploymentTargetService.Get(ctx, rollout.DeploymentTargetId)
+	if err != nil {
+		return errors.Wrap(err, "get canary deployment target")
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	nextStep := rollout.CurrentStep + 1
+	now := time.Now()
+	restarts := s.countPodRestarts(pods)
+	// the step only moves forward if an overlapping run of the controller loop did not move it already
+	result := s.getBaseDB(ctx).
+		Where("id = ?", rollout.ID).
+		Where("status = ?", schemas.CanaryRolloutStatusRunning).
+		Where("current_step = ?", rollout.CurrentStep).
+		Updates(map[string]interface{}{
+			"current_step":               nextStep,
+			"step_started_at":            now,
+			"step_baseline_pod_restarts": restarts,
+		})
+	if result.Error != nil {
+		err = result.Error
+		return
+	}
+	if result.RowsAffected == 0 {
+		err = errors.Errorf("canary rollout %s was moved forward meanwhile", rollout.Uid)
+		return
+	}
+	rollout.CurrentStep = nextStep
+	rollout.StepStartedAt = now
+	rollout.StepBaselinePodRestarts = restarts
+	err = s.applyWeight(ctx, deploymentRevision, deploymentTarget, rollout.GetCurrentWeight())
+	if err != nil {
+		err = errors.Wrap(err, "apply canary weight")
+		return
+	}
+	err = s.createEvent(ctx, rollout, rollout.CreatorId, "", modelschemas.EventStatusSuccess, fmt.Sprintf("canary rollout step %d/%d: weight set to %d%%", nextStep+1, len(rollout.Steps), rollout.GetCurrentWeight()))
+	return
+}
+
+// ProgressRunningRollouts is run periodically to move every running rollout forward
+func (s *canaryRolloutService) ProgressRunningRollouts(ctx context.Context) error {
+	logger := logrus.WithField("cron", "canary rollout")
+
+	status := schemas.CanaryRolloutStatusRunning
+	rollouts, _, err := s.List(ctx, ListCanaryRolloutOption{
+		Status: &status,
+	})
+	if err != nil {
+		return errors.Wrap(err, "list running canary rollouts")
+	}
+	for _, rollout := range rollouts {
+		if err = s.Progress(ctx, rollout); err != nil {
+			logger.Errorf("progress canary rollout %s: %s", rollout.Uid, err.Error())
+		}
+	}
+	return nil
+}
+
+// checkHealth returns why the canary is unhealthy, an empty reason means every signal is within its threshold
+func (s *canaryRolloutService) checkHealth(ctx context.Context, rollout *models.CanaryRollout, deployment *models.Deployment, pods []apiv1.Pod) (string, error) {
+	restarts := s.countPodRestarts(pods)
+	if restarts > rollout.StepBaselinePodRestarts && restarts-rollout.StepBaselinePodRestarts > rollout.Analysis.MaxPodRestarts {
+		return fmt.Sprintf("the canary pods restarted %d times during step %d, more than the %d allowed", restarts-rollout.StepBaselinePodRestarts, rollout.CurrentStep+1, rollout.Analysis.MaxPodRestarts), nil
+	}
+
+	events, err := KubeEventService.ListAllKubeEventsByDeployment(ctx, deployment)
+	if err != nil {
+		return "", errors.Wrap(err, "list kube events")
+	}
+	var warnings uint
+	for _, event := range KubeEventService.FilterWarningKubeEvents(KubeEventService.filterKubeEventsByPodsUID(events, pods)) {
+		lastTime := event.LastTimestamp.Time
+		if lastTime.IsZero() {
+			lastTime = event.EventTime.Time
+		}
+		if lastTime.Before(rollout.StepStartedAt) {
+			continue
+		}
+		if event.Count > 1 {
+			warnings += uint(event.Count)
+		} else {
+			warnings++
+		}
+	}
+	if warnings > rollout.Analysis.MaxWarningEvents {
+		return fmt.Sprintf("the canary pods got %d warning events during step %d, more than the %d allowed", warnings, rollout.CurrentStep+1, rollout.Analysis.MaxWarningEvents), nil
+	}
+
+	if rollout.Analysis.PrometheusQuery != "" && rollout.Analysis.PrometheusMaxValue != nil {
+		// a failed query keeps the rollout at the current step instead of aborting it, the canary is never promoted without the signal
+		value, err := PrometheusService.QueryMaxValue(ctx, rollout.Analysis.PrometheusQuery)
+		if err != nil {
+			return "", errors.Wrap(err, "query prometheus")
+		}
+		if value != nil && *value > *rollout.Analysis.PrometheusMaxValue {
+			return fmt.Sprintf("the prometheus query returned %g during step %d, more than the %g allowed", *value, rollout.CurrentStep+1, *rollout.Analysis.PrometheusMaxValue), nil
+		}
+	}
+	return "", nil
+}
+
+func (s *canaryRolloutService) getActiveCanaryTarget(ctx context.Context, deployment *models.Deployment) (*models.DeploymentRevision, *models.DeploymentTarget, error) {
+	status := modelschemas.DeploymentRevisionStatusActive
+	deploymentRevisions, _, err := DeploymentRevisionService.List(ctx, ListDeploymentRevisionOption{
+		DeploymentId: utils.UintPtr(deployment.ID),
+		Status:       &status,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "list active deployment revisions")
+	}
+	if len(deploymentRevisions) == 0 {
+		return nil, nil, errors.Errorf("deployment %s has no active revision", deployment.Name)
+	}
+	deploymentRevision := deploymentRevisions[0]
+
+	targetType := modelschemas.DeploymentTargetTypeCanary
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		DeploymentRevisionId: utils.UintPtr(deploymentRevision.ID),
+		Type:                 &targetType,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "list canary deployment targets")
+	}
+	if len(deploymentTargets) == 0 {
+		return nil, nil, errors.Errorf("the active revision of deployment %s has no canary target", deployment.Name)
+	}
+	if len(deploymentTargets) > 1 {
+		return nil, nil, errors.Errorf("the active revision of deployment %s has %d canary targets, a rollout steps a single canary", deployment.Name, len(deploymentTargets))
+	}
+	return deploymentRevision, deploymentTargets[0], nil
+}
+
+func (s *canaryRolloutService) listCanaryPods(ctx context.Context, deployment *models.Deployment) ([]apiv1.Pod, error) {
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated cluster")
+	}
+	_, podLister, err := GetPodInformer(ctx, cluster, DeploymentService.GetKubeNamespace(deployment))
+	if err != nil {
+		return nil, err
+	}
+	selector, err := labels.Parse(fmt.Sprintf("%s = %s, %s = %s", commonconsts.KubeLabelYataiBentoDeployment, deployment.Name, commonconsts.KubeLabelYataiBentoDeploymentTargetType, modelschemas.DeploymentTargetTypeCanary))
+	if err != nil {
+		return nil, err
+	}
+	pods_, err := podLister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]apiv1.Pod, 0, len(pods_))
+	for _, p := range pods_ {
+		pods = append(pods, *p)
+	}
+	return pods, nil
+}
+
+func (s *canaryRolloutService) countPodRestarts(pods []apiv1.Pod) uint {
+	var restarts uint
+	for _, pod := range pods {
+		restarts += uint(KubePodService.GetKubePodRestartCount(pod))
+	}
+	return restarts
+}
+
+// applyWeight saves the weight in the canary rules of the target and applies it to the canary ingress of the target,
+// the ingress is owned by the revision so it is garbage collected once the revision is replaced.
+// The ingress routes to the kubernetes service of the canary target, no traffic is sent to it while the service does not exist
+func (s *canaryRolloutService) applyWeight(ctx context.Context, deploymentRevision *models.DeploymentRevision, deploymentTarget *models.DeploymentTarget, weight uint) error {
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentTarget)
+	if err != nil {
+		return errors.Wrap(err, "get deployment")
+	}
+	kubeName, err := DeploymentTargetService.GetKubeName(ctx, deploymentTarget)
+	if err != nil {
+		return errors.Wrap(err, "get kube name")
+	}
+	servicesCli, err := DeploymentService.GetKubeServicesCli(ctx, deployment)
+	if err != nil {
+		return errors.Wrap(err, "get kube services cli")
+	}
+	_, err = servicesCli.Get(ctx, kubeName, metav1.GetOptions{})
+	backendNotFound := apierrors.IsNotFound(err)
+	if err != nil && !backendNotFound {
+		return errors.Wrapf(err, "get k8s service %s", kubeName)
+	}
+	if backendNotFound && weight > 0 {
+		return errors.Errorf("the k8s service %s of the canary target does not exist, the traffic cannot be routed to it", kubeName)
+	}
+
+	rules := make(modelschemas.DeploymentTargetCanaryRules, 0)
+	if deploymentTarget.CanaryRules != nil {
+		for _, rule := range *deploymentTarget.CanaryRules {
+			if rule.Type == modelschemas.DeploymentTargetCanaryRuleTypeWeight {
+				continue
+			}
+			rules = append(rules, rule)
+		}
+	}
+	rules = append(rules, &modelschemas.DeploymentTargetCanaryRule{
+		Type:   modelschemas.DeploymentTargetCanaryRuleTypeWeight,
+		Weight: &weight,
+	})
+	rulesPtr := &rules
+	_, err = DeploymentTargetService.Update(ctx, deploymentTarget, UpdateDeploymentTargetOption{
+		CanaryRules: &rulesPtr,
+	})
+	if err != nil {
+		return errors.Wrap(err, "update canary rules")
+	}
+	if backendNotFound {
+		return nil
+	}
+
+	ownerReferences, err := DeploymentRevisionService.MakeSureKubeOwnerReferences(ctx, deploymentRevision)
+	if err != nil {
+		return errors.Wrap(err, "make sure kube owner references")
+	}
+	deployOption, err := DeploymentRevisionService.GetDeployOption(ctx, deploymentRevision, false)
+	if err != nil {
+		return err
+	}
+	deployOption.OwnerReferences = ownerReferences
+	return KubeIngressService.DeployDeploymentTargetAsKubeIngresses(ctx, deploymentTarget, deployOption)
+}
+
+// finish only moves a running rollout, the row is locked by the update so a rollout promoted by an overlapping run
+// of the controller loop or by a user meanwhile is not finished twice
+func (s *canaryRolloutService) finish(ctx context.Context, rollout *models.CanaryRollout, status schemas.CanaryRolloutStatus, reason string) error {
+	now := time.Now()
+	result := s.getBaseDB(ctx).
+		Where("id = ?", rollout.ID).
+		Where("status = ?", schemas.CanaryRolloutStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reason":      reason,
+			"finished_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.Errorf("canary rollout %s is not running anymore", rollout.Uid)
+	}
+	rollout.Status = status
+	rollout.Reason = reason
+	rollout.FinishedAt = &now
+	return nil
+}
+
+func (s *canaryRolloutService) createEvent(ctx context.Context, rollout *models.CanaryRollout, creatorId uint, apiTokenName string, status modelschemas.EventStatus, operationName string) error {
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, rollout)
+	if err != nil {
+		return errors.Wrap(err, "get deployment")
+	}
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return errors.Wrap(err, "get associated cluster")
+	}
+	_, err = EventService.Create(ctx, CreateEventOption{
+		CreatorId:      creatorId,
+		ApiTokenName:   apiTokenName,
+		OrganizationId: &cluster.OrganizationId,
+		ResourceType:   modelschemas.ResourceTypeDeployment,
+		ResourceId:     deployment.ID,
+		Status:         status,
+		OperationName:  operationName,
+	})
+	if err != nil {
+		return errors.Wrap(err, "create event")
+	}
+	return nil
+}
@@ -26,6 +26,7 @@ import (
 	commonconsts "github.com/bentoml/yatai-common/consts"
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/common/consts"
 	"github.com/bentoml/yatai/common/helmchart"
 	"github.com/bentoml/yatai/common/utils"
@@ -52,12 +53,12 @@ type CreateClusterOption struct {
 	Name           string
 	Description    string
 	KubeConfig     string
-	Config         *modelschemas.ClusterConfigSchema
+	Config         *schemas.ClusterConfigSchema
 }
 
 type UpdateClusterOption struct {
 	Description *string
-	Config      **modelschemas.ClusterConfigSchema
+	Config      **schemas.ClusterConfigSchema
 	KubeConfig  *string
 }
 
@@ -265,6 +266,11 @@ func (s *clusterService) GetDeploymentKubeNamespace(c *models.Cluster) string {
 	return kubeNamespace
 }
 
+// IsProtected tells whether the deployment changes on the cluster wait for the approval of another admin of the cluster
+func (s *clusterService) IsProtected(c *models.Cluster) bool {
+	return c.Config != nil && c.Config.Protected
+}
+
 func (s *clusterService) GetDefault(ctx context.Context, orgId uint) (defaultCluster *models.Cluster, err error) {
 	clusters, total, err := s.List(ctx, ListClusterOption{
 		BaseListOption: BaseListOption{
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type copyService struct{}
+
+var CopyService = copyService{}
+
+type CopyBentoOption struct {
+	CreatorId                 uint
+	ApiTokenName              string
+	TargetOrganizationId      uint
+	TargetBentoRepositoryName string
+}
+
+type CopyModelOption struct {
+	CreatorId                 uint
+	ApiTokenName              string
+	TargetOrganizationId      uint
+	TargetModelRepositoryName string
+}
+
+func (s *copyService) createCopyEvents(ctx context.Context, creatorId uint, apiTokenName string, srcOrg *models.Organization, src models.IResource, targetOrg *models.Organization, target models.IResource) error {
+	_, err := EventService.Create(ctx, CreateEventOption{
+		CreatorId:      creatorId,
+		ApiTokenName:   apiTokenName,
+		OrganizationId: &srcOrg.ID,
+		ResourceType:   src.GetResourceType(),
+		ResourceId:     src.GetId(),
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "copied out",
+	})
+	if err != nil {
+		return errors.Wrap(err, "create source event")
+	}
+	_, err = EventService.Create(ctx, CreateEventOption{
+		CreatorId:      creatorId,
+		ApiTokenName:   apiTokenName,
+		OrganizationId: &targetOrg.ID,
+		ResourceType:   target.GetResourceType(),
+		ResourceId:     target.GetId(),
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "copied in",
+	})
+	if err != nil {
+		return errors.Wrap(err, "create target event")
+	}
+	return nil
+}
+
+func (s *copyService) getOrCreateBentoRepository(ctx context.Context, creatorId, organizationId uint, name string) (*models.BentoRepository, error) {
+	bentoRepository, err := BentoRepositoryService.GetByName(ctx, organizationId, name)
+	if err == nil {
+		return bentoRepository, nil
+	}
+	if !utils.IsNotFound(err) {
+		return nil, err
+	}
+	return BentoRepositoryService.Create(ctx, CreateBentoRepositoryOption{
+		CreatorId:      creatorId,
+		OrganizationId: organizationId,
+		Name:           name,
+	})
+}
+
+func (s *copyService) getOrCreateModelRepository(ctx context.Context, creatorId, organizationId uint, name string) (*models.ModelRepository, error) {
+	modelRepository, err := ModelRepositoryService.GetByName(ctx, organizationId, name)
+	if err == nil {
+		return modelRepository, nil
+	}
+	if !utils.IsNotFound(err) {
+		return nil, err
+	}
+	return ModelRepositoryService.Create(ctx, CreateModelRepositoryOption{
+		CreatorId:      creatorId,
+		OrganizationId: organizationId,
+		Name:           name,
+	})
+}
+
+func (s *copyService) CopyBento(ctx context.Context, bento *models.Bento, opt CopyBentoOption) (newBento *models.Bento, err error) {
+	if bento.UploadStatus != modelschemas.BentoUploadStatusSuccess {
+		err = errors.Errorf("bento %s has not been uploaded successfully, cannot copy it", bento.Version)
+		return
+	}
+	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+	if err != nil {
+		return
+	}
+	srcOrg, err := OrganizationService.GetAssociatedOrganization(ctx, bentoRepository)
+	if err != nil {
+		return
+	}
+	targetOrg, err := OrganizationService.Get(ctx, opt.TargetOrganizationId)
+	if err != nil {
+		err = errors.Wrap(err, "get target organization")
+		return
+	}
+	targetBentoRepositoryName := opt.TargetBentoRepositoryName
+	if targetBentoRepositoryName == "" {
+		targetBentoRepositoryName = bentoRepository.Name
+	}
+	if targetOrg.ID == srcOrg.ID && targetBentoRepositoryName == bentoRepository.Name {
+		err = errors.Errorf("cannot copy bento %s:%s onto itself", bentoRepository.Name, bento.Version)
+		return
+	}
+
+	// the target is checked before the archive is copied, the archive of an existing bento must not be replaced
+	if err = s.checkBentoNotExists(ctx, targetOrg, targetBentoRepositoryName, bento.Version); err != nil {
+		return
+	}
+
+	// the models have to exist in the target organization before the bento is created, otherwise the bento model relations are not built,
+	// every model is copied and committed on its own
+	models_, err := BentoService.ListModelsFromManifests(ctx, bento)
+	if err != nil {
+		err = errors.Wrap(err, "list models from manifests")
+		return
+	}
+	for _, model := range models_ {
+		_, err = s.copyReferencedModel(ctx, model, opt.CreatorId, opt.ApiTokenName, targetOrg)
+		if err != nil {
+			return
+		}
+	}
+
+	srcDriver, err := BentoService.getStorageDriver(ctx, bento)
+	if err != nil {
+		return
+	}
+	srcObjectName, err := BentoService.getObjectName(ctx, bento)
+	if err != nil {
+		return
+	}
+	targetDriver, err := OrganizationService.GetStorageDriver(ctx, targetOrg, StorageBucketTypeBentos)
+	if err != nil {
+		return
+	}
+	targetObjectName := BentoService.formatObjectName(targetOrg, targetBentoRepositoryName, bento.Version)
+	// the archive is copied before the transaction is started, a large archive would otherwise hold the transaction open for the whole copy
+	err = CopyStorageObject(ctx, srcDriver, srcObjectName, targetDriver, targetObjectName)
+	if err != nil {
+		err = errors.Wrap(err, "copy bento object")
+		return
+	}
+	targetExists := false
+	defer func() {
+		if err != nil && !targetExists {
+			s.deleteCopiedObject(ctx, targetDriver, targetObjectName)
+		}
+	}()
+
+	labels, err := LabelService.ListLabelItemsByResource(ctx, srcOrg.ID, bento)
+	if err != nil {
+		return
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	targetBentoRepository, err := s.getOrCreateBentoRepository(ctx, opt.CreatorId, targetOrg.ID, targetBentoRepositoryName)
+	if err != nil {
+		err = errors.Wrap(err, "get or create target bento repository")
+		return
+	}
+	_, err = BentoService.GetByVersion(ctx, targetBentoRepository.ID, bento.Version)
+	if err == nil {
+		targetExists = true
+		err = errors.Errorf("bento %s:%s already exists in organization %s", targetBentoRepositoryName, bento.Version, targetOrg.Name)
+		return
+	}
+	if !utils.IsNotFound(err) {
+		return
+	}
+
+	newBento, err = BentoService.Create(ctx, CreateBentoOption{
+		CreatorId:         opt.CreatorId,
+		BentoRepositoryId: targetBentoRepository.ID,
+		Version:           bento.Version,
+		Description:       bento.Description,
+		BuildAt:           bento.BuildAt,
+		Manifest:          bento.Manifest,
+		Labels:            labels,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "create target bento")
+		return
+	}
+
+	uploadStatus := modelschemas.BentoUploadStatusSuccess
+	now := time.Now()
+	nowPtr := &now
+	newBento, err = BentoService.Update(ctx, newBento, UpdateBentoOption{
+		UploadStatus:     &uploadStatus,
+		UploadStartedAt:  &nowPtr,
+		UploadFinishedAt: &nowPtr,
+		Checksum:         &bento.Checksum,
+	})
+	if err != nil {
+		return
+	}
+
+	err = s.createCopyEvents(ctx, opt.CreatorId, opt.ApiTokenName, srcOrg, bento, targetOrg, newBento)
+	return
+}
+
+func (s *copyService) checkBentoNotExists(ctx context.Context, targetOrg *models.Organization, bentoRepositoryName, version string) error {
+	bentoRepository, err := BentoRepositoryService.GetByName(ctx, targetOrg.ID, bentoRepositoryName)
+	if utils.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = BentoService.GetByVersion(ctx, bentoRepository.ID, version)
+	if err == nil {
+		return errors.Errorf("bento %s:%s already exists in organization %s", bentoRepositoryName, version, targetOrg.Name)
+	}
+	if utils.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// deleteCopiedObject removes the archive copied for a bento or a model whose rows could not be created
+func (s *copyService) deleteCopiedObject(ctx context.Context, driver StorageDriver, objectName string) {
+	if err := driver.Delete(ctx, objectName); err != nil {
+		logrus.Errorf("delete the copied object %s: %v", objectName, err)
+	}
+}
+
+// copyReferencedModel makes sure a model referenced by a copied bento exists in the target organization,
+// the repository name cannot change because the bento manifest refers to the model by its tag
+func (s *copyService) copyReferencedModel(ctx context.Context, model *models.Model, creatorId uint, apiTokenName string, targetOrg *models.Organization) (*models.Model, error) {
+	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	targetModelRepository, err := ModelRepositoryService.GetByName(ctx, targetOrg.ID, modelRepository.Name)
+	if err != nil && !utils.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		existing, err := ModelService.GetByVersion(ctx, targetModelRepository.ID, model.Version)
+		if err != nil && !utils.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			if existing.Checksum != "" && model.Checksum != "" && existing.Checksum != model.Checksum {
+				return nil, errors.Errorf("model %s:%s already exists in organization %s with different content", modelRepository.Name, model.Version, targetOrg.Name)
+			}
+			return existing, nil
+		}
+	}
+	return s.CopyModel(ctx, model, CopyModelOption{
+		CreatorId:            creatorId,
+		ApiTokenName:         apiTokenName,
+		TargetOrganizationId: targetOrg.ID,
+	})
+}
+
+func (s *copyService) CopyModel(ctx context.Context, model *models.Model, opt CopyModelOption) (newModel *models.Model, err error) {
+	if model.UploadStatus != modelschemas.ModelUploadStatusSuccess {
+		err = errors.Errorf("model %s has not been uploaded successfully, cannot copy it", model.Version)
+		return
+	}
+	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
+	if err != nil {
+		return
+	}
+	srcOrg, err := OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
+	if err != nil {
+		return
+	}
+	targetOrg, err := OrganizationService.Get(ctx, opt.TargetOrganizationId)
+	if err != nil {
+		err = errors.Wrap(err, "get target organization")
+		return
+	}
+	targetModelRepositoryName := opt.TargetModelRepositoryName
+	if targetModelRepositoryName == "" {
+		targetModelRepositoryName = modelRepository.Name
+	}
+	if targetOrg.ID == srcOrg.ID && targetModelRepositoryName == modelRepository.Name {
+		err = errors.Errorf("cannot copy model %s:%s onto itself", modelRepository.Name, model.Version)
+		return
+	}
+
+	// the target is checked before the archive is copied, the archive of an existing model must not be replaced
+	if err = s.checkModelNotExists(ctx, targetOrg, targetModelRepositoryName, model.Version); err != nil {
+		return
+	}
+
+	srcDriver, err := ModelService.getStorageDriver(ctx, model)
+	if err != nil {
+		return
+	}
+	srcObjectName, err := ModelService.getObjectName(ctx, model)
+	if err != nil {
+		return
+	}
+	targetDriver, err := OrganizationService.GetStorageDriver(ctx, targetOrg, StorageBucketTypeModels)
+	if err != nil {
+		return
+	}
+	targetObjectName := ModelService.formatObjectName(targetOrg, targetModelRepositoryName, model.Version)
+	// the archive is copied before the transaction is started, a large archive would otherwise hold the transaction open for the whole copy
+	err = CopyStorageObject(ctx, srcDriver, srcObjectName, targetDriver, targetObjectName)
+	if err != nil {
+		err = errors.Wrap(err, "copy model object")
+		return
+	}
+	targetExists := false
+	defer func() {
+		if err != nil && !targetExists {
+			s.deleteCopiedObject(ctx, targetDriver, targetObjectName)
+		}
+	}()
+
+	labels, err := LabelService.ListLabelItemsByResource(ctx, srcOrg.ID, model)
+	if err != nil {
+		return
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	targetModelRepository, err := s.getOrCreateModelRepository(ctx, opt.CreatorId, targetOrg.ID, targetModelRepositoryName)
+	if err != nil {
+		err = errors.Wrap(err, "get or create target model repository")
+		return
+	}
+	_, err = ModelService.GetByVersion(ctx, targetModelRepository.ID, model.Version)
+	if err == nil {
+		targetExists = true
+		err = errors.Errorf("model %s:%s already exists in organization %s", targetModelRepositoryName, model.Version, targetOrg.Name)
+		return
+	}
+	if !utils.IsNotFound(err) {
+		return
+	}
+
+	newModel, err = ModelService.Create(ctx, CreateModelOption{
+		CreatorId:         opt.CreatorId,
+		ModelRepositoryId: targetModelRepository.ID,
+		Version:           model.Version,
+		Description:       model.Description,
+		BuildAt:           model.BuildAt,
+		Manifest:          model.Manifest,
+		Labels:            labels,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "create target model")
+		return
+	}
+
+	uploadStatus := modelschemas.ModelUploadStatusSuccess
+	now := time.Now()
+	nowPtr := &now
+	newModel, err = ModelService.Update(ctx, newModel, UpdateModelOption{
+		UploadStatus:     &uploadStatus,
+		UploadStartedAt:  &nowPtr,
+		UploadFinishedAt: &nowPtr,
+		Checksum:         &model.Checksum,
+	})
+	if err != nil {
+		return
+	}
+
+	err = s.createCopyEvents(ctx, opt.CreatorId, opt.ApiTokenName, srcOrg, model, targetOrg, newModel)
+	return
+}
+
+func (s *copyService) checkModelNotExists(ctx context.Context, targetOrg *models.Organization, modelRepositoryName, version string) error {
+	modelRepository, err := ModelRepositoryService.GetByName(ctx, targetOrg.ID, modelRepositoryName)
+	if utils.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = ModelService.GetByVersion(ctx, modelRepository.ID, version)
+	if err == nil {
+		return errors.Errorf("model %s:%s already exists in organization %s", modelRepositoryName, version, targetOrg.Name)
+	}
+	if utils.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
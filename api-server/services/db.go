@@ -153,6 +153,22 @@ func StartTransaction(ctx context.Context) (*gorm.DB, context.Context, func(erro
 type TransactionDBWrapper struct {
 	orig     *gorm.DB
 	released bool
+	// afterCommit are the side effects that cannot be rolled back, they only run once the transaction is committed
+	afterCommit []func()
+}
+
+// runAfterCommit runs fn once the transaction of the context is committed, or right away when there is no transaction,
+// fn never runs if the transaction is rolled back
+func runAfterCommit(ctx context.Context, fn func()) {
+	session_ := ctx.Value(DbSessionKey)
+	if session_ != nil {
+		db_ := session_.(*TransactionDBWrapper)
+		if !db_.released {
+			db_.afterCommit = append(db_.afterCommit, fn)
+			return
+		}
+	}
+	fn()
 }
 
 // nolint: unparam
@@ -190,8 +206,10 @@ func startTransaction(ctx context.Context) (*gorm.DB, context.Context, func(erro
 			panic(p)
 		} else if err != nil {
 			tx.Rollback()
-		} else {
-			tx.Commit()
+		} else if tx.Commit().Error == nil {
+			for _, fn := range db_.afterCommit {
+				fn()
+			}
 		}
 	}, nil
 }
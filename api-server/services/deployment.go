@@ -19,6 +19,7 @@ import (
 	batchtypev1beta "k8s.io/client-go/kubernetes/typed/batch/v1beta1"
 	apitypev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	networkingtypev1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+	listerCoreV1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 
 	"github.com/bentoml/yatai-common/system"
@@ -77,15 +78,22 @@ type ListDeploymentOption struct {
 	Order           *string
 }
 
-func (*deploymentService) Create(ctx context.Context, opt CreateDeploymentOption) (*models.Deployment, error) {
+func (*deploymentService) ValidateCreateOption(opt CreateDeploymentOption) error {
 	errs := validation.IsDNS1035Label(opt.Name)
 	if len(errs) > 0 {
-		return nil, errors.New(strings.Join(errs, ";"))
+		return errors.New(strings.Join(errs, ";"))
 	}
 
 	errs = validation.IsDNS1035Label(opt.KubeNamespace)
 	if len(errs) > 0 {
-		return nil, errors.New(strings.Join(errs, ";"))
+		return errors.New(strings.Join(errs, ";"))
+	}
+	return nil
+}
+
+func (s *deploymentService) Create(ctx context.Context, opt CreateDeploymentOption) (*models.Deployment, error) {
+	if err := s.ValidateCreateOption(opt); err != nil {
+		return nil, err
 	}
 
 	guid := xid.New()
@@ -277,16 +285,6 @@ func (s *deploymentService) List(ctx context.Context, opt ListDeploymentOption)
 	return deployments, uint(total), err
 }
 
-func (s *deploymentService) ListUnsynced(ctx context.Context) ([]*models.Deployment, error) {
-	q := getBaseQuery(ctx, s)
-	now := time.Now()
-	t := now.Add(-time.Minute)
-	q = q.Where("status_syncing_at is null or status_syncing_at < ? or status_updated_at is null or status_updated_at < ?", t, t)
-	envs := make([]*models.Deployment, 0)
-	err := q.Order("id DESC").Find(&envs).Error
-	return envs, err
-}
-
 func (s *deploymentService) UpdateStatus(ctx context.Context, deployment *models.Deployment, opt UpdateDeploymentStatusOption) (*models.Deployment, error) {
 	updater := map[string]interface{}{}
 	if opt.Status != nil {
@@ -447,6 +445,16 @@ func (s *deploymentService) GetKubeBentoDeploymentCli(ctx context.Context, d *mo
 }
 
 func (s *deploymentService) SyncStatus(ctx context.Context, d *models.Deployment) (modelschemas.DeploymentStatus, error) {
+	return s.syncStatus(ctx, d, nil)
+}
+
+// SyncStatusWithPodLister computes the status from the pods of a lister that is already synced,
+// it is used by the deployment status watcher which keeps its own pod informer per cluster
+func (s *deploymentService) SyncStatusWithPodLister(ctx context.Context, d *models.Deployment, podLister listerCoreV1.PodNamespaceLister) (modelschemas.DeploymentStatus, error) {
+	return s.syncStatus(ctx, d, podLister)
+}
+
+func (s *deploymentService) syncStatus(ctx context.Context, d *models.Deployment, podLister listerCoreV1.PodNamespaceLister) (modelschemas.DeploymentStatus, error) {
 	now := time.Now()
 	nowPtr := &now
 	_, err := s.UpdateStatus(ctx, d, UpdateDeploymentStatusOption{
@@ -455,7 +463,7 @@ func (s *deploymentService) SyncStatus(ctx context.Context, d *models.Deployment
 	if err != nil {
 		return d.Status, err
 	}
-	currentStatus, err := s.getStatusFromK8s(ctx, d)
+	currentStatus, err := s.getStatusFromK8s(ctx, d, podLister)
 	if err != nil {
 		return d.Status, err
 	}
@@ -471,19 +479,21 @@ func (s *deploymentService) SyncStatus(ctx context.Context, d *models.Deployment
 	return currentStatus, nil
 }
 
-func (s *deploymentService) getStatusFromK8s(ctx context.Context, d *models.Deployment) (modelschemas.DeploymentStatus, error) {
+func (s *deploymentService) getStatusFromK8s(ctx context.Context, d *models.Deployment, podLister listerCoreV1.PodNamespaceLister) (modelschemas.DeploymentStatus, error) {
 	defaultStatus := modelschemas.DeploymentStatusUnknown
 
-	cluster, err := ClusterService.GetAssociatedCluster(ctx, d)
-	if err != nil {
-		return defaultStatus, errors.Wrap(err, "get associated cluster")
-	}
+	if podLister == nil {
+		cluster, err := ClusterService.GetAssociatedCluster(ctx, d)
+		if err != nil {
+			return defaultStatus, errors.Wrap(err, "get associated cluster")
+		}
 
-	namespace := DeploymentService.GetKubeNamespace(d)
+		namespace := DeploymentService.GetKubeNamespace(d)
 
-	_, podLister, err := GetPodInformer(ctx, cluster, namespace)
-	if err != nil {
-		return defaultStatus, err
+		_, podLister, err = GetPodInformer(ctx, cluster, namespace)
+		if err != nil {
+			return defaultStatus, err
+		}
 	}
 
 	pods, err := KubePodService.ListPodsByDeployment(ctx, podLister, d)
@@ -631,6 +641,22 @@ func (s *deploymentService) GetURLs(ctx context.Context, deployment *models.Depl
 		return []string{}, nil
 	}
 	urls := make([]string, 0)
+
+	// the custom hostnames come first, they are the ones meant to be given to the users of the deployment
+	stableType := modelschemas.DeploymentTargetTypeStable
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		DeploymentRevisionId: utils.UintPtr(deploymentRevisions[0].ID),
+		Type:                 &stableType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, deploymentTarget := range deploymentTargets {
+		if deploymentTarget.Ingress != nil {
+			urls = append(urls, deploymentTarget.Ingress.GetURLs()...)
+		}
+	}
+
 	kubeName := deployment.Name
 	ingCli, err := s.GetKubeIngressesCli(ctx, deployment)
 	if err != nil {
@@ -642,7 +668,7 @@ func (s *deploymentService) GetURLs(ctx context.Context, deployment *models.Depl
 		return nil, err
 	}
 	if ingIsNotFound {
-		return []string{}, nil
+		return urls, nil
 	}
 	for _, rule := range ing.Spec.Rules {
 		urls = append(urls, fmt.Sprintf("http://%s", rule.Host))
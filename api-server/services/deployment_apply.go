@@ -0,0 +1,72 @@
+package services
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+type deploymentTargetFingerprint struct {
+	Type        modelschemas.DeploymentTargetType         `json:"type"`
+	BentoId     uint                                      `json:"bento_id"`
+	CanaryRules *modelschemas.DeploymentTargetCanaryRules `json:"canary_rules"`
+	Config      *modelschemas.DeploymentTargetConfig      `json:"config"`
+	Ingress     *schemas.DeploymentTargetIngressSchema    `json:"ingress"`
+}
+
+func getDeploymentTargetsFingerprints(deploymentTargets []*models.DeploymentTarget) ([]string, error) {
+	res := make([]string, 0, len(deploymentTargets))
+	for _, deploymentTarget := range deploymentTargets {
+		config := DeploymentTargetService.GetDefaultConfig()
+		if deploymentTarget.Config != nil {
+			config_ := *deploymentTarget.Config
+			config_.KubeResourceUid = ""
+			config_.KubeResourceVersion = ""
+			config = &config_
+		}
+		canaryRules := deploymentTarget.CanaryRules
+		if canaryRules != nil && len(*canaryRules) == 0 {
+			canaryRules = nil
+		}
+		fingerprint, err := json.Marshal(deploymentTargetFingerprint{
+			Type:        deploymentTarget.Type,
+			BentoId:     deploymentTarget.BentoId,
+			CanaryRules: canaryRules,
+			Config:      config,
+			Ingress:     deploymentTarget.Ingress,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal deployment target")
+		}
+		res = append(res, string(fingerprint))
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// IsSameDeploymentTargets tells whether two sets of deployment targets deploy the same bentos with the same settings,
+// the order of the targets and the kube resource versions recorded in their configs are ignored
+func IsSameDeploymentTargets(a, b []*models.DeploymentTarget) (bool, error) {
+	if len(a) != len(b) {
+		return false, nil
+	}
+	aFingerprints, err := getDeploymentTargetsFingerprints(a)
+	if err != nil {
+		return false, err
+	}
+	bFingerprints, err := getDeploymentTargetsFingerprints(b)
+	if err != nil {
+		return false, err
+	}
+	for idx := range aFingerprints {
+		if aFingerprints[idx] != bFingerprints[idx] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+)
+
+func TestIsSameDeploymentTargets(t *testing.T) {
+	newTarget := func(bentoId uint, targetType modelschemas.DeploymentTargetType, config *modelschemas.DeploymentTargetConfig) *models.DeploymentTarget {
+		return &models.DeploymentTarget{
+			BentoAssociate: models.BentoAssociate{
+				BentoId: bentoId,
+			},
+			Type:   targetType,
+			Config: config,
+		}
+	}
+
+	deployed := DeploymentTargetService.GetDefaultConfig()
+	deployed.KubeResourceUid = "uid"
+	deployed.KubeResourceVersion = "42"
+	live := []*models.DeploymentTarget{
+		newTarget(1, modelschemas.DeploymentTargetTypeStable, deployed),
+		newTarget(2, modelschemas.DeploymentTargetTypeCanary, DeploymentTargetService.GetDefaultConfig()),
+	}
+
+	same, err := IsSameDeploymentTargets(live, []*models.DeploymentTarget{
+		newTarget(2, modelschemas.DeploymentTargetTypeCanary, nil),
+		newTarget(1, modelschemas.DeploymentTargetTypeStable, nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !same {
+		t.Errorf("targets with the default config in another order should be the same")
+	}
+
+	scaled := DeploymentTargetService.GetDefaultConfig()
+	scaled.HPAConf.MaxReplicas = nil
+	same, err = IsSameDeploymentTargets(live, []*models.DeploymentTarget{
+		newTarget(1, modelschemas.DeploymentTargetTypeStable, scaled),
+		newTarget(2, modelschemas.DeploymentTargetTypeCanary, nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same {
+		t.Errorf("targets with a different config should differ")
+	}
+
+	same, err = IsSameDeploymentTargets(live, []*models.DeploymentTarget{
+		newTarget(1, modelschemas.DeploymentTargetTypeStable, nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same {
+		t.Errorf("a removed target should differ")
+	}
+}
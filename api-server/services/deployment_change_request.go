@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type deploymentChangeRequestService struct{}
+
+var DeploymentChangeRequestService = deploymentChangeRequestService{}
+
+func (s *deploymentChangeRequestService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.DeploymentChangeRequest{})
+}
+
+type CreateDeploymentChangeRequestOption struct {
+	CreatorId    uint
+	ApiTokenName string
+	Deployment   *models.Deployment
+	Kind         schemas.DeploymentChangeRequestKind
+	// Targets are the resolved targets of a deploy request, they are empty for a terminate or resync request
+	Targets []*schemas.CreateDeploymentTargetSchema
+}
+
+type UpdateDeploymentChangeRequestOption struct {
+	Status        *schemas.DeploymentChangeRequestStatus
+	ReviewerId    *uint
+	ReviewComment *string
+	ReviewedAt    *time.Time
+}
+
+type ListDeploymentChangeRequestOption struct {
+	BaseListOption
+	ClusterId    *uint
+	DeploymentId *uint
+	Status       *schemas.DeploymentChangeRequestStatus
+}
+
+// ReviewDeploymentChangeRequestOption tells who approves or rejects a request
+type ReviewDeploymentChangeRequestOption struct {
+	ReviewerId   uint
+	ApiTokenName string
+	Comment      string
+}
+
+// Create records the change with the diff of the targets, the pending requests of the deployment are superseded by it
+func (s *deploymentChangeRequestService) Create(ctx context.Context, opt CreateDeploymentChangeRequestOption) (request *models.DeploymentChangeRequest, err error) {
+	// a resync leaves the recorded targets as they are, the live edits it undoes are in the drift of the deployment
+	var diff schemas.DeploymentChangeRequestDiffSchema
+	if opt.Kind != schemas.DeploymentChangeRequestKindResync {
+		diff, err = s.diffTargets(ctx, opt.Deployment, opt.Targets)
+		if err != nil {
+			err = errors.Wrap(err, "diff deployment targets")
+			return
+		}
+	}
+
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	err = s.getBaseDB(ctx).
+		Where("deployment_id = ?", opt.Deployment.ID).
+		Where("status = ?", schemas.DeploymentChangeRequestStatusPending).
+		Updates(map[string]interface{}{
+			"status": schemas.DeploymentChangeRequestStatusSuperseded,
+		}).Error
+	if err != nil {
+		return
+	}
+
+	request = &models.DeploymentChangeRequest{
+		CreatorAssociate: models.CreatorAssociate{
+			CreatorId: opt.CreatorId,
+		},
+		ClusterAssociate: models.ClusterAssociate{
+			ClusterId: opt.Deployment.ClusterId,
+		},
+		DeploymentAssociate: models.DeploymentAssociate{
+			DeploymentId:              opt.Deployment.ID,
+			AssociatedDeploymentCache: opt.Deployment,
+		},
+		Kind:    opt.Kind,
+		Status:  schemas.DeploymentChangeRequestStatusPending,
+		Targets: opt.Targets,
+		Diff:    diff,
+	}
+	err = db.Create(request).Error
+	if err != nil {
+		return
+	}
+
+	err = s.createEvent(ctx, request, opt.CreatorId, opt.ApiTokenName, fmt.Sprintf("requested %s change", request.Kind))
+	return
+}
+
+// diffTargets compares the active targets of the deployment with the requested ones, a terminate request removes all of them
+func (s *deploymentChangeRequestService) diffTargets(ctx context.Context, deployment *models.Deployment, targets []*schemas.CreateDeploymentTargetSchema) (schemas.DeploymentChangeRequestDiffSchema, error) {
+	var activeTargets []*schemas.DeploymentManifestTargetSchema
+	manifests, err := DeploymentManifestService.ToManifests(ctx, []*models.Deployment{deployment})
+	if err != nil {
+		return nil, err
+	}
+	if len(manifests) > 0 {
+		activeTargets = manifests[0].Spec.Targets
+	}
+	var requestedTargets []*schemas.DeploymentManifestTargetSchema
+	if len(targets) > 0 {
+		requestedTargets = DeploymentManifestService.ToManifestTargets(targets)
+	}
+	return DiffKubeObjects(activeTargets, requestedTargets)
+}
+
+func (s *deploymentChangeRequestService) Update(ctx context.Context, request *models.DeploymentChangeRequest, opt UpdateDeploymentChangeRequestOption) (*models.DeploymentChangeRequest, error) {
+	var err error
+	updaters := make(map[string]interface{})
+
+	if opt.Status != nil {
+		updaters["status"] = *opt.Status
+		defer func() {
+			if err == nil {
+				request.Status = *opt.Status
+			}
+		}()
+	}
+	if opt.ReviewerId != nil {
+		updaters["reviewer_id"] = *opt.ReviewerId
+		defer func() {
+			if err == nil {
+				request.ReviewerId = opt.ReviewerId
+			}
+		}()
+	}
+	if opt.ReviewComment != nil {
+		updaters["review_comment"] = *opt.ReviewComment
+		defer func() {
+			if err == nil {
+				request.ReviewComment = *opt.ReviewComment
+			}
+		}()
+	}
+	if opt.ReviewedAt != nil {
+		updaters["reviewed_at"] = *opt.ReviewedAt
+		defer func() {
+			if err == nil {
+				request.ReviewedAt = opt.ReviewedAt
+			}
+		}()
+	}
+
+	if len(updaters) == 0 {
+		return request, nil
+	}
+
+	err = s.getBaseDB(ctx).Where("id = ?", request.ID).Updates(updaters).Error
+
+	return request, err
+}
+
+func (s *deploymentChangeRequestService) Get(ctx context.Context, id uint) (*models.DeploymentChangeRequest, error) {
+	var request models.DeploymentChangeRequest
+	err := getBaseQuery(ctx, s).Where("id = ?", id).First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	if request.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &request, nil
+}
+
+func (s *deploymentChangeRequestService) GetByUid(ctx context.Context, uid string) (*models.DeploymentChangeRequest, error) {
+	var request models.DeploymentChangeRequest
+	err := getBaseQuery(ctx, s).Where("uid = ?", uid).First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	if request.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &request, nil
+}
+
+func (s *deploymentChangeRequestService) List(ctx context.Context, opt ListDeploymentChangeRequestOption) ([]*models.DeploymentChangeRequest, uint, error) {
+	query := getBaseQuery(ctx, s)
+	if opt.ClusterId != nil {
+		query = query.Where("cluster_id = ?", *opt.ClusterId)
+	}
+	if opt.DeploymentId != nil {
+		query = query.Where("deployment_id = ?", *opt.DeploymentId)
+	}
+	if opt.Status != nil {
+		query = query.Where("status = ?", *opt.Status)
+	}
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	requests := make([]*models.DeploymentChangeRequest, 0)
+	query = opt.BindQueryWithLimit(query)
+	err = query.Order("id DESC").Find(&requests).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return requests, uint(total), err
+}
+
+// Review marks the request approved or rejected, applying an approved change is up to the caller in the same transaction
+func (s *deploymentChangeRequestService) Review(ctx context.Context, request *models.DeploymentChangeRequest, approved bool, opt ReviewDeploymentChangeRequestOption) (err error) {
+	if request.Status != schemas.DeploymentChangeRequestStatusPending {
+		return errors.Errorf("deployment change request %s is already %s", request.Uid, request.Status)
+	}
+	// two-person review: whoever requested the change cannot review it
+	if request.CreatorId == opt.ReviewerId {
+		return errors.Errorf("deployment change request %s must be reviewed by another admin of the cluster", request.Uid)
+	}
+
+	status := schemas.DeploymentChangeRequestStatusRejected
+	if approved {
+		status = schemas.DeploymentChangeRequestStatusApproved
+	}
+	now := time.Now()
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	// the row is locked by the update, a request reviewed or superseded meanwhile is not reviewed twice
+	result := s.getBaseDB(ctx).
+		Where("id = ?", request.ID).
+		Where("status = ?", schemas.DeploymentChangeRequestStatusPending).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"reviewer_id":    opt.ReviewerId,
+			"review_comment": opt.Comment,
+			"reviewed_at":    now,
+		})
+	if result.Error != nil {
+		err = result.Error
+		return
+	}
+	if result.RowsAffected == 0 {
+		err = errors.Errorf("deployment change request %s is not pending anymore", request.Uid)
+		return
+	}
+	request.Status = status
+	request.ReviewerId = utils.UintPtr(opt.ReviewerId)
+	request.ReviewComment = opt.Comment
+	request.ReviewedAt = &now
+
+	err = s.createEvent(ctx, request, opt.ReviewerId, opt.ApiTokenName, fmt.Sprintf("%s %s change", status, request.Kind))
+	return
+}
+
+func (s *deploymentChangeRequestService) createEvent(ctx context.Context, request *models.DeploymentChangeRequest, creatorId uint, apiTokenName string, operationName string) error {
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, request)
+	if err != nil {
+		return errors.Wrap(err, "get deployment")
+	}
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return errors.Wrap(err, "get associated cluster")
+	}
+	_, err = EventService.Create(ctx, CreateEventOption{
+		CreatorId:      creatorId,
+		ApiTokenName:   apiTokenName,
+		OrganizationId: &cluster.OrganizationId,
+		ClusterId:      &cluster.ID,
+		ResourceType:   modelschemas.ResourceTypeDeployment,
+		ResourceId:     deployment.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  operationName,
+	})
+	if err != nil {
+		return errors.Wrap(err, "create event")
+	}
+	return nil
+}
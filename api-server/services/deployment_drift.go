@@ -0,0 +1,400 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/common/utils"
+
+	servingv1alpha2 "github.com/bentoml/yatai-deployment/apis/serving/v1alpha2"
+	servingclientv1alpha2 "github.com/bentoml/yatai-deployment/generated/serving/clientset/versioned/typed/serving/v1alpha2"
+)
+
+type deploymentDriftService struct{}
+
+var DeploymentDriftService = deploymentDriftService{}
+
+// reportedUnmanagedBentoDeployments remembers the unmanaged BentoDeployments of each cluster that already have an event,
+// it only lives in memory so an unmanaged BentoDeployment is reported once more after a restart
+var (
+	reportedUnmanagedBentoDeployments   = make(map[uint]map[string]struct{})
+	reportedUnmanagedBentoDeploymentsMu sync.Mutex
+)
+
+type ResyncDeploymentOption struct {
+	CreatorId    uint
+	ApiTokenName string
+}
+
+// DetectAll is run periodically to compare every deployment with its BentoDeployment in the cluster
+func (s *deploymentDriftService) DetectAll(ctx context.Context) error {
+	logger := logrus.WithField("cron", "deployment drift")
+
+	clusters, _, err := ClusterService.List(ctx, ListClusterOption{})
+	if err != nil {
+		return errors.Wrap(err, "list clusters")
+	}
+	for _, cluster := range clusters {
+		if err = s.DetectCluster(ctx, cluster); err != nil {
+			logger.Errorf("detect drift of cluster %s: %s", cluster.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// DetectCluster lists the BentoDeployments of every namespace the deployments of the cluster live in,
+// so that the ones no deployment knows about are found as well
+func (s *deploymentDriftService) DetectCluster(ctx context.Context, cluster *models.Cluster) error {
+	logger := logrus.WithField("cron", "deployment drift").WithField("cluster", cluster.Name)
+
+	deployments, _, err := DeploymentService.List(ctx, ListDeploymentOption{
+		ClusterId: utils.UintPtr(cluster.ID),
+	})
+	if err != nil {
+		return errors.Wrap(err, "list deployments")
+	}
+	if len(deployments) == 0 {
+		return nil
+	}
+
+	_, restConfig, err := ClusterService.GetKubeCliSet(ctx, cluster)
+	if err != nil {
+		return errors.Wrap(err, "get kube cli set")
+	}
+	cli, err := servingclientv1alpha2.NewForConfig(restConfig)
+	if err != nil {
+		return errors.Wrap(err, "get bento deployment cliset")
+	}
+
+	deploymentsByNamespace := make(map[string][]*models.Deployment)
+	for _, deployment := range deployments {
+		kubeNs := DeploymentService.GetKubeNamespace(deployment)
+		deploymentsByNamespace[kubeNs] = append(deploymentsByNamespace[kubeNs], deployment)
+	}
+
+	unmanaged := make([]string, 0)
+	for kubeNs, deployments := range deploymentsByNamespace {
+		kubeBentoDeploymentList, err := cli.BentoDeployments(kubeNs).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "list bento deployments in namespace %s", kubeNs)
+		}
+		lives := make(map[string]*servingv1alpha2.BentoDeployment, len(kubeBentoDeploymentList.Items))
+		for idx := range kubeBentoDeploymentList.Items {
+			live := &kubeBentoDeploymentList.Items[idx]
+			lives[live.Name] = live
+		}
+		for _, deployment := range deployments {
+			live := lives[deployment.Name]
+			delete(lives, deployment.Name)
+			if deployment.Status == modelschemas.DeploymentStatusTerminating {
+				continue
+			}
+			drift, err := s.detect(ctx, deployment, live)
+			if err != nil {
+				logger.Errorf("detect drift of deployment %s: %s", deployment.Name, err.Error())
+				continue
+			}
+			if err = s.record(ctx, deployment, drift); err != nil {
+				logger.Errorf("record drift of deployment %s: %s", deployment.Name, err.Error())
+			}
+		}
+		for name := range lives {
+			unmanaged = append(unmanaged, fmt.Sprintf("%s/%s", kubeNs, name))
+		}
+	}
+	sort.Strings(unmanaged)
+
+	return s.recordUnmanaged(ctx, cluster, unmanaged)
+}
+
+// detect returns nil when the live BentoDeployment matches the active revision
+func (s *deploymentDriftService) detect(ctx context.Context, deployment *models.Deployment, live *servingv1alpha2.BentoDeployment) (*schemas.DeploymentDriftSchema, error) {
+	deploymentRevision, err := s.getActiveDeploymentRevision(ctx, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	if deploymentRevision == nil {
+		if live == nil {
+			return nil, nil
+		}
+		return &schemas.DeploymentDriftSchema{
+			Items: []*schemas.DeploymentDriftItemSchema{
+				{
+					Type:             schemas.DeploymentDriftTypeUnmanaged,
+					KubeResourceName: live.Name,
+				},
+			},
+			DetectedAt: time.Now(),
+		}, nil
+	}
+
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		DeploymentRevisionId: utils.UintPtr(deploymentRevision.ID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployment targets")
+	}
+	if len(deploymentTargets) == 0 {
+		return nil, nil
+	}
+
+	drift := &schemas.DeploymentDriftSchema{
+		DeploymentRevisionUid: deploymentRevision.Uid,
+		DetectedAt:            time.Now(),
+	}
+
+	if live == nil {
+		drift.Items = []*schemas.DeploymentDriftItemSchema{
+			{
+				Type:             schemas.DeploymentDriftTypeMissing,
+				KubeResourceName: deployment.Name,
+			},
+		}
+		return drift, nil
+	}
+
+	// the targets are deployed in order to the same BentoDeployment, so the last one is what the cluster should have
+	desired, err := KubeBentoDeploymentService.Render(ctx, deployment, deploymentTargets[len(deploymentTargets)-1])
+	if err != nil {
+		return nil, errors.Wrap(err, "render kube bento deployment")
+	}
+	diff, err := DiffKubeObjects(live.Spec, desired.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "diff kube bento deployment")
+	}
+	if len(diff) == 0 {
+		return nil, nil
+	}
+	drift.Items = []*schemas.DeploymentDriftItemSchema{
+		{
+			Type:             schemas.DeploymentDriftTypeModified,
+			KubeResourceName: live.Name,
+			Diff:             diff,
+		},
+	}
+	return drift, nil
+}
+
+// record saves the drift on the deployment, an event is only created when the drift changes
+func (s *deploymentDriftService) record(ctx context.Context, deployment *models.Deployment, drift *schemas.DeploymentDriftSchema) error {
+	if s.isSameDrift(deployment.Drift, drift) {
+		return nil
+	}
+	hadDrift := deployment.Drift != nil
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { df(err) }()
+
+	if err = s.updateDrift(ctx, deployment, drift); err != nil {
+		return err
+	}
+
+	creatorId, err := s.getEventCreatorId(ctx, deployment)
+	if err != nil {
+		return err
+	}
+	if drift == nil {
+		if hadDrift {
+			err = s.createEvent(ctx, deployment, creatorId, "", modelschemas.EventStatusSuccess, "drift resolved")
+		}
+		return err
+	}
+	err = s.createEvent(ctx, deployment, creatorId, "", modelschemas.EventStatusFailed, fmt.Sprintf("detected drift: %s", s.describe(drift)))
+	return err
+}
+
+// Resync re-applies the spec of the active revision to the cluster, it overwrites the edits made out of band
+func (s *deploymentDriftService) Resync(ctx context.Context, deployment *models.Deployment, opt ResyncDeploymentOption) (err error) {
+	deploymentRevision, err := s.getActiveDeploymentRevision(ctx, deployment)
+	if err != nil {
+		return err
+	}
+	if deploymentRevision == nil {
+		return errors.Errorf("deployment %s has no active revision to re-sync", deployment.Name)
+	}
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		DeploymentRevisionId: utils.UintPtr(deploymentRevision.ID),
+	})
+	if err != nil {
+		return errors.Wrap(err, "list deployment targets")
+	}
+
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return errors.Wrap(err, "get associated cluster")
+	}
+	kubeNs := DeploymentService.GetKubeNamespace(deployment)
+	_, err = KubeNamespaceService.MakeSureNamespace(ctx, cluster, kubeNs)
+	if err != nil {
+		return errors.Wrapf(err, "make sure kube namespace %s", kubeNs)
+	}
+
+	// the targets are deployed without going through DeploymentRevisionService.Deploy, which drops the revision when the deploy fails
+	deployOption, err := DeploymentRevisionService.GetDeployOption(ctx, deploymentRevision, false)
+	if err != nil {
+		return err
+	}
+	for _, deploymentTarget := range deploymentTargets {
+		_, err = DeploymentTargetService.Deploy(ctx, deploymentTarget, deployOption)
+		if err != nil {
+			return errors.Wrapf(err, "deploy deployment target %s", deploymentTarget.Uid)
+		}
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	if err = s.updateDrift(ctx, deployment, nil); err != nil {
+		return
+	}
+	err = s.createEvent(ctx, deployment, opt.CreatorId, opt.ApiTokenName, modelschemas.EventStatusSuccess, fmt.Sprintf("re-synced revision %s", deploymentRevision.Uid))
+	return
+}
+
+func (s *deploymentDriftService) getActiveDeploymentRevision(ctx context.Context, deployment *models.Deployment) (*models.DeploymentRevision, error) {
+	deploymentRevisions, _, err := DeploymentRevisionService.List(ctx, ListDeploymentRevisionOption{
+		BaseListOption: BaseListOption{
+			Start: utils.UintPtr(0),
+			Count: utils.UintPtr(1),
+		},
+		DeploymentId: utils.UintPtr(deployment.ID),
+		Status:       modelschemas.DeploymentRevisionStatusActive.Ptr(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list active deployment revisions")
+	}
+	if len(deploymentRevisions) == 0 {
+		return nil, nil
+	}
+	return deploymentRevisions[0], nil
+}
+
+func (s *deploymentDriftService) updateDrift(ctx context.Context, deployment *models.Deployment, drift *schemas.DeploymentDriftSchema) error {
+	err := DeploymentService.getBaseDB(ctx).Where("id = ?", deployment.ID).Updates(map[string]interface{}{
+		"drift": drift,
+	}).Error
+	if err != nil {
+		return err
+	}
+	deployment.Drift = drift
+	return nil
+}
+
+// isSameDrift ignores when the drift was detected
+func (s *deploymentDriftService) isSameDrift(a, b *schemas.DeploymentDriftSchema) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.DeploymentRevisionUid == b.DeploymentRevisionUid && reflect.DeepEqual(s.normalizeItems(a.Items), s.normalizeItems(b.Items))
+}
+
+// normalizeItems makes the items read back from the database comparable with freshly detected ones
+func (s *deploymentDriftService) normalizeItems(items []*schemas.DeploymentDriftItemSchema) interface{} {
+	res, err := toJsonValue(items)
+	if err != nil {
+		return items
+	}
+	return res
+}
+
+func (s *deploymentDriftService) describe(drift *schemas.DeploymentDriftSchema) string {
+	descriptions := make([]string, 0, len(drift.Items))
+	for _, item := range drift.Items {
+		switch item.Type {
+		case schemas.DeploymentDriftTypeModified:
+			paths := make([]string, 0, len(item.Diff))
+			for _, diffItem := range item.Diff {
+				paths = append(paths, diffItem.Path)
+			}
+			descriptions = append(descriptions, fmt.Sprintf("%s was modified (%s)", item.KubeResourceName, strings.Join(paths, ", ")))
+		case schemas.DeploymentDriftTypeMissing:
+			descriptions = append(descriptions, fmt.Sprintf("%s is missing", item.KubeResourceName))
+		case schemas.DeploymentDriftTypeUnmanaged:
+			descriptions = append(descriptions, fmt.Sprintf("%s exists without an active revision", item.KubeResourceName))
+		}
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// getEventCreatorId attributes the drift events to whoever deployed the active revision
+func (s *deploymentDriftService) getEventCreatorId(ctx context.Context, deployment *models.Deployment) (uint, error) {
+	deploymentRevision, err := s.getActiveDeploymentRevision(ctx, deployment)
+	if err != nil {
+		return 0, err
+	}
+	if deploymentRevision != nil {
+		return deploymentRevision.CreatorId, nil
+	}
+	return deployment.CreatorId, nil
+}
+
+// recordUnmanaged creates an event on the cluster for each BentoDeployment that no deployment knows about
+func (s *deploymentDriftService) recordUnmanaged(ctx context.Context, cluster *models.Cluster, unmanaged []string) error {
+	reportedUnmanagedBentoDeploymentsMu.Lock()
+	defer reportedUnmanagedBentoDeploymentsMu.Unlock()
+
+	reported := reportedUnmanagedBentoDeployments[cluster.ID]
+	current := make(map[string]struct{}, len(unmanaged))
+	for _, name := range unmanaged {
+		current[name] = struct{}{}
+		if _, ok := reported[name]; ok {
+			continue
+		}
+		_, err := EventService.Create(ctx, CreateEventOption{
+			CreatorId:      cluster.CreatorId,
+			OrganizationId: &cluster.OrganizationId,
+			ClusterId:      &cluster.ID,
+			ResourceType:   modelschemas.ResourceTypeCluster,
+			ResourceId:     cluster.ID,
+			Status:         modelschemas.EventStatusFailed,
+			OperationName:  fmt.Sprintf("detected unmanaged bento deployment %s", name),
+		})
+		if err != nil {
+			return errors.Wrap(err, "create event")
+		}
+	}
+	reportedUnmanagedBentoDeployments[cluster.ID] = current
+	return nil
+}
+
+func (s *deploymentDriftService) createEvent(ctx context.Context, deployment *models.Deployment, creatorId uint, apiTokenName string, status modelschemas.EventStatus, operationName string) error {
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return errors.Wrap(err, "get associated cluster")
+	}
+	_, err = EventService.Create(ctx, CreateEventOption{
+		CreatorId:      creatorId,
+		ApiTokenName:   apiTokenName,
+		OrganizationId: &cluster.OrganizationId,
+		ClusterId:      &cluster.ID,
+		ResourceType:   modelschemas.ResourceTypeDeployment,
+		ResourceId:     deployment.ID,
+		Status:         status,
+		OperationName:  operationName,
+	})
+	if err != nil {
+		return errors.Wrap(err, "create event")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+func TestDeploymentDriftIsSameDrift(t *testing.T) {
+	drift := &schemas.DeploymentDriftSchema{
+		DeploymentRevisionUid: "rev-1",
+		Items: []*schemas.DeploymentDriftItemSchema{
+			{
+				Type:             schemas.DeploymentDriftTypeModified,
+				KubeResourceName: "iris",
+				Diff: []*schemas.KubeObjectDiffItemSchema{
+					{Path: "autoscaling.min_replicas", Operation: schemas.KubeObjectDiffOperationChanged, From: 3, To: 1},
+				},
+			},
+		},
+		DetectedAt: time.Now(),
+	}
+
+	// the recorded drift is read back from a jsonb column, the numbers become float64 and the time changes
+	content, err := json.Marshal(drift)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := &schemas.DeploymentDriftSchema{}
+	if err = recorded.Scan(content); err != nil {
+		t.Fatal(err)
+	}
+	recorded.DetectedAt = recorded.DetectedAt.Add(-time.Hour)
+
+	if !DeploymentDriftService.isSameDrift(recorded, drift) {
+		t.Error("expected the recorded drift to be the same as the detected one")
+	}
+	if DeploymentDriftService.isSameDrift(nil, drift) || DeploymentDriftService.isSameDrift(recorded, nil) {
+		t.Error("expected a drift to differ from no drift")
+	}
+	if !DeploymentDriftService.isSameDrift(nil, nil) {
+		t.Error("expected no drift to be the same as no drift")
+	}
+
+	drift.Items[0].Diff[0].To = 2
+	if DeploymentDriftService.isSameDrift(recorded, drift) {
+		t.Error("expected a changed diff to be a different drift")
+	}
+}
+
+func TestDeploymentDriftDescribe(t *testing.T) {
+	description := DeploymentDriftService.describe(&schemas.DeploymentDriftSchema{
+		Items: []*schemas.DeploymentDriftItemSchema{
+			{
+				Type:             schemas.DeploymentDriftTypeModified,
+				KubeResourceName: "iris",
+				Diff: []*schemas.KubeObjectDiffItemSchema{
+					{Path: "bento_tag"},
+					{Path: "envs.0.value"},
+				},
+			},
+			{
+				Type:             schemas.DeploymentDriftTypeMissing,
+				KubeResourceName: "fraud",
+			},
+		},
+	})
+	expected := "iris was modified (bento_tag, envs.0.value); fraud is missing"
+	if description != expected {
+		t.Errorf("expected %q, got %q", expected, description)
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type deploymentManifestService struct{}
+
+var DeploymentManifestService = deploymentManifestService{}
+
+// ToManifests renders the active revisions of the deployments as manifests, the deployments that have never been deployed or are terminated are skipped
+func (s *deploymentManifestService) ToManifests(ctx context.Context, deployments []*models.Deployment) ([]*schemas.DeploymentManifestSchema, error) {
+	res := make([]*schemas.DeploymentManifestSchema, 0, len(deployments))
+	for _, deployment := range deployments {
+		switch deployment.Status {
+		case modelschemas.DeploymentStatusTerminating, modelschemas.DeploymentStatusTerminated, modelschemas.DeploymentStatusNonDeployed:
+			continue
+		}
+
+		status := modelschemas.DeploymentRevisionStatusActive
+		deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+			DeploymentId:             utils.UintPtr(deployment.ID),
+			DeploymentRevisionStatus: &status,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "list the active targets of deployment %s", deployment.Name)
+		}
+		if len(deploymentTargets) == 0 {
+			continue
+		}
+
+		cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get the cluster of deployment %s", deployment.Name)
+		}
+		labels, err := LabelService.ListLabelItemsByResource(ctx, cluster.OrganizationId, deployment)
+		if err != nil {
+			return nil, errors.Wrapf(err, "list the labels of deployment %s", deployment.Name)
+		}
+
+		targets := make([]*schemas.DeploymentManifestTargetSchema, 0, len(deploymentTargets))
+		for _, deploymentTarget := range deploymentTargets {
+			bento, err := BentoService.GetAssociatedBento(ctx, deploymentTarget)
+			if err != nil {
+				return nil, errors.Wrapf(err, "get the bento of deployment %s", deployment.Name)
+			}
+			bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+			if err != nil {
+				return nil, errors.Wrapf(err, "get the bento repository of deployment %s", deployment.Name)
+			}
+			config := deploymentTarget.Config
+			if config != nil {
+				// the kube resource fields are runtime state, they must not be replayed by an import
+				config_ := *config
+				config_.KubeResourceUid = ""
+				config_.KubeResourceVersion = ""
+				config = &config_
+			}
+			canaryRules := deploymentTarget.CanaryRules
+			if canaryRules != nil && len(*canaryRules) == 0 {
+				canaryRules = nil
+			}
+			targets = append(targets, &schemas.DeploymentManifestTargetSchema{
+				Type:        deploymentTarget.Type,
+				Bento:       fmt.Sprintf("%s:%s", bentoRepository.Name, bento.Version),
+				CanaryRules: canaryRules,
+				Config:      config,
+				Ingress:     deploymentTarget.Ingress,
+			})
+		}
+		sortManifestTargets(targets)
+
+		res = append(res, &schemas.DeploymentManifestSchema{
+			ApiVersion: schemas.DeploymentManifestApiVersionV1,
+			Kind:       schemas.DeploymentManifestKind,
+			Metadata: schemas.DeploymentManifestMetadataSchema{
+				Cluster:     cluster.Name,
+				Name:        deployment.Name,
+				Namespace:   deployment.KubeNamespace,
+				Labels:      labels,
+				Description: deployment.Description,
+			},
+			Spec: schemas.DeploymentManifestSpecSchema{
+				Targets: targets,
+			},
+		})
+	}
+
+	// a stable order keeps the exports diffable
+	sort.SliceStable(res, func(i, j int) bool {
+		a, b := res[i].Metadata, res[j].Metadata
+		if a.Cluster != b.Cluster {
+			return a.Cluster < b.Cluster
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return res, nil
+}
+
+// ToManifestTargets renders the targets of a deployment request the way ToManifests renders the active targets, so that both can be compared
+func (s *deploymentManifestService) ToManifestTargets(targets []*schemas.CreateDeploymentTargetSchema) []*schemas.DeploymentManifestTargetSchema {
+	res := make([]*schemas.DeploymentManifestTargetSchema, 0, len(targets))
+	for _, target := range targets {
+		config := target.Config
+		if config != nil {
+			config_ := *config
+			config_.KubeResourceUid = ""
+			config_.KubeResourceVersion = ""
+			config = &config_
+		}
+		canaryRules := target.CanaryRules
+		if canaryRules != nil && len(*canaryRules) == 0 {
+			canaryRules = nil
+		}
+		res = append(res, &schemas.DeploymentManifestTargetSchema{
+			Type:        target.Type,
+			Bento:       fmt.Sprintf("%s:%s", target.BentoRepository, target.Bento),
+			CanaryRules: canaryRules,
+			Config:      config,
+			Ingress:     target.Ingress,
+		})
+	}
+	sortManifestTargets(res)
+	return res
+}
+
+func sortManifestTargets(targets []*schemas.DeploymentManifestTargetSchema) {
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].Type != targets[j].Type {
+			return targets[i].Type > targets[j].Type
+		}
+		return targets[i].Bento < targets[j].Bento
+	})
+}
+
+// MarshalManifests renders the manifests as a multi-document yaml stream
+func (s *deploymentManifestService) MarshalManifests(manifests []*schemas.DeploymentManifestSchema) ([]byte, error) {
+	var buf bytes.Buffer
+	for idx, manifest := range manifests {
+		content, err := yaml.Marshal(manifest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshal the manifest of deployment %s", manifest.Metadata.Name)
+		}
+		if idx > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(content)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalManifests parses and validates a multi-document yaml stream, the empty documents are ignored
+func (s *deploymentManifestService) UnmarshalManifests(r io.Reader) ([]*schemas.DeploymentManifestSchema, error) {
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(r))
+	res := make([]*schemas.DeploymentManifestSchema, 0)
+	for {
+		content, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "read yaml document")
+		}
+		if len(bytes.TrimSpace(content)) == 0 {
+			continue
+		}
+		var manifest *schemas.DeploymentManifestSchema
+		if err = yaml.Unmarshal(content, &manifest); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal the document %d", len(res))
+		}
+		if manifest == nil {
+			continue
+		}
+		if err = manifest.Validate(); err != nil {
+			return nil, err
+		}
+		res = append(res, manifest)
+	}
+	return res, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+func TestDeploymentManifestsRoundTrip(t *testing.T) {
+	manifests := []*schemas.DeploymentManifestSchema{
+		{
+			ApiVersion: schemas.DeploymentManifestApiVersionV1,
+			Kind:       schemas.DeploymentManifestKind,
+			Metadata: schemas.DeploymentManifestMetadataSchema{
+				Cluster:   "default",
+				Name:      "iris",
+				Namespace: "yatai",
+				Labels:    modelschemas.LabelItemsSchema{{Key: "team", Value: "ml"}},
+			},
+			Spec: schemas.DeploymentManifestSpecSchema{
+				Targets: []*schemas.DeploymentManifestTargetSchema{
+					{Type: modelschemas.DeploymentTargetTypeStable, Bento: "iris_classifier:v1", Config: DeploymentTargetService.GetDefaultConfig()},
+				},
+			},
+		},
+		{
+			ApiVersion: schemas.DeploymentManifestApiVersionV1,
+			Kind:       schemas.DeploymentManifestKind,
+			Metadata: schemas.DeploymentManifestMetadataSchema{
+				Cluster:   "default",
+				Name:      "fraud",
+				Namespace: "yatai",
+			},
+			Spec: schemas.DeploymentManifestSpecSchema{
+				Targets: []*schemas.DeploymentManifestTargetSchema{
+					{Type: modelschemas.DeploymentTargetTypeStable, Bento: "fraud_detection:v2"},
+				},
+			},
+		},
+	}
+
+	content, err := DeploymentManifestService.MarshalManifests(manifests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a leading separator and an empty document are what hand written files usually contain
+	parsed, err := DeploymentManifestService.UnmarshalManifests(strings.NewReader("---\n" + string(content) + "\n---\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("expected 2 manifests, got %d", len(parsed))
+	}
+	if parsed[0].Metadata.Name != "iris" || parsed[1].Metadata.Name != "fraud" {
+		t.Errorf("unexpected manifests order: %s, %s", parsed[0].Metadata.Name, parsed[1].Metadata.Name)
+	}
+	if len(parsed[0].Metadata.Labels) != 1 || parsed[0].Metadata.Labels[0].Value != "ml" {
+		t.Errorf("labels are not preserved: %v", parsed[0].Metadata.Labels)
+	}
+	if parsed[0].Spec.Targets[0].Config == nil || parsed[1].Spec.Targets[0].Config != nil {
+		t.Error("target configs are not preserved")
+	}
+
+	_, err = DeploymentManifestService.UnmarshalManifests(strings.NewReader("apiVersion: yatai.ai/v2\nkind: Deployment\n"))
+	if err == nil {
+		t.Error("expected an unsupported apiVersion to be rejected")
+	}
+	_, err = DeploymentManifestService.UnmarshalManifests(strings.NewReader(strings.Replace(string(content), "iris_classifier:v1", "iris_classifier", 1)))
+	if err == nil {
+		t.Error("expected a bento tag without a version to be rejected")
+	}
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type deploymentPresetService struct{}
+
+var DeploymentPresetService = deploymentPresetService{}
+
+func (s *deploymentPresetService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.DeploymentPreset{})
+}
+
+type CreateDeploymentPresetOption struct {
+	CreatorId      uint
+	OrganizationId uint
+	// ClusterId is nil for the organization presets
+	ClusterId   *uint
+	Name        string
+	Description string
+	Config      *modelschemas.DeploymentTargetConfig
+	IsDefault   bool
+}
+
+type UpdateDeploymentPresetOption struct {
+	Description *string
+	Config      **modelschemas.DeploymentTargetConfig
+	IsDefault   *bool
+}
+
+type ListDeploymentPresetOption struct {
+	BaseListOption
+	OrganizationId *uint
+	// ClusterId lists the presets of the cluster, the organization presets are listed when it is nil
+	ClusterId *uint
+}
+
+func (s *deploymentPresetService) whereScope(query *gorm.DB, organizationId uint, clusterId *uint) *gorm.DB {
+	query = query.Where("organization_id = ?", organizationId)
+	if clusterId == nil {
+		return query.Where("cluster_id IS NULL")
+	}
+	return query.Where("cluster_id = ?", *clusterId)
+}
+
+// clearDefault unmarks the default preset of the scope, the preset being marked is left untouched
+func (s *deploymentPresetService) clearDefault(ctx context.Context, organizationId uint, clusterId *uint, exceptId uint) error {
+	return s.whereScope(s.getBaseDB(ctx), organizationId, clusterId).
+		Where("is_default = ?", true).
+		Where("id != ?", exceptId).
+		Updates(map[string]interface{}{
+			"is_default": false,
+		}).Error
+}
+
+func (s *deploymentPresetService) Create(ctx context.Context, opt CreateDeploymentPresetOption) (preset *models.DeploymentPreset, err error) {
+	errs := validation.IsDNS1035Label(opt.Name)
+	if len(errs) > 0 {
+		err = errors.New(strings.Join(errs, ";"))
+		return
+	}
+	if opt.Config == nil {
+		err = errors.New("the config of a deployment preset cannot be empty")
+		return
+	}
+	// the kube resource fields are runtime state of a target
+	config := *opt.Config
+	config.KubeResourceUid = ""
+	config.KubeResourceVersion = ""
+
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	preset = &models.DeploymentPreset{
+		CreatorAssociate: models.CreatorAssociate{
+			CreatorId: opt.CreatorId,
+		},
+		OrganizationAssociate: models.OrganizationAssociate{
+			OrganizationId: opt.OrganizationId,
+		},
+		NullableClusterAssociate: models.NullableClusterAssociate{
+			ClusterId: opt.ClusterId,
+		},
+		Name:        opt.Name,
+		Description: opt.Description,
+		Config:      &config,
+		IsDefault:   opt.IsDefault,
+	}
+	if opt.IsDefault {
+		err = s.clearDefault(ctx, opt.OrganizationId, opt.ClusterId, 0)
+		if err != nil {
+			return
+		}
+	}
+	err = db.Create(preset).Error
+	return
+}
+
+func (s *deploymentPresetService) Update(ctx context.Context, p *models.DeploymentPreset, opt UpdateDeploymentPresetOption) (preset *models.DeploymentPreset, err error) {
+	updaters := make(map[string]interface{})
+	if opt.Description != nil {
+		updaters["description"] = *opt.Description
+		defer func() {
+			if err == nil {
+				p.Description = *opt.Description
+			}
+		}()
+	}
+	if opt.Config != nil {
+		if *opt.Config == nil {
+			err = errors.New("the config of a deployment preset cannot be empty")
+			return
+		}
+		config := **opt.Config
+		config.KubeResourceUid = ""
+		config.KubeResourceVersion = ""
+		updaters["config"] = &config
+		defer func() {
+			if err == nil {
+				p.Config = &config
+			}
+		}()
+	}
+	if opt.IsDefault != nil {
+		updaters["is_default"] = *opt.IsDefault
+		defer func() {
+			if err == nil {
+				p.IsDefault = *opt.IsDefault
+			}
+		}()
+	}
+
+	if len(updaters) == 0 {
+		return p, nil
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	if opt.IsDefault != nil && *opt.IsDefault {
+		err = s.clearDefault(ctx, p.OrganizationId, p.ClusterId, p.ID)
+		if err != nil {
+			return
+		}
+	}
+	err = s.getBaseDB(ctx).Where("id = ?", p.ID).Updates(updaters).Error
+	return p, err
+}
+
+func (s *deploymentPresetService) Get(ctx context.Context, id uint) (*models.DeploymentPreset, error) {
+	var preset models.DeploymentPreset
+	err := getBaseQuery(ctx, s).Where("id = ?", id).First(&preset).Error
+	if err != nil {
+		return nil, err
+	}
+	if preset.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &preset, nil
+}
+
+// GetByName looks the preset up in a single scope, use Resolve to fall back on the organization presets
+func (s *deploymentPresetService) GetByName(ctx context.Context, organizationId uint, clusterId *uint, name string) (*models.DeploymentPreset, error) {
+	var preset models.DeploymentPreset
+	err := s.whereScope(getBaseQuery(ctx, s), organizationId, clusterId).Where("name = ?", name).First(&preset).Error
+	if err != nil {
+		return nil, err
+	}
+	if preset.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &preset, nil
+}
+
+func (s *deploymentPresetService) List(ctx context.Context, opt ListDeploymentPresetOption) ([]*models.DeploymentPreset, uint, error) {
+	query := getBaseQuery(ctx, s)
+	if opt.OrganizationId != nil {
+		query = s.whereScope(query, *opt.OrganizationId, opt.ClusterId)
+	}
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	presets := make([]*models.DeploymentPreset, 0)
+	query = opt.BindQueryWithLimit(query)
+	query = query.Order("name ASC")
+	err = query.Find(&presets).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return presets, uint(total), err
+}
+
+func (s *deploymentPresetService) Delete(ctx context.Context, preset *models.DeploymentPreset) (*models.DeploymentPreset, error) {
+	return preset, s.getBaseDB(ctx).Unscoped().Delete(preset).Error
+}
+
+// Resolve returns the preset of the cluster with the name, or the organization preset when the cluster has none
+func (s *deploymentPresetService) Resolve(ctx context.Context, cluster *models.Cluster, name string) (*models.DeploymentPreset, error) {
+	preset, err := s.GetByName(ctx, cluster.OrganizationId, utils.UintPtr(cluster.ID), name)
+	if !utils.IsNotFound(err) {
+		return preset, err
+	}
+	preset, err = s.GetByName(ctx, cluster.OrganizationId, nil, name)
+	if utils.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "deployment preset %s not found", name)
+	}
+	return preset, err
+}
+
+// GetDefault returns the default preset of the cluster, or the default organization preset when the cluster has none
+func (s *deploymentPresetService) GetDefault(ctx context.Context, cluster *models.Cluster) (*models.DeploymentPreset, error) {
+	for _, clusterId := range []*uint{utils.UintPtr(cluster.ID), nil} {
+		var preset models.DeploymentPreset
+		err := s.whereScope(getBaseQuery(ctx, s), cluster.OrganizationId, clusterId).Where("is_default = ?", true).First(&preset).Error
+		if utils.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &preset, nil
+	}
+	return nil, consts.ErrNotFound
+}
+
+// GetDefaultConfig returns the config given to the targets of the cluster created without one,
+// the built-in config is used when neither the cluster nor the organization has a default preset
+func (s *deploymentPresetService) GetDefaultConfig(ctx context.Context, cluster *models.Cluster) (*modelschemas.DeploymentTargetConfig, error) {
+	preset, err := s.GetDefault(ctx, cluster)
+	if utils.IsNotFound(err) {
+		return DeploymentTargetService.GetDefaultConfig(), nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get default deployment preset")
+	}
+	return preset.Config.DeepCopy(), nil
+}
+
+// ResolveConfig computes the config of a target, the fields set in the config of the target are applied on top of the named preset,
+// or on top of the default config when no preset is named
+func (s *deploymentPresetService) ResolveConfig(ctx context.Context, cluster *models.Cluster, presetName string, config *modelschemas.DeploymentTargetConfig) (*modelschemas.DeploymentTargetConfig, error) {
+	if presetName == "" {
+		if config != nil {
+			return config, nil
+		}
+		return s.GetDefaultConfig(ctx, cluster)
+	}
+	preset, err := s.Resolve(ctx, cluster, presetName)
+	if err != nil {
+		return nil, err
+	}
+	return MergeDeploymentTargetConfig(preset.Config, config), nil
+}
+
+// MergeDeploymentTargetConfig returns a copy of base with the fields set in override replacing its own,
+// the envs are merged by name and the runners are merged by runner name
+func MergeDeploymentTargetConfig(base, override *modelschemas.DeploymentTargetConfig) *modelschemas.DeploymentTargetConfig {
+	res := base.DeepCopy()
+	if res == nil {
+		res = &modelschemas.DeploymentTargetConfig{}
+	}
+	if override == nil {
+		return res
+	}
+	override = override.DeepCopy()
+
+	res.KubeResourceUid = override.KubeResourceUid
+	res.KubeResourceVersion = override.KubeResourceVersion
+	if override.Resources != nil {
+		res.Resources = override.Resources
+	}
+	if override.HPAConf != nil {
+		res.HPAConf = override.HPAConf
+	}
+	if override.EnableIngress != nil {
+		res.EnableIngress = override.EnableIngress
+	}
+	if override.Envs != nil {
+		res.Envs = mergeEnvs(res.Envs, override.Envs)
+	}
+	if len(override.Runners) > 0 {
+		// DeepCopy shares the runners map with base
+		runners := make(map[string]modelschemas.DeploymentTargetRunnerConfig, len(res.Runners)+len(override.Runners))
+		for name, runner := range res.Runners {
+			runners[name] = runner
+		}
+		for name, runner := range override.Runners {
+			runners[name] = runner
+		}
+		res.Runners = runners
+	}
+	return res
+}
+
+func mergeEnvs(base, override *[]*modelschemas.LabelItemSchema) *[]*modelschemas.LabelItemSchema {
+	if base == nil {
+		return override
+	}
+	envs := make([]*modelschemas.LabelItemSchema, 0, len(*base)+len(*override))
+	indexes := make(map[string]int, len(*base))
+	for _, env := range *base {
+		indexes[env.Key] = len(envs)
+		envs = append(envs, env)
+	}
+	for _, env := range *override {
+		if idx, ok := indexes[env.Key]; ok {
+			envs[idx] = env
+			continue
+		}
+		indexes[env.Key] = len(envs)
+		envs = append(envs, env)
+	}
+	return &envs
+}
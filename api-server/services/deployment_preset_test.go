@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+
+	"k8s.io/utils/pointer"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+)
+
+func TestMergeDeploymentTargetConfig(t *testing.T) {
+	preset := &modelschemas.DeploymentTargetConfig{
+		Resources: &modelschemas.DeploymentTargetResources{
+			Requests: &modelschemas.DeploymentTargetResourceItem{CPU: "4", Memory: "16G"},
+		},
+		HPAConf: &modelschemas.DeploymentTargetHPAConf{
+			MinReplicas: pointer.Int32Ptr(1),
+			MaxReplicas: pointer.Int32Ptr(3),
+		},
+		Envs: &[]*modelschemas.LabelItemSchema{
+			{Key: "LOG_LEVEL", Value: "info"},
+			{Key: "WORKERS", Value: "4"},
+		},
+		Runners: map[string]modelschemas.DeploymentTargetRunnerConfig{
+			"encoder": {HPAConf: &modelschemas.DeploymentTargetHPAConf{MaxReplicas: pointer.Int32Ptr(2)}},
+		},
+	}
+
+	merged := MergeDeploymentTargetConfig(preset, nil)
+	if merged == preset || merged.Resources.Requests.CPU != "4" {
+		t.Fatal("expected a copy of the preset when the target has no config")
+	}
+
+	merged = MergeDeploymentTargetConfig(preset, &modelschemas.DeploymentTargetConfig{
+		HPAConf: &modelschemas.DeploymentTargetHPAConf{
+			MinReplicas: pointer.Int32Ptr(5),
+			MaxReplicas: pointer.Int32Ptr(5),
+		},
+		Envs: &[]*modelschemas.LabelItemSchema{
+			{Key: "WORKERS", Value: "8"},
+			{Key: "MODEL", Value: "v2"},
+		},
+		Runners: map[string]modelschemas.DeploymentTargetRunnerConfig{
+			"decoder": {},
+		},
+	})
+	if merged.Resources.Requests.Memory != "16G" {
+		t.Error("expected the resources of the preset to be kept")
+	}
+	if *merged.HPAConf.MinReplicas != 5 {
+		t.Errorf("expected the hpa conf of the target to win, got min replicas %d", *merged.HPAConf.MinReplicas)
+	}
+	envs := map[string]string{}
+	for _, env := range *merged.Envs {
+		envs[env.Key] = env.Value
+	}
+	if len(envs) != 3 || envs["LOG_LEVEL"] != "info" || envs["WORKERS"] != "8" || envs["MODEL"] != "v2" {
+		t.Errorf("unexpected merged envs %v", envs)
+	}
+	if _, ok := merged.Runners["encoder"]; !ok || len(merged.Runners) != 2 {
+		t.Errorf("expected the runners to be merged by name, got %v", merged.Runners)
+	}
+	if len(*preset.Envs) != 2 || len(preset.Runners) != 1 {
+		t.Error("the preset must not be modified")
+	}
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+// ApplyDeploymentPromotionOverrides returns a copy of the config with the overrides of the promotion applied
+func ApplyDeploymentPromotionOverrides(config *modelschemas.DeploymentTargetConfig, overrides *schemas.DeploymentPromotionOverridesSchema) *modelschemas.DeploymentTargetConfig {
+	if overrides == nil {
+		return config.DeepCopy()
+	}
+	res := MergeDeploymentTargetConfig(config, &modelschemas.DeploymentTargetConfig{
+		Resources: overrides.Resources,
+		Envs:      overrides.Envs,
+	})
+	if overrides.MinReplicas != nil || overrides.MaxReplicas != nil {
+		hpaConf := res.HPAConf.DeepCopy()
+		if hpaConf == nil {
+			hpaConf = &modelschemas.DeploymentTargetHPAConf{}
+		}
+		if overrides.MinReplicas != nil {
+			hpaConf.MinReplicas = overrides.MinReplicas
+		}
+		if overrides.MaxReplicas != nil {
+			hpaConf.MaxReplicas = overrides.MaxReplicas
+		}
+		res.HPAConf = hpaConf
+	}
+	return res
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	"k8s.io/utils/pointer"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+func TestApplyDeploymentPromotionOverrides(t *testing.T) {
+	config := &modelschemas.DeploymentTargetConfig{
+		Resources: &modelschemas.DeploymentTargetResources{
+			Requests: &modelschemas.DeploymentTargetResourceItem{CPU: "500m", Memory: "1G"},
+		},
+		HPAConf: &modelschemas.DeploymentTargetHPAConf{
+			CPU:         pointer.Int32Ptr(80),
+			MinReplicas: pointer.Int32Ptr(1),
+			MaxReplicas: pointer.Int32Ptr(2),
+		},
+		Envs: &[]*modelschemas.LabelItemSchema{
+			{Key: "LOG_LEVEL", Value: "debug"},
+			{Key: "MODEL", Value: "v2"},
+		},
+	}
+
+	promoted := ApplyDeploymentPromotionOverrides(config, &schemas.DeploymentPromotionOverridesSchema{
+		Resources: &modelschemas.DeploymentTargetResources{
+			Requests: &modelschemas.DeploymentTargetResourceItem{CPU: "4", Memory: "16G"},
+		},
+		Envs: &[]*modelschemas.LabelItemSchema{
+			{Key: "LOG_LEVEL", Value: "info"},
+		},
+		MaxReplicas: pointer.Int32Ptr(10),
+	})
+	if promoted.Resources.Requests.CPU != "4" {
+		t.Error("expected the resources to be overridden")
+	}
+	if *promoted.HPAConf.MinReplicas != 1 || *promoted.HPAConf.MaxReplicas != 10 || *promoted.HPAConf.CPU != 80 {
+		t.Errorf("expected only the max replicas to be overridden, got %+v", promoted.HPAConf)
+	}
+	envs := map[string]string{}
+	for _, env := range *promoted.Envs {
+		envs[env.Key] = env.Value
+	}
+	if len(envs) != 2 || envs["LOG_LEVEL"] != "info" || envs["MODEL"] != "v2" {
+		t.Errorf("unexpected promoted envs %v", envs)
+	}
+	if *config.HPAConf.MaxReplicas != 2 || (*config.Envs)[0].Value != "debug" {
+		t.Error("expected the source config to be left untouched")
+	}
+}
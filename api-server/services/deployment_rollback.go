@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type RollbackDeploymentRevisionOption struct {
+	CreatorId    uint
+	ApiTokenName string
+}
+
+// listRollbackTargets returns the deployment targets of a previous revision with the uid of the active revision they replace,
+// the bentos of all of them must still exist
+func (s *deploymentRevisionService) listRollbackTargets(ctx context.Context, deployment *models.Deployment, deploymentRevision *models.DeploymentRevision) (deploymentTargets []*models.DeploymentTarget, fromDeploymentRevisionUid string, err error) {
+	status := modelschemas.DeploymentRevisionStatusActive
+	activeDeploymentRevisions, _, err := s.List(ctx, ListDeploymentRevisionOption{
+		DeploymentId: utils.UintPtr(deployment.ID),
+		Status:       &status,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "list active deployment revisions")
+		return
+	}
+	for _, activeDeploymentRevision := range activeDeploymentRevisions {
+		if activeDeploymentRevision.ID == deploymentRevision.ID {
+			err = errors.Errorf("deployment revision %s is already active", deploymentRevision.Uid)
+			return
+		}
+		fromDeploymentRevisionUid = activeDeploymentRevision.Uid
+	}
+
+	deploymentTargets, _, err = DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		DeploymentRevisionId: utils.UintPtr(deploymentRevision.ID),
+	})
+	if err != nil {
+		err = errors.Wrap(err, "list deployment targets")
+		return
+	}
+	if len(deploymentTargets) == 0 {
+		err = errors.Errorf("deployment revision %s has no deployment targets", deploymentRevision.Uid)
+		return
+	}
+	for _, deploymentTarget := range deploymentTargets {
+		_, err = BentoService.GetAssociatedBento(ctx, deploymentTarget)
+		if err != nil {
+			err = errors.Wrapf(err, "get the bento of deployment target %s, it may have been deleted", deploymentTarget.Uid)
+			return
+		}
+	}
+	return
+}
+
+// GetRollbackTargets renders the deployment targets of a previous revision as the targets of a deployment request,
+// a rollback on a protected cluster is requested with them
+func (s *deploymentRevisionService) GetRollbackTargets(ctx context.Context, deploymentRevision *models.DeploymentRevision) ([]*schemas.CreateDeploymentTargetSchema, error) {
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentRevision)
+	if err != nil {
+		return nil, err
+	}
+	deploymentTargets, _, err := s.listRollbackTargets(ctx, deployment, deploymentRevision)
+	if err != nil {
+		return nil, err
+	}
+	return DeploymentTargetService.ToCreateDeploymentTargetSchemas(ctx, deploymentTargets)
+}
+
+// Rollback clones the deployment targets of a previous revision into a new active revision and deploys it
+func (s *deploymentRevisionService) Rollback(ctx context.Context, deploymentRevision *models.DeploymentRevision, opt RollbackDeploymentRevisionOption) (newDeploymentRevision *models.DeploymentRevision, err error) {
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentRevision)
+	if err != nil {
+		return
+	}
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+	if err != nil {
+		return
+	}
+	deploymentTargets, fromDeploymentRevisionUid, err := s.listRollbackTargets(ctx, deployment, deploymentRevision)
+	if err != nil {
+		return
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	newDeploymentRevision, err = s.Create(ctx, CreateDeploymentRevisionOption{
+		CreatorId:    opt.CreatorId,
+		DeploymentId: deployment.ID,
+		Status:       modelschemas.DeploymentRevisionStatusActive,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "create deployment revision")
+		return
+	}
+
+	newDeploymentTargets := make([]*models.DeploymentTarget, 0, len(deploymentTargets))
+	for _, deploymentTarget := range deploymentTargets {
+		var config *modelschemas.DeploymentTargetConfig
+		if deploymentTarget.Config != nil {
+			config_ := *deploymentTarget.Config
+			// the kube resource belongs to the revision being replaced
+			config_.KubeResourceUid = ""
+			config_.KubeResourceVersion = ""
+			config = &config_
+		}
+		var newDeploymentTarget *models.DeploymentTarget
+		newDeploymentTarget, err = DeploymentTargetService.Create(ctx, CreateDeploymentTargetOption{
+			CreatorId:            opt.CreatorId,
+			DeploymentId:         deployment.ID,
+			DeploymentRevisionId: newDeploymentRevision.ID,
+			BentoId:              deploymentTarget.BentoId,
+			Type:                 deploymentTarget.Type,
+			CanaryRules:          deploymentTarget.CanaryRules,
+			Config:               config,
+			Ingress:              deploymentTarget.Ingress,
+		})
+		if err != nil {
+			err = errors.Wrap(err, "create deployment target")
+			return
+		}
+		newDeploymentTargets = append(newDeploymentTargets, newDeploymentTarget)
+	}
+
+	err = s.Deploy(ctx, newDeploymentRevision, newDeploymentTargets, false)
+	if err != nil {
+		err = errors.Wrap(err, "deploy deployment revision")
+		return
+	}
+
+	operationName := fmt.Sprintf("rolled back to revision %s", deploymentRevision.Uid)
+	if fromDeploymentRevisionUid != "" {
+		operationName = fmt.Sprintf("rolled back from revision %s to revision %s", fromDeploymentRevisionUid, deploymentRevision.Uid)
+	}
+	_, err = EventService.Create(ctx, CreateEventOption{
+		CreatorId:      opt.CreatorId,
+		ApiTokenName:   opt.ApiTokenName,
+		OrganizationId: &cluster.OrganizationId,
+		ResourceType:   modelschemas.ResourceTypeDeployment,
+		ResourceId:     deployment.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  operationName,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "create event")
+	}
+	return
+}
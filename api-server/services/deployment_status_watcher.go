@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	listerBatchV1 "k8s.io/client-go/listers/batch/v1"
+	listerBatchV1beta1 "k8s.io/client-go/listers/batch/v1beta1"
+	listerCoreV1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	commonconsts "github.com/bentoml/yatai-common/consts"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+var bentoDeploymentGroupVersionResource = schema.GroupVersionResource{
+	Group:    "serving.yatai.ai",
+	Version:  "v1alpha2",
+	Resource: "bentodeployments",
+}
+
+const (
+	// deploymentStatusWatcherClustersSyncPeriod is how often the watcher picks up added, removed and reconfigured clusters
+	deploymentStatusWatcherClustersSyncPeriod = time.Minute
+	// deploymentStatusWatcherResyncPeriod replays every watched object as a safety net against missed events
+	deploymentStatusWatcherResyncPeriod = 10 * time.Minute
+	// deploymentStatusWatcherDebounce coalesces the bursts of events a rollout produces into a single sync
+	deploymentStatusWatcherDebounce = time.Second
+	deploymentStatusWatcherWorkers  = 8
+	deploymentStatusWatcherRetries  = 5
+)
+
+type deploymentStatusKey struct {
+	clusterId uint
+	namespace string
+	name      string
+}
+
+// jobStatusKey is queued next to the deployment status keys, the runs of the jobs are watched by the same cluster watches
+type jobStatusKey struct {
+	clusterId uint
+	namespace string
+	name      string
+}
+
+type clusterStatusWatch struct {
+	kubeConfig    string
+	cancel        context.CancelFunc
+	podLister     listerCoreV1.PodLister
+	jobLister     listerBatchV1.JobLister
+	cronJobLister listerBatchV1beta1.CronJobLister
+	jobPodLister  listerCoreV1.PodLister
+}
+
+// deploymentStatusWatcher keeps deployment.status up to date by watching the BentoDeployments,
+// the Deployments and the Pods of every cluster and syncing a deployment as soon as one of its objects changes.
+// It keeps job.status up to date the same way from the Jobs, the CronJobs and the Pods of the yatai jobs
+type deploymentStatusWatcher struct {
+	mu      sync.RWMutex
+	watches map[uint]*clusterStatusWatch
+	queue   workqueue.RateLimitingInterface
+}
+
+var DeploymentStatusWatcher = deploymentStatusWatcher{}
+
+// Run blocks until the context is done
+func (w *deploymentStatusWatcher) Run(ctx context.Context) {
+	logger := logrus.WithField("watcher", "deployment status")
+
+	w.mu.Lock()
+	w.watches = make(map[uint]*clusterStatusWatch)
+	w.queue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "deployment status")
+	w.mu.Unlock()
+	defer w.queue.ShutDown()
+
+	for i := 0; i < deploymentStatusWatcherWorkers; i++ {
+		go func() {
+			for w.processNextKey(ctx) {
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(deploymentStatusWatcherClustersSyncPeriod)
+	defer ticker.Stop()
+	for {
+		if err := w.syncClusters(ctx); err != nil {
+			logger.Errorf("sync watched clusters: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			w.mu.Lock()
+			for clusterId, watch := range w.watches {
+				watch.cancel()
+				delete(w.watches, clusterId)
+			}
+			w.mu.Unlock()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// syncClusters starts watching the new clusters, restarts the watches of the clusters whose kubeconfig changed
+// and stops watching the deleted clusters
+func (w *deploymentStatusWatcher) syncClusters(ctx context.Context) error {
+	logger := logrus.WithField("watcher", "deployment status")
+
+	clusters, _, err := ClusterService.List(ctx, ListClusterOption{})
+	if err != nil {
+		return errors.Wrap(err, "list clusters")
+	}
+
+	seen := make(map[uint]struct{}, len(clusters))
+	for _, cluster := range clusters {
+		seen[cluster.ID] = struct{}{}
+
+		w.mu.RLock()
+		watch, ok := w.watches[cluster.ID]
+		w.mu.RUnlock()
+		if ok && watch.kubeConfig == cluster.KubeConfig {
+			continue
+		}
+		if ok {
+			logger.Infof("kubeconfig of cluster %s changed, restarting its watch", cluster.Name)
+			watch.cancel()
+		}
+
+		newWatch, err := w.watchCluster(ctx, cluster)
+		w.mu.Lock()
+		if err != nil {
+			delete(w.watches, cluster.ID)
+		} else {
+			w.watches[cluster.ID] = newWatch
+		}
+		w.mu.Unlock()
+		if err != nil {
+			logger.Errorf("watch cluster %s: %s", cluster.Name, err.Error())
+			continue
+		}
+		logger.Infof("watching the deployments of cluster %s", cluster.Name)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for clusterId, watch := range w.watches {
+		if _, ok := seen[clusterId]; !ok {
+			watch.cancel()
+			delete(w.watches, clusterId)
+		}
+	}
+	return nil
+}
+
+func (w *deploymentStatusWatcher) watchCluster(ctx context.Context, cluster *models.Cluster) (*clusterStatusWatch, error) {
+	clientSet, restConfig, err := ClusterService.GetKubeCliSet(ctx, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "get kube cli set")
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+
+	// only the objects that belong to a yatai deployment are watched
+	factory := informers.NewSharedInformerFactoryWithOptions(clientSet, deploymentStatusWatcherResyncPeriod, informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+		opts.LabelSelector = commonconsts.KubeLabelYataiBentoDeployment
+	}))
+	podInformer := factory.Core().V1().Pods()
+	podInformer.Informer().AddEventHandler(w.newEventHandler(cluster.ID, w.getKeyByLabel))
+	factory.Apps().V1().Deployments().Informer().AddEventHandler(w.newEventHandler(cluster.ID, w.getKeyByLabel))
+	factory.Start(watchCtx.Done())
+
+	jobFactory := informers.NewSharedInformerFactoryWithOptions(clientSet, deploymentStatusWatcherResyncPeriod, informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+		opts.LabelSelector = consts.KubeLabelYataiJob
+	}))
+	jobInformer := jobFactory.Batch().V1().Jobs()
+	jobInformer.Informer().AddEventHandler(w.newEventHandler(cluster.ID, w.getJobKey))
+	cronJobInformer := jobFactory.Batch().V1beta1().CronJobs()
+	cronJobInformer.Informer().AddEventHandler(w.newEventHandler(cluster.ID, w.getJobKey))
+	jobPodInformer := jobFactory.Core().V1().Pods()
+	jobPodInformer.Informer().AddEventHandler(w.newEventHandler(cluster.ID, w.getJobKey))
+	jobFactory.Start(watchCtx.Done())
+
+	_, err = clientSet.Discovery().ServerResourcesForGroupVersion(bentoDeploymentGroupVersionResource.GroupVersion().String())
+	if err != nil {
+		// the pods are enough to compute the status, the yatai-deployment operator may not be installed yet
+		logrus.WithField("watcher", "deployment status").Warnf("cluster %s does not serve %s, its BentoDeployments are not watched: %s", cluster.Name, bentoDeploymentGroupVersionResource.GroupVersion().String(), err.Error())
+	} else {
+		dynamicCli, err := dynamic.NewForConfig(restConfig)
+		if err != nil {
+			cancel()
+			return nil, errors.Wrap(err, "get kube dynamic cli")
+		}
+		dynamicFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicCli, deploymentStatusWatcherResyncPeriod)
+		dynamicFactory.ForResource(bentoDeploymentGroupVersionResource).Informer().AddEventHandler(w.newEventHandler(cluster.ID, w.getKeyByName))
+		dynamicFactory.Start(watchCtx.Done())
+	}
+
+	syncCtx, syncCancel := context.WithTimeout(watchCtx, informerSyncTimeout)
+	defer syncCancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), podInformer.Informer().HasSynced) {
+		cancel()
+		return nil, errors.New("timed out waiting for the pod informer to sync")
+	}
+	if !cache.WaitForCacheSync(syncCtx.Done(), jobInformer.Informer().HasSynced, cronJobInformer.Informer().HasSynced, jobPodInformer.Informer().HasSynced) {
+		cancel()
+		return nil, errors.New("timed out waiting for the job informers to sync")
+	}
+
+	return &clusterStatusWatch{
+		kubeConfig:    cluster.KubeConfig,
+		cancel:        cancel,
+		podLister:     podInformer.Lister(),
+		jobLister:     jobInformer.Lister(),
+		cronJobLister: cronJobInformer.Lister(),
+		jobPodLister:  jobPodInformer.Lister(),
+	}, nil
+}
+
+func (w *deploymentStatusWatcher) getKeyByLabel(clusterId uint, obj metav1.Object) (interface{}, bool) {
+	name, ok := obj.GetLabels()[commonconsts.KubeLabelYataiBentoDeployment]
+	if !ok || name == "" {
+		return nil, false
+	}
+	return deploymentStatusKey{clusterId: clusterId, namespace: obj.GetNamespace(), name: name}, true
+}
+
+func (w *deploymentStatusWatcher) getKeyByName(clusterId uint, obj metav1.Object) (interface{}, bool) {
+	return deploymentStatusKey{clusterId: clusterId, namespace: obj.GetNamespace(), name: obj.GetName()}, true
+}
+
+func (w *deploymentStatusWatcher) getJobKey(clusterId uint, obj metav1.Object) (interface{}, bool) {
+	name, ok := obj.GetLabels()[consts.KubeLabelYataiJob]
+	if !ok || name == "" {
+		return nil, false
+	}
+	return jobStatusKey{clusterId: clusterId, namespace: obj.GetNamespace(), name: name}, true
+}
+
+func (w *deploymentStatusWatcher) newEventHandler(clusterId uint, getKey func(uint, metav1.Object) (interface{}, bool)) cache.ResourceEventHandler {
+	enqueue := func(obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		object, err := meta.Accessor(obj)
+		if err != nil {
+			return
+		}
+		key, ok := getKey(clusterId, object)
+		if !ok {
+			return
+		}
+		w.queue.AddAfter(key, deploymentStatusWatcherDebounce)
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
+		UpdateFunc: func(_, newObj interface{}) {
+			enqueue(newObj)
+		},
+		DeleteFunc: enqueue,
+	}
+}
+
+func (w *deploymentStatusWatcher) processNextKey(ctx context.Context) bool {
+	item, shutdown := w.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer w.queue.Done(item)
+
+	var err error
+	var resource string
+	switch key := item.(type) {
+	case deploymentStatusKey:
+		err = w.syncDeploymentStatus(ctx, key)
+		resource = fmt.Sprintf("deployment %s/%s", key.namespace, key.name)
+	case jobStatusKey:
+		err = w.syncJobStatus(ctx, key)
+		resource = fmt.Sprintf("job %s/%s", key.namespace, key.name)
+	}
+	if err == nil {
+		w.queue.Forget(item)
+		return true
+	}
+	if w.queue.NumRequeues(item) < deploymentStatusWatcherRetries {
+		w.queue.AddRateLimited(item)
+		return true
+	}
+	w.queue.Forget(item)
+	logrus.WithField("watcher", "deployment status").Errorf("sync the status of %s: %s", resource, err.Error())
+	return true
+}
+
+func (w *deploymentStatusWatcher) syncDeploymentStatus(ctx context.Context, key deploymentStatusKey) error {
+	w.mu.RLock()
+	watch, ok := w.watches[key.clusterId]
+	w.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	deployment, err := DeploymentService.GetByName(ctx, key.clusterId, key.namespace, key.name)
+	if utils.IsNotFound(err) {
+		// the object is not managed by this yatai
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "get deployment")
+	}
+	_, err = DeploymentService.SyncStatusWithPodLister(ctx, deployment, watch.podLister.Pods(key.namespace))
+	return err
+}
+
+func (w *deploymentStatusWatcher) syncJobStatus(ctx context.Context, key jobStatusKey) error {
+	w.mu.RLock()
+	watch, ok := w.watches[key.clusterId]
+	w.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	job, err := JobService.GetByName(ctx, key.clusterId, key.namespace, key.name)
+	if utils.IsNotFound(err) {
+		// the job was deleted, its runs are garbage collected
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "get job")
+	}
+	_, err = JobService.SyncStatusWithListers(ctx, job, watch.jobLister.Jobs(key.namespace), watch.cronJobLister.CronJobs(key.namespace), watch.jobPodLister.Pods(key.namespace))
+	return err
+}
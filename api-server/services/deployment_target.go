@@ -13,6 +13,7 @@ import (
 	commonconsts "github.com/bentoml/yatai-common/consts"
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/common/consts"
 )
 
@@ -32,10 +33,12 @@ type CreateDeploymentTargetOption struct {
 	Type                 modelschemas.DeploymentTargetType
 	CanaryRules          *modelschemas.DeploymentTargetCanaryRules
 	Config               *modelschemas.DeploymentTargetConfig
+	Ingress              *schemas.DeploymentTargetIngressSchema
 }
 
 type UpdateDeploymentTargetOption struct {
-	Config **modelschemas.DeploymentTargetConfig
+	Config      **modelschemas.DeploymentTargetConfig
+	CanaryRules **modelschemas.DeploymentTargetCanaryRules
 }
 
 type ListDeploymentTargetOption struct {
@@ -45,28 +48,47 @@ type ListDeploymentTargetOption struct {
 	DeploymentIds            *[]uint
 	DeploymentRevisionId     *uint
 	DeploymentRevisionIds    *[]uint
+	ClusterId                *uint
+	BentoIds                 *[]uint
 	Type                     *modelschemas.DeploymentTargetType
 }
 
-func (*deploymentTargetService) Create(ctx context.Context, opt CreateDeploymentTargetOption) (*models.DeploymentTarget, error) {
-	if opt.Config == nil {
-		opt.Config = &modelschemas.DeploymentTargetConfig{
-			Resources: &modelschemas.DeploymentTargetResources{
-				Requests: &modelschemas.DeploymentTargetResourceItem{
-					CPU:    "500m",
-					Memory: "1G",
-				},
-				Limits: &modelschemas.DeploymentTargetResourceItem{
-					CPU:    "1000m",
-					Memory: "2G",
-				},
+// GetDefaultConfig returns the built-in config, it is given to the targets created without one
+// when neither their cluster nor their organization has a default deployment preset
+func (*deploymentTargetService) GetDefaultConfig() *modelschemas.DeploymentTargetConfig {
+	return &modelschemas.DeploymentTargetConfig{
+		Resources: &modelschemas.DeploymentTargetResources{
+			Requests: &modelschemas.DeploymentTargetResourceItem{
+				CPU:    "500m",
+				Memory: "1G",
 			},
-			HPAConf: &modelschemas.DeploymentTargetHPAConf{
-				CPU:         pointer.Int32Ptr(80),
-				GPU:         pointer.Int32Ptr(80),
-				MinReplicas: pointer.Int32Ptr(2),
-				MaxReplicas: pointer.Int32Ptr(10),
+			Limits: &modelschemas.DeploymentTargetResourceItem{
+				CPU:    "1000m",
+				Memory: "2G",
 			},
+		},
+		HPAConf: &modelschemas.DeploymentTargetHPAConf{
+			CPU:         pointer.Int32Ptr(80),
+			GPU:         pointer.Int32Ptr(80),
+			MinReplicas: pointer.Int32Ptr(2),
+			MaxReplicas: pointer.Int32Ptr(10),
+		},
+	}
+}
+
+func (s *deploymentTargetService) Create(ctx context.Context, opt CreateDeploymentTargetOption) (*models.DeploymentTarget, error) {
+	if opt.Config == nil {
+		deployment, err := DeploymentService.Get(ctx, opt.DeploymentId)
+		if err != nil {
+			return nil, errors.Wrap(err, "get deployment")
+		}
+		cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+		if err != nil {
+			return nil, errors.Wrap(err, "get deployment cluster")
+		}
+		opt.Config, err = DeploymentPresetService.GetDefaultConfig(ctx, cluster)
+		if err != nil {
+			return nil, err
 		}
 	}
 	deploymentTarget := models.DeploymentTarget{
@@ -85,6 +107,7 @@ func (*deploymentTargetService) Create(ctx context.Context, opt CreateDeployment
 		Type:        opt.Type,
 		CanaryRules: opt.CanaryRules,
 		Config:      opt.Config,
+		Ingress:     opt.Ingress,
 	}
 	err := mustGetSession(ctx).Create(&deploymentTarget).Error
 	if err != nil {
@@ -93,6 +116,54 @@ func (*deploymentTargetService) Create(ctx context.Context, opt CreateDeployment
 	return &deploymentTarget, err
 }
 
+// ValidateIngresses checks the ingresses of the targets of the deployment, a hostname can only be served by one deployment of the cluster
+func (s *deploymentTargetService) ValidateIngresses(ctx context.Context, deployment *models.Deployment, targets []*schemas.CreateDeploymentTargetSchema) error {
+	hostnames := make(map[string]struct{})
+	for _, target := range targets {
+		if target.Ingress == nil {
+			continue
+		}
+		if target.Type == modelschemas.DeploymentTargetTypeCanary {
+			return errors.Errorf("the canary target of bento %s:%s cannot have an ingress, it is served on the hostnames of the stable target", target.BentoRepository, target.Bento)
+		}
+		if err := target.Ingress.Validate(); err != nil {
+			return errors.Wrapf(err, "the ingress of the target of bento %s:%s", target.BentoRepository, target.Bento)
+		}
+		for _, hostname := range target.Ingress.Hostnames {
+			hostnames[hostname] = struct{}{}
+		}
+	}
+	if len(hostnames) == 0 {
+		return nil
+	}
+
+	var deploymentTargets []*models.DeploymentTarget
+	err := getBaseQuery(ctx, s).
+		Joins("INNER JOIN deployment_revision ON deployment_revision.id = deployment_target.deployment_revision_id and deployment_revision.status = ?", modelschemas.DeploymentRevisionStatusActive).
+		Joins("INNER JOIN deployment ON deployment.id = deployment_target.deployment_id").
+		Where("deployment.cluster_id = ?", deployment.ClusterId).
+		Where("deployment.id != ?", deployment.ID).
+		Where("deployment.status != ?", modelschemas.DeploymentStatusTerminated).
+		Where("deployment_target.ingress IS NOT NULL").
+		Find(&deploymentTargets).Error
+	if err != nil {
+		return errors.Wrap(err, "list the deployment targets with an ingress")
+	}
+	for _, deploymentTarget := range deploymentTargets {
+		for _, hostname := range deploymentTarget.Ingress.Hostnames {
+			if _, ok := hostnames[hostname]; !ok {
+				continue
+			}
+			other, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentTarget)
+			if err != nil {
+				return errors.Wrap(err, "get associated deployment")
+			}
+			return errors.Errorf("the hostname %s is already used by deployment %s in namespace %s", hostname, other.Name, other.KubeNamespace)
+		}
+	}
+	return nil
+}
+
 func (s *deploymentTargetService) Get(ctx context.Context, id uint) (*models.DeploymentTarget, error) {
 	var deploymentTarget models.DeploymentTarget
 	err := getBaseQuery(ctx, s).Where("id = ?", id).First(&deploymentTarget).Error
@@ -134,6 +205,12 @@ func (s *deploymentTargetService) List(ctx context.Context, opt ListDeploymentTa
 	if opt.DeploymentRevisionIds != nil {
 		query = query.Where("deployment_target.deployment_revision_id in (?)", *opt.DeploymentRevisionIds)
 	}
+	if opt.ClusterId != nil {
+		query = query.Joins("INNER JOIN deployment ON deployment.id = deployment_target.deployment_id and deployment.cluster_id = ?", *opt.ClusterId)
+	}
+	if opt.BentoIds != nil {
+		query = query.Where("deployment_target.bento_id in (?)", *opt.BentoIds)
+	}
 	if opt.Type != nil {
 		query = query.Where("deployment_target.type = ?", *opt.Type)
 	}
@@ -209,6 +286,15 @@ func (s *deploymentTargetService) Update(ctx context.Context, b *models.Deployme
 		}()
 	}
 
+	if opt.CanaryRules != nil {
+		updaters["canary_rules"] = *opt.CanaryRules
+		defer func() {
+			if err == nil {
+				b.CanaryRules = *opt.CanaryRules
+			}
+		}()
+	}
+
 	if len(updaters) == 0 {
 		return b, nil
 	}
@@ -221,15 +307,55 @@ func (s *deploymentTargetService) Update(ctx context.Context, b *models.Deployme
 func (s *deploymentTargetService) Deploy(ctx context.Context, deploymentTarget *models.DeploymentTarget, deployOption *models.DeployOption) (deploymentTarget_ *models.DeploymentTarget, err error) {
 	deploymentTarget_ = deploymentTarget
 
-	_, err = KubeBentoDeploymentService.Deploy(ctx, deploymentTarget, deployOption)
+	kubeBentoDeployment, err := KubeBentoDeploymentService.Deploy(ctx, deploymentTarget, deployOption)
 	if err != nil {
 		err = errors.Wrap(err, "failed to deploy kube bento deployment")
 		return
 	}
 
+	err = KubeIngressService.DeployCustomKubeIngress(ctx, deploymentTarget, kubeBentoDeployment)
+	if err != nil {
+		err = errors.Wrap(err, "failed to deploy the ingress of the custom hostnames")
+		return
+	}
+
 	return
 }
 
+// ToCreateDeploymentTargetSchemas renders deployment targets as the targets of a deployment request, the change requests
+// of a protected cluster redeploy them once they are approved
+func (s *deploymentTargetService) ToCreateDeploymentTargetSchemas(ctx context.Context, deploymentTargets []*models.DeploymentTarget) ([]*schemas.CreateDeploymentTargetSchema, error) {
+	res := make([]*schemas.CreateDeploymentTargetSchema, 0, len(deploymentTargets))
+	for _, deploymentTarget := range deploymentTargets {
+		bento, err := BentoService.GetAssociatedBento(ctx, deploymentTarget)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get the bento of deployment target %s", deploymentTarget.Uid)
+		}
+		bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get the bento repository of deployment target %s", deploymentTarget.Uid)
+		}
+		config := deploymentTarget.Config
+		if config != nil {
+			// the kube resource belongs to the revision being replaced
+			config_ := *config
+			config_.KubeResourceUid = ""
+			config_.KubeResourceVersion = ""
+			config = &config_
+		}
+		target := &schemas.CreateDeploymentTargetSchema{
+			Ingress: deploymentTarget.Ingress,
+		}
+		target.Type = deploymentTarget.Type
+		target.BentoRepository = bentoRepository.Name
+		target.Bento = bento.Version
+		target.CanaryRules = deploymentTarget.CanaryRules
+		target.Config = config
+		res = append(res, target)
+	}
+	return res, nil
+}
+
 func (s *deploymentTargetService) GetKubeCliSet(ctx context.Context, deploymentTarget *models.DeploymentTarget) (kubeCli *kubernetes.Clientset, restConfig *rest.Config, err error) {
 	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentTarget)
 	if err != nil {
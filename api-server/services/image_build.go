@@ -0,0 +1,524 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonconsts "github.com/bentoml/yatai-common/consts"
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+const (
+	imageBuildMaxAttempts      = 3
+	imageBuildRetryBaseBackoff = time.Minute
+	imageBuildRetryMaxBackoff  = 30 * time.Minute
+	imageBuildLogsTailLines    = int64(1000)
+)
+
+type imageBuildService struct{}
+
+var ImageBuildService = imageBuildService{}
+
+func (s *imageBuildService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.ImageBuild{})
+}
+
+type CreateImageBuildOption struct {
+	CreatorId uint
+	// Resource is the bento being built
+	Resource  models.IResource
+	Attempt   uint
+	Status    modelschemas.ImageBuildStatus
+	PodName   string
+	StartedAt *time.Time
+}
+
+type UpdateImageBuildOption struct {
+	Status        *modelschemas.ImageBuildStatus
+	PodName       *string
+	FailureReason *string
+	Logs          *string
+	StartedAt     **time.Time
+	FinishedAt    **time.Time
+	NextRetryAt   **time.Time
+}
+
+type ListImageBuildOption struct {
+	BaseListOption
+	ResourceType    *modelschemas.ResourceType
+	ResourceId      *uint
+	Status          *modelschemas.ImageBuildStatus
+	NextRetryBefore *time.Time
+}
+
+type RecordImageBuildStatusOption struct {
+	CreatorId     uint
+	Bento         *models.Bento
+	Status        modelschemas.ImageBuildStatus
+	PodName       *string
+	FailureReason *string
+}
+
+func (s *imageBuildService) Create(ctx context.Context, opt CreateImageBuildOption) (*models.ImageBuild, error) {
+	build := models.ImageBuild{
+		CreatorAssociate: models.CreatorAssociate{
+			CreatorId: opt.CreatorId,
+		},
+		ResourceType: opt.Resource.GetResourceType(),
+		ResourceId:   opt.Resource.GetId(),
+		Attempt:      opt.Attempt,
+		Status:       opt.Status,
+		PodName:      opt.PodName,
+		StartedAt:    opt.StartedAt,
+	}
+	err := mustGetSession(ctx).Create(&build).Error
+	if err != nil {
+		return nil, err
+	}
+	return &build, nil
+}
+
+func (s *imageBuildService) Update(ctx context.Context, build *models.ImageBuild, opt UpdateImageBuildOption) (*models.ImageBuild, error) {
+	var err error
+	updaters := make(map[string]interface{})
+	if opt.Status != nil {
+		updaters["status"] = *opt.Status
+		defer func() {
+			if err == nil {
+				build.Status = *opt.Status
+			}
+		}()
+	}
+	if opt.PodName != nil {
+		updaters["pod_name"] = *opt.PodName
+		defer func() {
+			if err == nil {
+				build.PodName = *opt.PodName
+			}
+		}()
+	}
+	if opt.FailureReason != nil {
+		updaters["failure_reason"] = *opt.FailureReason
+		defer func() {
+			if err == nil {
+				build.FailureReason = *opt.FailureReason
+			}
+		}()
+	}
+	if opt.Logs != nil {
+		updaters["logs"] = *opt.Logs
+		defer func() {
+			if err == nil {
+				build.Logs = *opt.Logs
+			}
+		}()
+	}
+	if opt.StartedAt != nil {
+		updaters["started_at"] = *opt.StartedAt
+		defer func() {
+			if err == nil {
+				build.StartedAt = *opt.StartedAt
+			}
+		}()
+	}
+	if opt.FinishedAt != nil {
+		updaters["finished_at"] = *opt.FinishedAt
+		defer func() {
+			if err == nil {
+				build.FinishedAt = *opt.FinishedAt
+			}
+		}()
+	}
+	if opt.NextRetryAt != nil {
+		updaters["next_retry_at"] = *opt.NextRetryAt
+		defer func() {
+			if err == nil {
+				build.NextRetryAt = *opt.NextRetryAt
+			}
+		}()
+	}
+
+	if len(updaters) == 0 {
+		return build, nil
+	}
+
+	err = s.getBaseDB(ctx).Where("id = ?", build.ID).Updates(updaters).Error
+	if err != nil {
+		return nil, err
+	}
+	return build, nil
+}
+
+func (s *imageBuildService) Get(ctx context.Context, id uint) (*models.ImageBuild, error) {
+	var build models.ImageBuild
+	err := getBaseQuery(ctx, s).Where("id = ?", id).First(&build).Error
+	if err != nil {
+		return nil, err
+	}
+	if build.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &build, nil
+}
+
+func (s *imageBuildService) GetLatest(ctx context.Context, resource models.IResource) (*models.ImageBuild, error) {
+	var build models.ImageBuild
+	err := getBaseQuery(ctx, s).
+		Where("resource_type = ?", resource.GetResourceType()).
+		Where("resource_id = ?", resource.GetId()).
+		Order("id DESC").
+		First(&build).Error
+	if err != nil {
+		return nil, err
+	}
+	if build.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &build, nil
+}
+
+func (s *imageBuildService) List(ctx context.Context, opt ListImageBuildOption) ([]*models.ImageBuild, uint, error) {
+	query := getBaseQuery(ctx, s)
+	if opt.ResourceType != nil {
+		query = query.Where("resource_type = ?", *opt.ResourceType)
+	}
+	if opt.ResourceId != nil {
+		query = query.Where("resource_id = ?", *opt.ResourceId)
+	}
+	if opt.Status != nil {
+		query = query.Where("status = ?", *opt.Status)
+	}
+	if opt.NextRetryBefore != nil {
+		query = query.Where("next_retry_at < ?", *opt.NextRetryBefore)
+	}
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	builds := make([]*models.ImageBuild, 0)
+	query = opt.BindQueryWithLimit(query)
+	query = query.Order("id DESC")
+	err = query.Find(&builds).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return builds, uint(total), err
+}
+
+func (s *imageBuildService) DeleteByResource(ctx context.Context, resource models.IResource) error {
+	return s.getBaseDB(ctx).Unscoped().
+		Where("resource_type = ?", resource.GetResourceType()).
+		Where("resource_id = ?", resource.GetId()).
+		Delete(&models.ImageBuild{}).Error
+}
+
+func (s *imageBuildService) getRetryBackoff(attempt uint) time.Duration {
+	backoff := imageBuildRetryBaseBackoff
+	for i := uint(1); i < attempt; i++ {
+		backoff *= 2
+		if backoff >= imageBuildRetryMaxBackoff {
+			return imageBuildRetryMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// isFinished reports whether the build attempt has come to an end, a new status of the resource belongs to a new attempt then
+func (s *imageBuildService) isFinished(build *models.ImageBuild) bool {
+	return build.Status == modelschemas.ImageBuildStatusSuccess || build.Status == modelschemas.ImageBuildStatusFailed
+}
+
+// startNextAttempt creates the record of a new build attempt, it continues the attempts of a failed build which is waiting for a retry
+func (s *imageBuildService) startNextAttempt(ctx context.Context, creatorId uint, resource models.IResource, latest *models.ImageBuild, status modelschemas.ImageBuildStatus) (*models.ImageBuild, error) {
+	attempt := uint(1)
+	if latest != nil && latest.Status == modelschemas.ImageBuildStatusFailed && latest.NextRetryAt != nil {
+		attempt = latest.Attempt + 1
+		var nextRetryAt *time.Time
+		_, err := s.Update(ctx, latest, UpdateImageBuildOption{
+			NextRetryAt: &nextRetryAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var startedAt *time.Time
+	if status != modelschemas.ImageBuildStatusPending {
+		now := time.Now()
+		startedAt = &now
+	}
+	return s.Create(ctx, CreateImageBuildOption{
+		CreatorId: creatorId,
+		Resource:  resource,
+		Attempt:   attempt,
+		Status:    status,
+		StartedAt: startedAt,
+	})
+}
+
+// RecordStatus keeps the build records in step with the image build status reported by the image builder
+func (s *imageBuildService) RecordStatus(ctx context.Context, opt RecordImageBuildStatusOption) (build *models.ImageBuild, err error) {
+	if opt.Status == modelschemas.ImageBuildStatusPending {
+		return nil, nil
+	}
+
+	latest, err := s.GetLatest(ctx, opt.Bento)
+	isNotFound := utils.IsNotFound(err)
+	if err != nil && !isNotFound {
+		return
+	}
+	if isNotFound {
+		latest = nil
+	}
+
+	// the logs are fetched before the transaction because the builder pod can be slow to answer
+	var podName, logs string
+	if opt.PodName != nil {
+		podName = *opt.PodName
+	} else if latest != nil && !s.isFinished(latest) {
+		podName = latest.PodName
+	}
+	isFinishing := opt.Status == modelschemas.ImageBuildStatusSuccess || opt.Status == modelschemas.ImageBuildStatusFailed
+	if isFinishing {
+		podName, logs, err = s.captureBuilderPodLogs(ctx, opt.Bento, podName)
+		if err != nil {
+			logrus.Warnf("capture image builder pod logs of bento %s: %s", opt.Bento.Version, err.Error())
+			logs = fmt.Sprintf("failed to capture the image builder pod logs: %s", err.Error())
+			err = nil
+		}
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	build = latest
+	if build == nil || s.isFinished(build) {
+		build, err = s.startNextAttempt(ctx, opt.CreatorId, opt.Bento, latest, modelschemas.ImageBuildStatusBuilding)
+		if err != nil {
+			return
+		}
+	}
+
+	now := time.Now()
+	nowPtr := &now
+	updateOpt := UpdateImageBuildOption{
+		Status: &opt.Status,
+	}
+	if build.StartedAt == nil {
+		updateOpt.StartedAt = &nowPtr
+	}
+	if podName != "" {
+		updateOpt.PodName = &podName
+	}
+	if isFinishing {
+		updateOpt.FinishedAt = &nowPtr
+		updateOpt.Logs = &logs
+		updateOpt.FailureReason = opt.FailureReason
+	}
+	if opt.Status == modelschemas.ImageBuildStatusFailed && build.Attempt < imageBuildMaxAttempts {
+		nextRetryAt := now.Add(s.getRetryBackoff(build.Attempt))
+		nextRetryAtPtr := &nextRetryAt
+		updateOpt.NextRetryAt = &nextRetryAtPtr
+	}
+	build, err = s.Update(ctx, build, updateOpt)
+	return
+}
+
+// Rebuild starts a new series of build attempts, it is refused while the image is being built.
+// Only the bento images are built, the image builder of yatai-deployment has no model images to build
+func (s *imageBuildService) Rebuild(ctx context.Context, creatorId uint, bento *models.Bento) (build *models.ImageBuild, err error) {
+	latest, err := s.GetLatest(ctx, bento)
+	isNotFound := utils.IsNotFound(err)
+	if err != nil && !isNotFound {
+		return
+	}
+	if isNotFound {
+		latest = nil
+	}
+	if latest != nil && !s.isFinished(latest) {
+		err = errors.Errorf("the image of bento %s is being built", bento.Version)
+		return
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	if latest != nil && latest.NextRetryAt != nil {
+		var nextRetryAt *time.Time
+		_, err = s.Update(ctx, latest, UpdateImageBuildOption{
+			NextRetryAt: &nextRetryAt,
+		})
+		if err != nil {
+			return
+		}
+	}
+	build, err = s.Create(ctx, CreateImageBuildOption{
+		CreatorId: creatorId,
+		Resource:  bento,
+		Attempt:   1,
+		Status:    modelschemas.ImageBuildStatusPending,
+	})
+	if err != nil {
+		return
+	}
+	err = s.resetBentoImageBuildStatus(ctx, bento)
+	return
+}
+
+func (s *imageBuildService) retry(ctx context.Context, failed *models.ImageBuild) (err error) {
+	bento, err := BentoService.Get(ctx, failed.ResourceId)
+	if err != nil {
+		return
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	_, err = s.startNextAttempt(ctx, failed.CreatorId, bento, failed, modelschemas.ImageBuildStatusPending)
+	if err != nil {
+		return
+	}
+	err = s.resetBentoImageBuildStatus(ctx, bento)
+	return
+}
+
+// RetryFailedBuilds puts the images whose retry backoff has elapsed back into the image builder queue
+func (s *imageBuildService) RetryFailedBuilds(ctx context.Context) error {
+	logger := logrus.WithField("cron", "image build retry")
+
+	status := modelschemas.ImageBuildStatusFailed
+	now := time.Now()
+	builds, _, err := s.List(ctx, ListImageBuildOption{
+		ResourceType:    modelschemas.ResourceTypeBento.Ptr(),
+		Status:          &status,
+		NextRetryBefore: &now,
+	})
+	if err != nil {
+		return errors.Wrap(err, "list failed image builds")
+	}
+	for _, build := range builds {
+		if err = s.retry(ctx, build); err != nil {
+			logger.Errorf("retry image build of %s %d: %s", build.ResourceType, build.ResourceId, err.Error())
+			continue
+		}
+		logger.Infof("retrying image build of %s %d, attempt %d", build.ResourceType, build.ResourceId, build.Attempt+1)
+	}
+	return nil
+}
+
+// resetBentoImageBuildStatus marks the image as pending and unsynced so that the image builder picks it up again
+func (s *imageBuildService) resetBentoImageBuildStatus(ctx context.Context, bento *models.Bento) error {
+	status := modelschemas.ImageBuildStatusPending
+	var nilTime *time.Time
+	_, err := BentoService.Update(ctx, bento, UpdateBentoOption{
+		ImageBuildStatus:          &status,
+		ImageBuildStatusSyncingAt: &nilTime,
+		ImageBuildStatusUpdatedAt: &nilTime,
+	})
+	return err
+}
+
+func (s *imageBuildService) getImageBuilderClusterAndKubeLabels(ctx context.Context, bento *models.Bento) (*models.Cluster, map[string]string, error) {
+	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+	if err != nil {
+		return nil, nil, err
+	}
+	kubeLabels, err := BentoService.GetImageBuilderKubeLabels(ctx, bento)
+	if err != nil {
+		return nil, nil, err
+	}
+	org, err := OrganizationService.Get(ctx, bentoRepository.OrganizationId)
+	if err != nil {
+		return nil, nil, err
+	}
+	cluster, err := OrganizationService.GetMajorCluster(ctx, org)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cluster, kubeLabels, nil
+}
+
+// captureBuilderPodLogs returns the tail of the logs of every container of the builder pod,
+// the newest builder pod of the bento is used when the pod name is unknown
+func (s *imageBuildService) captureBuilderPodLogs(ctx context.Context, bento *models.Bento, podName string) (string, string, error) {
+	cluster, kubeLabels, err := s.getImageBuilderClusterAndKubeLabels(ctx, bento)
+	if err != nil {
+		return podName, "", err
+	}
+	if podName == "" {
+		pods, err := ImageBuilderService.ListImageBuilderPods(ctx, cluster, kubeLabels)
+		if err != nil {
+			return podName, "", errors.Wrap(err, "list image builder pods")
+		}
+		var newest *apiv1.Pod
+		for _, pod := range pods {
+			pod := pod
+			if newest == nil || newest.CreationTimestamp.Before(&pod.Pod.CreationTimestamp) {
+				newest = &pod.Pod
+			}
+		}
+		if newest == nil {
+			return podName, "", nil
+		}
+		podName = newest.Name
+	}
+
+	clientSet, _, err := ClusterService.GetKubeCliSet(ctx, cluster)
+	if err != nil {
+		return podName, "", err
+	}
+	podsCli := clientSet.CoreV1().Pods(commonconsts.KubeNamespaceYataiBentoImageBuilder)
+	pod, err := podsCli.Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return podName, "", errors.Wrapf(err, "get image builder pod %s", podName)
+	}
+
+	containers := make([]apiv1.Container, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	containers = append(containers, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	tailLines := imageBuildLogsTailLines
+	buf := new(bytes.Buffer)
+	for _, container := range containers {
+		fmt.Fprintf(buf, "==> %s <==\n", container.Name)
+		err = func() error {
+			rs, err := podsCli.GetLogs(podName, &apiv1.PodLogOptions{
+				Container: container.Name,
+				TailLines: &tailLines,
+			}).Stream(ctx)
+			if err != nil {
+				return err
+			}
+			defer rs.Close()
+			_, err = io.Copy(buf, rs)
+			return err
+		}()
+		if err != nil {
+			fmt.Fprintf(buf, "failed to get the logs: %s\n", err.Error())
+		}
+	}
+	return podName, buf.String(), nil
+}
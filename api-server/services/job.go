@@ -0,0 +1,1030 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tianweidut/cron"
+	"gorm.io/gorm"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	batchtypev1 "k8s.io/client-go/kubernetes/typed/batch/v1"
+	batchtypev1beta "k8s.io/client-go/kubernetes/typed/batch/v1beta1"
+	listerBatchV1 "k8s.io/client-go/listers/batch/v1"
+	listerBatchV1beta1 "k8s.io/client-go/listers/batch/v1beta1"
+	listerCoreV1 "k8s.io/client-go/listers/core/v1"
+
+	commonconsts "github.com/bentoml/yatai-common/consts"
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type jobService struct{}
+
+var JobService = jobService{}
+
+const (
+	jobContainerName = "main"
+	// the kubernetes defaults of the cron jobs, they apply to the runs of the one-off jobs as well
+	defaultJobSuccessfulRunsHistoryLimit int32 = 3
+	defaultJobFailedRunsHistoryLimit     int32 = 1
+	// kubernetes sets this label on the pods of a job
+	kubeLabelJobName = "job-name"
+)
+
+func (s *jobService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.Job{})
+}
+
+type CreateJobOption struct {
+	CreatorId     uint
+	ClusterId     uint
+	BentoId       uint
+	KubeNamespace string
+	Name          string
+	Description   string
+	Type          schemas.JobType
+	Schedule      string
+	Suspend       bool
+	Config        *schemas.JobConfigSchema
+	Labels        modelschemas.LabelItemsSchema
+}
+
+type UpdateJobOption struct {
+	Description     *string
+	BentoId         *uint
+	Schedule        *string
+	Suspend         *bool
+	Config          **schemas.JobConfigSchema
+	Status          *schemas.JobStatus
+	StatusUpdatedAt *time.Time
+	Labels          *modelschemas.LabelItemsSchema
+}
+
+type ListJobOption struct {
+	BaseListOption
+	ClusterId     *uint
+	KubeNamespace *string
+	BentoIds      *[]uint
+	Type          *schemas.JobType
+	Status        *schemas.JobStatus
+}
+
+// RunJobOption tells who runs the job, the creator of the job is used for the scheduled runs
+type RunJobOption struct {
+	CreatorId    uint
+	ApiTokenName string
+}
+
+func (s *jobService) validate(name string, jobType schemas.JobType, schedule string, config *schemas.JobConfigSchema) error {
+	// the generated names of the runs get a 6 characters suffix and kubernetes limits the job-name label of their pods to 63 characters
+	errs := validation.IsDNS1035Label(name)
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ";"))
+	}
+	if len(name) > 52 {
+		return errors.Errorf("job name %s is longer than 52 characters", name)
+	}
+	switch jobType {
+	case schemas.JobTypeJob:
+		if schedule != "" {
+			return errors.New("only the cron jobs have a schedule")
+		}
+	case schemas.JobTypeCronJob:
+		if _, err := cron.ParseStandard(schedule); err != nil {
+			return errors.Wrapf(err, "invalid schedule %s", schedule)
+		}
+	default:
+		return errors.Errorf("unknown job type %s", jobType)
+	}
+	if config == nil {
+		return nil
+	}
+	if _, err := toJobResourceRequirements(config.Resources); err != nil {
+		return err
+	}
+	for _, name := range config.ImagePullSecrets {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return errors.Errorf("invalid image pull secret %s: %s", name, strings.Join(errs, ";"))
+		}
+	}
+	return nil
+}
+
+func (s *jobService) Create(ctx context.Context, opt CreateJobOption) (*models.Job, error) {
+	if err := s.validate(opt.Name, opt.Type, opt.Schedule, opt.Config); err != nil {
+		return nil, err
+	}
+
+	status := schemas.JobStatusPending
+	if opt.Type == schemas.JobTypeCronJob {
+		status = schemas.JobStatusScheduled
+		if opt.Suspend {
+			status = schemas.JobStatusSuspended
+		}
+	}
+	now := time.Now()
+
+	job := models.Job{
+		ResourceMixin: models.ResourceMixin{
+			Name: opt.Name,
+		},
+		CreatorAssociate: models.CreatorAssociate{
+			CreatorId: opt.CreatorId,
+		},
+		ClusterAssociate: models.ClusterAssociate{
+			ClusterId: opt.ClusterId,
+		},
+		BentoAssociate: models.BentoAssociate{
+			BentoId: opt.BentoId,
+		},
+		Description:     opt.Description,
+		KubeNamespace:   opt.KubeNamespace,
+		Type:            opt.Type,
+		Schedule:        opt.Schedule,
+		Suspend:         opt.Suspend,
+		Config:          opt.Config,
+		Status:          status,
+		StatusUpdatedAt: &now,
+	}
+	err := mustGetSession(ctx).Create(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, &job)
+	if err != nil {
+		return nil, err
+	}
+	err = LabelService.CreateOrUpdateLabelsFromLabelItemsSchema(ctx, opt.Labels, opt.CreatorId, cluster.OrganizationId, &job)
+	return &job, err
+}
+
+func (s *jobService) Update(ctx context.Context, job *models.Job, opt UpdateJobOption) (*models.Job, error) {
+	schedule := job.Schedule
+	if opt.Schedule != nil {
+		schedule = *opt.Schedule
+	}
+	config := job.Config
+	if opt.Config != nil {
+		config = *opt.Config
+	}
+	if opt.Schedule != nil || opt.Config != nil {
+		if err := s.validate(job.Name, job.Type, schedule, config); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	updaters := make(map[string]interface{})
+	if opt.Description != nil {
+		updaters["description"] = *opt.Description
+		defer func() {
+			if err == nil {
+				job.Description = *opt.Description
+			}
+		}()
+	}
+	if opt.BentoId != nil {
+		updaters["bento_id"] = *opt.BentoId
+		defer func() {
+			if err == nil {
+				job.BentoId = *opt.BentoId
+				job.AssociatedBentoCache = nil
+			}
+		}()
+	}
+	if opt.Schedule != nil {
+		updaters["schedule"] = *opt.Schedule
+		defer func() {
+			if err == nil {
+				job.Schedule = *opt.Schedule
+			}
+		}()
+	}
+	if opt.Suspend != nil {
+		updaters["suspend"] = *opt.Suspend
+		defer func() {
+			if err == nil {
+				job.Suspend = *opt.Suspend
+			}
+		}()
+	}
+	if opt.Config != nil {
+		updaters["config"] = *opt.Config
+		defer func() {
+			if err == nil {
+				job.Config = *opt.Config
+			}
+		}()
+	}
+	if opt.Status != nil {
+		updaters["status"] = *opt.Status
+		defer func() {
+			if err == nil {
+				job.Status = *opt.Status
+			}
+		}()
+	}
+	if opt.StatusUpdatedAt != nil {
+		updaters["status_updated_at"] = *opt.StatusUpdatedAt
+		defer func() {
+			if err == nil {
+				job.StatusUpdatedAt = opt.StatusUpdatedAt
+			}
+		}()
+	}
+
+	if len(updaters) > 0 {
+		err = s.getBaseDB(ctx).Where("id = ?", job.ID).Updates(updaters).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opt.Labels != nil {
+		cluster, err := ClusterService.GetAssociatedCluster(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+		user, err := GetCurrentUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		err = LabelService.CreateOrUpdateLabelsFromLabelItemsSchema(ctx, *opt.Labels, user.ID, cluster.OrganizationId, job)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return job, err
+}
+
+func (s *jobService) Get(ctx context.Context, id uint) (*models.Job, error) {
+	var job models.Job
+	err := getBaseQuery(ctx, s).Where("id = ?", id).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	if job.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &job, nil
+}
+
+func (s *jobService) GetByName(ctx context.Context, clusterId uint, kubeNamespace, name string) (*models.Job, error) {
+	var job models.Job
+	err := getBaseQuery(ctx, s).Where("cluster_id = ?", clusterId).Where("kube_namespace = ?", kubeNamespace).Where("name = ?", name).First(&job).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "get job %s", name)
+	}
+	if job.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &job, nil
+}
+
+func (s *jobService) List(ctx context.Context, opt ListJobOption) ([]*models.Job, uint, error) {
+	query := getBaseQuery(ctx, s)
+	if opt.ClusterId != nil {
+		query = query.Where("cluster_id = ?", *opt.ClusterId)
+	}
+	if opt.KubeNamespace != nil {
+		query = query.Where("kube_namespace = ?", *opt.KubeNamespace)
+	}
+	if opt.BentoIds != nil {
+		query = query.Where("bento_id in (?)", *opt.BentoIds)
+	}
+	if opt.Type != nil {
+		query = query.Where("type = ?", *opt.Type)
+	}
+	if opt.Status != nil {
+		query = query.Where("status = ?", *opt.Status)
+	}
+	query = opt.BindQueryWithKeywords(query, "job")
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	jobs := make([]*models.Job, 0)
+	query = opt.BindQueryWithLimit(query)
+	err = query.Order("id DESC").Find(&jobs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return jobs, uint(total), err
+}
+
+// Delete removes the kubernetes objects of the job with all of its runs and pods
+func (s *jobService) Delete(ctx context.Context, job *models.Job) (*models.Job, error) {
+	propagationPolicy := metav1.DeletePropagationBackground
+	deleteOptions := metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	}
+	if job.Type == schemas.JobTypeCronJob {
+		cronJobsCli, err := s.GetKubeCronJobsCli(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+		err = cronJobsCli.Delete(ctx, job.Name, deleteOptions)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "delete kube cron job %s", job.Name)
+		}
+	}
+	jobsCli, err := s.GetKubeJobsCli(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	err = jobsCli.DeleteCollection(ctx, deleteOptions, metav1.ListOptions{
+		LabelSelector: s.getKubeLabelSelector(job),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "delete kube jobs")
+	}
+	return job, s.getBaseDB(ctx).Unscoped().Delete(job).Error
+}
+
+func (s *jobService) GetKubeJobsCli(ctx context.Context, job *models.Job) (batchtypev1.JobInterface, error) {
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated cluster")
+	}
+	cliset, _, err := ClusterService.GetKubeCliSet(ctx, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "get k8s cliset")
+	}
+	return cliset.BatchV1().Jobs(job.KubeNamespace), nil
+}
+
+func (s *jobService) GetKubeCronJobsCli(ctx context.Context, job *models.Job) (batchtypev1beta.CronJobInterface, error) {
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated cluster")
+	}
+	cliset, _, err := ClusterService.GetKubeCliSet(ctx, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "get k8s cliset")
+	}
+	return cliset.BatchV1beta1().CronJobs(job.KubeNamespace), nil
+}
+
+func (s *jobService) GetKubeLabels(job *models.Job) map[string]string {
+	return map[string]string{
+		consts.KubeLabelYataiJob: job.Name,
+	}
+}
+
+func (s *jobService) getKubeLabelSelector(job *models.Job) string {
+	return fmt.Sprintf("%s=%s", consts.KubeLabelYataiJob, job.Name)
+}
+
+// GetImage returns the image the job runs, it is the bento image pushed by yatai-deployment unless the config of the job overrides it
+func (s *jobService) GetImage(ctx context.Context, job *models.Job) (string, error) {
+	if job.Config != nil && job.Config.Image != "" {
+		return job.Config.Image, nil
+	}
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, job)
+	if err != nil {
+		return "", errors.Wrap(err, "get associated cluster")
+	}
+	if cluster.Config == nil || cluster.Config.BentoImageRepository == "" {
+		return "", errors.Errorf("cluster %s has no bento image repository, set it in the cluster config or set the image of the job", cluster.Name)
+	}
+	bento, err := BentoService.GetAssociatedBento(ctx, job)
+	if err != nil {
+		return "", errors.Wrap(err, "get associated bento")
+	}
+	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+	if err != nil {
+		return "", errors.Wrap(err, "get associated bento repository")
+	}
+	return fmt.Sprintf("%s:yatai.%s.%s", cluster.Config.BentoImageRepository, bentoRepository.Name, bento.Version), nil
+}
+
+// getBentoImageKubeNamespace makes sure the bento image of the job has been pushed to the bento image repository of the cluster,
+// yatai-deployment only builds it when the bento is deployed in the cluster. It returns the namespace of a deployment of the bento
+func (s *jobService) getBentoImageKubeNamespace(ctx context.Context, cluster *models.Cluster, job *models.Job) (string, error) {
+	bento, err := BentoService.GetAssociatedBento(ctx, job)
+	if err != nil {
+		return "", errors.Wrap(err, "get associated bento")
+	}
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		BaseListOption: BaseListOption{
+			Start: utils.UintPtr(0),
+			Count: utils.UintPtr(1),
+		},
+		ClusterId: utils.UintPtr(cluster.ID),
+		BentoIds:  &[]uint{bento.ID},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "list deployment targets")
+	}
+	if len(deploymentTargets) == 0 {
+		return "", errors.Errorf("bento %s has never been deployed in cluster %s, yatai-deployment builds its image when it is deployed, deploy it first or set the image of the job", bento.Version, cluster.Name)
+	}
+	if bento.ImageBuildStatus != modelschemas.ImageBuildStatusSuccess {
+		return "", errors.Errorf("the image of bento %s is not built yet, its image build is %s", bento.Version, bento.ImageBuildStatus)
+	}
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentTargets[0])
+	if err != nil {
+		return "", errors.Wrap(err, "get deployment")
+	}
+	return DeploymentService.GetKubeNamespace(deployment), nil
+}
+
+// makeSureBentoImagePullSecret copies the yatai-regcred secret yatai-deployment creates in the namespaces of the deployments
+// to the namespace of the job, it returns false when the bento image repository needs no credentials
+func (s *jobService) makeSureBentoImagePullSecret(ctx context.Context, cluster *models.Cluster, job *models.Job, sourceKubeNamespace string) (bool, error) {
+	kubeCli, _, err := ClusterService.GetKubeCliSet(ctx, cluster)
+	if err != nil {
+		return false, errors.Wrap(err, "get k8s cliset")
+	}
+	_, err = kubeCli.CoreV1().Secrets(job.KubeNamespace).Get(ctx, commonconsts.KubeSecretNameRegcred, metav1.GetOptions{})
+	if err == nil {
+		return true, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "get kube secret %s", commonconsts.KubeSecretNameRegcred)
+	}
+	sourceSecret, err := kubeCli.CoreV1().Secrets(sourceKubeNamespace).Get(ctx, commonconsts.KubeSecretNameRegcred, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "get kube secret %s in namespace %s", commonconsts.KubeSecretNameRegcred, sourceKubeNamespace)
+	}
+	_, err = kubeCli.CoreV1().Secrets(job.KubeNamespace).Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      commonconsts.KubeSecretNameRegcred,
+			Namespace: job.KubeNamespace,
+			Labels: map[string]string{
+				commonconsts.KubeLabelCreator: commonconsts.KubeCreator,
+			},
+		},
+		Type: sourceSecret.Type,
+		Data: sourceSecret.Data,
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return false, errors.Wrapf(err, "create kube secret %s", commonconsts.KubeSecretNameRegcred)
+	}
+	return true, nil
+}
+
+// materializeSecretEnvs creates the kubernetes secrets of the yatai secrets referenced by the envs of the job in its namespace
+func (s *jobService) materializeSecretEnvs(ctx context.Context, cluster *models.Cluster, job *models.Job) error {
+	if job.Config == nil {
+		return nil
+	}
+	materialized := make(map[string]map[string]string)
+	for _, env := range job.Config.Envs {
+		ref := schemas.ParseSecretKeyRef(env.Value)
+		if ref == nil {
+			continue
+		}
+		data, ok := materialized[ref.Name]
+		if !ok {
+			secret, err := SecretService.Resolve(ctx, cluster, ref.Name)
+			if err != nil {
+				return errors.Wrapf(err, "get secret %s of env %s", ref.Name, env.Key)
+			}
+			data, err = SecretService.GetData(secret)
+			if err != nil {
+				return err
+			}
+			if err = SecretService.Materialize(ctx, cluster, job.KubeNamespace, secret); err != nil {
+				return errors.Wrapf(err, "materialize secret %s", ref.Name)
+			}
+			materialized[ref.Name] = data
+		}
+		if _, ok = data[ref.Key]; !ok {
+			return errors.Errorf("secret %s of env %s has no key %s", ref.Name, env.Key, ref.Key)
+		}
+	}
+	return nil
+}
+
+// prepareKubeJobSpec makes sure the image of the job can be pulled and its secrets exist in its namespace before rendering the spec of the runs
+func (s *jobService) prepareKubeJobSpec(ctx context.Context, cluster *models.Cluster, job *models.Job) (batchv1.JobSpec, error) {
+	image, err := s.GetImage(ctx, job)
+	if err != nil {
+		return batchv1.JobSpec{}, err
+	}
+	var imagePullSecrets []string
+	if job.Config != nil {
+		imagePullSecrets = append(imagePullSecrets, job.Config.ImagePullSecrets...)
+	}
+	if job.Config == nil || job.Config.Image == "" {
+		sourceKubeNamespace, err := s.getBentoImageKubeNamespace(ctx, cluster, job)
+		if err != nil {
+			return batchv1.JobSpec{}, err
+		}
+		ok, err := s.makeSureBentoImagePullSecret(ctx, cluster, job, sourceKubeNamespace)
+		if err != nil {
+			return batchv1.JobSpec{}, err
+		}
+		if ok {
+			imagePullSecrets = append(imagePullSecrets, commonconsts.KubeSecretNameRegcred)
+		}
+	}
+	if err = s.materializeSecretEnvs(ctx, cluster, job); err != nil {
+		return batchv1.JobSpec{}, err
+	}
+	return s.RenderKubeJobSpec(job, image, imagePullSecrets)
+}
+
+func toJobResourceRequirements(resources *modelschemas.DeploymentTargetResources) (corev1.ResourceRequirements, error) {
+	res := corev1.ResourceRequirements{}
+	if resources == nil {
+		return res, nil
+	}
+	toResourceList := func(item *modelschemas.DeploymentTargetResourceItem) (corev1.ResourceList, error) {
+		if item == nil {
+			return nil, nil
+		}
+		list := corev1.ResourceList{}
+		for name, value := range map[corev1.ResourceName]string{
+			corev1.ResourceCPU:                 item.CPU,
+			corev1.ResourceMemory:              item.Memory,
+			commonconsts.KubeResourceGPUNvidia: item.GPU,
+		} {
+			if value == "" {
+				continue
+			}
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse %s quantity %s", name, value)
+			}
+			list[name] = quantity
+		}
+		return list, nil
+	}
+	var err error
+	res.Requests, err = toResourceList(resources.Requests)
+	if err != nil {
+		return res, err
+	}
+	res.Limits, err = toResourceList(resources.Limits)
+	return res, err
+}
+
+// RenderKubeJobSpec is the spec of every run of the job, the runs are not restarted in place so that the logs of each retry are kept.
+// The envs referencing a yatai secret read it from the kubernetes secret of the same name
+func (s *jobService) RenderKubeJobSpec(job *models.Job, image string, imagePullSecrets []string) (batchv1.JobSpec, error) {
+	config := job.Config
+	if config == nil {
+		config = &schemas.JobConfigSchema{}
+	}
+	resources, err := toJobResourceRequirements(config.Resources)
+	if err != nil {
+		return batchv1.JobSpec{}, err
+	}
+	envs := make([]corev1.EnvVar, 0, len(config.Envs))
+	for _, env := range config.Envs {
+		ref := schemas.ParseSecretKeyRef(env.Value)
+		if ref == nil {
+			envs = append(envs, corev1.EnvVar{
+				Name:  env.Key,
+				Value: env.Value,
+			})
+			continue
+		}
+		envs = append(envs, corev1.EnvVar{
+			Name: env.Key,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: ref.Name,
+					},
+					Key: ref.Key,
+				},
+			},
+		})
+	}
+	pullSecrets := make([]corev1.LocalObjectReference, 0, len(imagePullSecrets))
+	for _, name := range imagePullSecrets {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{
+			Name: name,
+		})
+	}
+	return batchv1.JobSpec{
+		BackoffLimit:          config.BackoffLimit,
+		ActiveDeadlineSeconds: config.ActiveDeadlineSeconds,
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: s.GetKubeLabels(job),
+			},
+			Spec: corev1.PodSpec{
+				RestartPolicy:    corev1.RestartPolicyNever,
+				ImagePullSecrets: pullSecrets,
+				Containers: []corev1.Container{
+					{
+						Name:      jobContainerName,
+						Image:     image,
+						Args:      config.Args,
+						Env:       envs,
+						Resources: resources,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (s *jobService) renderKubeCronJob(job *models.Job, jobSpec batchv1.JobSpec) *batchv1beta1.CronJob {
+	successfulRunsHistoryLimit, failedRunsHistoryLimit := getJobRunsHistoryLimits(job)
+	return &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   job.Name,
+			Labels: s.GetKubeLabels(job),
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule: job.Schedule,
+			Suspend:  &job.Suspend,
+			// a nightly scoring that is still running when the next one is due should not run twice
+			ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &successfulRunsHistoryLimit,
+			FailedJobsHistoryLimit:     &failedRunsHistoryLimit,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: s.GetKubeLabels(job),
+				},
+				Spec: jobSpec,
+			},
+		},
+	}
+}
+
+func getJobRunsHistoryLimits(job *models.Job) (successful int32, failed int32) {
+	successful, failed = defaultJobSuccessfulRunsHistoryLimit, defaultJobFailedRunsHistoryLimit
+	if job.Config != nil && job.Config.SuccessfulRunsHistoryLimit != nil {
+		successful = *job.Config.SuccessfulRunsHistoryLimit
+	}
+	if job.Config != nil && job.Config.FailedRunsHistoryLimit != nil {
+		failed = *job.Config.FailedRunsHistoryLimit
+	}
+	return
+}
+
+// Apply creates or updates the kubernetes cron job of a scheduled job, the one-off jobs have nothing to apply until they run
+func (s *jobService) Apply(ctx context.Context, job *models.Job) error {
+	if job.Type != schemas.JobTypeCronJob {
+		return nil
+	}
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, job)
+	if err != nil {
+		return errors.Wrap(err, "get associated cluster")
+	}
+	_, err = KubeNamespaceService.MakeSureNamespace(ctx, cluster, job.KubeNamespace)
+	if err != nil {
+		return errors.Wrapf(err, "make sure namespace %s", job.KubeNamespace)
+	}
+	jobSpec, err := s.prepareKubeJobSpec(ctx, cluster, job)
+	if err != nil {
+		return err
+	}
+	cronJob := s.renderKubeCronJob(job, jobSpec)
+
+	cronJobsCli, err := s.GetKubeCronJobsCli(ctx, job)
+	if err != nil {
+		return err
+	}
+	oldCronJob, err := cronJobsCli.Get(ctx, job.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = cronJobsCli.Create(ctx, cronJob, metav1.CreateOptions{})
+		return errors.Wrapf(err, "create kube cron job %s", job.Name)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "get kube cron job %s", job.Name)
+	}
+	oldCronJob.Labels = cronJob.Labels
+	oldCronJob.Spec = cronJob.Spec
+	_, err = cronJobsCli.Update(ctx, oldCronJob, metav1.UpdateOptions{})
+	return errors.Wrapf(err, "update kube cron job %s", job.Name)
+}
+
+// Run starts a run of the job right away, the run of a cron job does not wait for its schedule
+func (s *jobService) Run(ctx context.Context, job *models.Job, opt RunJobOption) (*schemas.JobRunSchema, error) {
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated cluster")
+	}
+	_, err = KubeNamespaceService.MakeSureNamespace(ctx, cluster, job.KubeNamespace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "make sure namespace %s", job.KubeNamespace)
+	}
+	jobSpec, err := s.prepareKubeJobSpec(ctx, cluster, job)
+	if err != nil {
+		return nil, err
+	}
+
+	jobsCli, err := s.GetKubeJobsCli(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	kubeJob, err := jobsCli.Create(ctx, &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: job.Name + "-",
+			Labels:       s.GetKubeLabels(job),
+		},
+		Spec: jobSpec,
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "create kube job")
+	}
+
+	if err = s.pruneRuns(ctx, job); err != nil {
+		return nil, errors.Wrap(err, "prune runs")
+	}
+
+	_, err = EventService.Create(ctx, CreateEventOption{
+		CreatorId:      opt.CreatorId,
+		ApiTokenName:   opt.ApiTokenName,
+		OrganizationId: &cluster.OrganizationId,
+		ClusterId:      &cluster.ID,
+		ResourceType:   schemas.ResourceTypeJob,
+		ResourceId:     job.ID,
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  fmt.Sprintf("started run %s", kubeJob.Name),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+
+	return toJobRunSchema(kubeJob, nil), nil
+}
+
+// pruneRuns deletes the oldest finished runs of a one-off job beyond the history limits, kubernetes does it for the cron jobs
+func (s *jobService) pruneRuns(ctx context.Context, job *models.Job) error {
+	if job.Type != schemas.JobTypeJob {
+		return nil
+	}
+	runs, err := s.ListRuns(ctx, job)
+	if err != nil {
+		return err
+	}
+	successfulRunsHistoryLimit, failedRunsHistoryLimit := getJobRunsHistoryLimits(job)
+	jobsCli, err := s.GetKubeJobsCli(ctx, job)
+	if err != nil {
+		return err
+	}
+	propagationPolicy := metav1.DeletePropagationBackground
+	for _, name := range getPrunedJobRunNames(runs, successfulRunsHistoryLimit, failedRunsHistoryLimit) {
+		err = jobsCli.Delete(ctx, name, metav1.DeleteOptions{
+			PropagationPolicy: &propagationPolicy,
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "delete kube job %s", name)
+		}
+	}
+	return nil
+}
+
+// getPrunedJobRunNames expects the runs ordered from the newest
+func getPrunedJobRunNames(runs []*schemas.JobRunSchema, successfulRunsHistoryLimit, failedRunsHistoryLimit int32) []string {
+	var succeeded, failed int32
+	names := make([]string, 0)
+	for _, run := range runs {
+		switch run.Status {
+		case schemas.JobStatusSucceeded:
+			succeeded++
+			if succeeded > successfulRunsHistoryLimit {
+				names = append(names, run.Name)
+			}
+		case schemas.JobStatusFailed:
+			failed++
+			if failed > failedRunsHistoryLimit {
+				names = append(names, run.Name)
+			}
+		}
+	}
+	return names
+}
+
+// ListRuns returns the runs of the job that kubernetes still keeps, from the newest
+func (s *jobService) ListRuns(ctx context.Context, job *models.Job) ([]*schemas.JobRunSchema, error) {
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated cluster")
+	}
+	cliset, _, err := ClusterService.GetKubeCliSet(ctx, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "get k8s cliset")
+	}
+	listOptions := metav1.ListOptions{
+		LabelSelector: s.getKubeLabelSelector(job),
+	}
+	kubeJobs, err := cliset.BatchV1().Jobs(job.KubeNamespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "list kube jobs")
+	}
+	pods, err := cliset.CoreV1().Pods(job.KubeNamespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "list kube pods")
+	}
+	kubeJobs_ := make([]*batchv1.Job, 0, len(kubeJobs.Items))
+	for i := range kubeJobs.Items {
+		kubeJobs_ = append(kubeJobs_, &kubeJobs.Items[i])
+	}
+	pods_ := make([]*corev1.Pod, 0, len(pods.Items))
+	for i := range pods.Items {
+		pods_ = append(pods_, &pods.Items[i])
+	}
+	return toJobRunSchemas(kubeJobs_, pods_), nil
+}
+
+// listRunsWithListers is ListRuns from the caches of the informers
+func (s *jobService) listRunsWithListers(job *models.Job, jobLister listerBatchV1.JobNamespaceLister, podLister listerCoreV1.PodNamespaceLister) ([]*schemas.JobRunSchema, error) {
+	selector := labels.SelectorFromSet(s.GetKubeLabels(job))
+	kubeJobs, err := jobLister.List(selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "list kube jobs")
+	}
+	pods, err := podLister.List(selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "list kube pods")
+	}
+	return toJobRunSchemas(kubeJobs, pods), nil
+}
+
+func toJobRunSchemas(kubeJobs []*batchv1.Job, pods []*corev1.Pod) []*schemas.JobRunSchema {
+	podNames := make(map[string][]string)
+	for _, pod := range pods {
+		kubeJobName := pod.Labels[kubeLabelJobName]
+		podNames[kubeJobName] = append(podNames[kubeJobName], pod.Name)
+	}
+
+	sort.SliceStable(kubeJobs, func(i, j int) bool {
+		return kubeJobs[j].CreationTimestamp.Before(&kubeJobs[i].CreationTimestamp)
+	})
+	runs := make([]*schemas.JobRunSchema, 0, len(kubeJobs))
+	for _, kubeJob := range kubeJobs {
+		runs = append(runs, toJobRunSchema(kubeJob, podNames[kubeJob.Name]))
+	}
+	return runs
+}
+
+func toJobRunSchema(kubeJob *batchv1.Job, podNames []string) *schemas.JobRunSchema {
+	run := &schemas.JobRunSchema{
+		Name:      kubeJob.Name,
+		Status:    schemas.JobStatusPending,
+		Active:    kubeJob.Status.Active,
+		Succeeded: kubeJob.Status.Succeeded,
+		Failed:    kubeJob.Status.Failed,
+		PodNames:  podNames,
+	}
+	if run.PodNames == nil {
+		run.PodNames = []string{}
+	}
+	if kubeJob.Status.StartTime != nil {
+		run.StartedAt = &kubeJob.Status.StartTime.Time
+	}
+	if kubeJob.Status.Active > 0 {
+		run.Status = schemas.JobStatusRunning
+	}
+	for _, condition := range kubeJob.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		// nolint: exhaustive
+		switch condition.Type {
+		case batchv1.JobComplete:
+			run.Status = schemas.JobStatusSucceeded
+			run.FinishedAt = &condition.LastTransitionTime.Time
+			if kubeJob.Status.CompletionTime != nil {
+				run.FinishedAt = &kubeJob.Status.CompletionTime.Time
+			}
+		case batchv1.JobFailed:
+			run.Status = schemas.JobStatusFailed
+			run.FinishedAt = &condition.LastTransitionTime.Time
+			run.FailureReason = condition.Message
+		}
+	}
+	return run
+}
+
+// getJobStatus tells the status of the job from its runs ordered from the newest,
+// a cron job that is not running has the status of its last run
+func getJobStatus(job *models.Job, runs []*schemas.JobRunSchema) schemas.JobStatus {
+	if len(runs) > 0 && (runs[0].Status == schemas.JobStatusRunning || runs[0].Status == schemas.JobStatusPending) {
+		return runs[0].Status
+	}
+	if job.Type == schemas.JobTypeCronJob && job.Suspend {
+		return schemas.JobStatusSuspended
+	}
+	if len(runs) == 0 {
+		if job.Type == schemas.JobTypeCronJob {
+			return schemas.JobStatusScheduled
+		}
+		return schemas.JobStatusPending
+	}
+	return runs[0].Status
+}
+
+// SyncStatus updates the status of the job from its runs and records an event when a run finishes
+func (s *jobService) SyncStatus(ctx context.Context, job *models.Job) (*models.Job, error) {
+	runs, err := s.ListRuns(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	cronJobExists := false
+	if job.Type == schemas.JobTypeCronJob {
+		cronJobsCli, err := s.GetKubeCronJobsCli(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+		_, err = cronJobsCli.Get(ctx, job.Name, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "get kube cron job %s", job.Name)
+		}
+		cronJobExists = err == nil
+	}
+	return s.syncStatus(ctx, job, runs, cronJobExists)
+}
+
+// SyncStatusWithListers is SyncStatus from the caches of the informers of the status watcher
+func (s *jobService) SyncStatusWithListers(ctx context.Context, job *models.Job, jobLister listerBatchV1.JobNamespaceLister, cronJobLister listerBatchV1beta1.CronJobNamespaceLister, podLister listerCoreV1.PodNamespaceLister) (*models.Job, error) {
+	runs, err := s.listRunsWithListers(job, jobLister, podLister)
+	if err != nil {
+		return nil, err
+	}
+	cronJobExists := false
+	if job.Type == schemas.JobTypeCronJob {
+		_, err = cronJobLister.Get(job.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "get kube cron job %s", job.Name)
+		}
+		cronJobExists = err == nil
+	}
+	return s.syncStatus(ctx, job, runs, cronJobExists)
+}
+
+// syncStatus saves the status given by the runs, the status of a cron job that is gone is unknown
+func (s *jobService) syncStatus(ctx context.Context, job *models.Job, runs []*schemas.JobRunSchema, cronJobExists bool) (*models.Job, error) {
+	status := schemas.JobStatusUnknown
+	var lastRun *schemas.JobRunSchema
+	if len(runs) > 0 {
+		lastRun = runs[0]
+	}
+	if job.Type != schemas.JobTypeCronJob || cronJobExists {
+		status = getJobStatus(job, runs)
+	}
+
+	if status == job.Status {
+		return job, nil
+	}
+	now := time.Now()
+	job, err := s.Update(ctx, job, UpdateJobOption{
+		Status:          &status,
+		StatusUpdatedAt: &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if lastRun == nil || lastRun.Status != status {
+		return job, nil
+	}
+
+	var eventStatus modelschemas.EventStatus
+	var operationName string
+	// nolint: exhaustive
+	switch status {
+	case schemas.JobStatusSucceeded:
+		eventStatus = modelschemas.EventStatusSuccess
+		operationName = fmt.Sprintf("run %s succeeded", lastRun.Name)
+	case schemas.JobStatusFailed:
+		eventStatus = modelschemas.EventStatusFailed
+		operationName = fmt.Sprintf("run %s failed after %d attempts: %s", lastRun.Name, lastRun.Failed, lastRun.FailureReason)
+	default:
+		return job, nil
+	}
+	cluster, err := ClusterService.GetAssociatedCluster(ctx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "get associated cluster")
+	}
+	_, err = EventService.Create(ctx, CreateEventOption{
+		CreatorId:      job.CreatorId,
+		OrganizationId: &cluster.OrganizationId,
+		ClusterId:      &cluster.ID,
+		ResourceType:   schemas.ResourceTypeJob,
+		ResourceId:     job.ID,
+		Status:         eventStatus,
+		OperationName:  operationName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create event")
+	}
+	return job, nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/common/consts"
+)
+
+func TestRenderKubeJobSpec(t *testing.T) {
+	job := &models.Job{
+		ResourceMixin: models.ResourceMixin{Name: "nightly-scoring"},
+		Type:          schemas.JobTypeJob,
+		Config: &schemas.JobConfigSchema{
+			Args: []string{"python", "batch.py"},
+			Resources: &modelschemas.DeploymentTargetResources{
+				Requests: &modelschemas.DeploymentTargetResourceItem{CPU: "500m", Memory: "1Gi"},
+				Limits:   &modelschemas.DeploymentTargetResourceItem{GPU: "1"},
+			},
+			Envs: []*modelschemas.LabelItemSchema{
+				{Key: "DATE", Value: "today"},
+				{Key: "API_KEY", Value: schemas.SecretEnvValuePrefix + "scoring/api-key"},
+			},
+			BackoffLimit: pointer.Int32Ptr(2),
+		},
+	}
+
+	spec, err := JobService.RenderKubeJobSpec(job, "registry/bentos:yatai.iris.v1", []string{"yatai-regcred"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *spec.BackoffLimit != 2 {
+		t.Errorf("expected the backoff limit of the config, got %d", *spec.BackoffLimit)
+	}
+	if spec.Template.Spec.RestartPolicy != corev1.RestartPolicyNever {
+		t.Errorf("expected the failed pods not to be restarted in place, got %s", spec.Template.Spec.RestartPolicy)
+	}
+	if spec.Template.Labels[consts.KubeLabelYataiJob] != job.Name {
+		t.Errorf("expected the pods to be labeled with the job name, got %v", spec.Template.Labels)
+	}
+	container := spec.Template.Spec.Containers[0]
+	if container.Image != "registry/bentos:yatai.iris.v1" || len(container.Args) != 2 || container.Env[0].Name != "DATE" {
+		t.Errorf("unexpected container %+v", container)
+	}
+	if ref := container.Env[1].ValueFrom; container.Env[1].Value != "" || ref == nil || ref.SecretKeyRef.Name != "scoring" || ref.SecretKeyRef.Key != "api-key" {
+		t.Errorf("expected the secret env to reference the kube secret, got %+v", container.Env[1])
+	}
+	if pullSecrets := spec.Template.Spec.ImagePullSecrets; len(pullSecrets) != 1 || pullSecrets[0].Name != "yatai-regcred" {
+		t.Errorf("unexpected image pull secrets %v", pullSecrets)
+	}
+	if container.Resources.Requests.Cpu().String() != "500m" || container.Resources.Limits.Name("nvidia.com/gpu", "").String() != "1" {
+		t.Errorf("unexpected resources %+v", container.Resources)
+	}
+
+	job.Config.Resources.Requests.Memory = "a lot"
+	if _, err = JobService.RenderKubeJobSpec(job, "image", nil); err == nil {
+		t.Error("expected an invalid quantity to be rejected")
+	}
+}
+
+func TestGetJobStatus(t *testing.T) {
+	job := &models.Job{Type: schemas.JobTypeCronJob}
+	if status := getJobStatus(job, nil); status != schemas.JobStatusScheduled {
+		t.Errorf("expected a cron job without runs to be scheduled, got %s", status)
+	}
+	job.Suspend = true
+	if status := getJobStatus(job, []*schemas.JobRunSchema{{Status: schemas.JobStatusFailed}}); status != schemas.JobStatusSuspended {
+		t.Errorf("expected a suspended cron job to be suspended, got %s", status)
+	}
+	if status := getJobStatus(job, []*schemas.JobRunSchema{{Status: schemas.JobStatusRunning}}); status != schemas.JobStatusRunning {
+		t.Errorf("expected a suspended cron job to be running until its run finishes, got %s", status)
+	}
+
+	job = &models.Job{Type: schemas.JobTypeJob}
+	if status := getJobStatus(job, nil); status != schemas.JobStatusPending {
+		t.Errorf("expected a job without runs to be pending, got %s", status)
+	}
+	runs := []*schemas.JobRunSchema{{Status: schemas.JobStatusFailed}, {Status: schemas.JobStatusSucceeded}}
+	if status := getJobStatus(job, runs); status != schemas.JobStatusFailed {
+		t.Errorf("expected the status of the last run, got %s", status)
+	}
+}
+
+func TestToJobRunSchema(t *testing.T) {
+	kubeJob := &batchv1.Job{
+		Status: batchv1.JobStatus{
+			Failed: 3,
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "Job has reached the specified backoff limit"},
+			},
+		},
+	}
+	run := toJobRunSchema(kubeJob, nil)
+	if run.Status != schemas.JobStatusFailed || run.FailureReason == "" || run.FinishedAt == nil || run.Failed != 3 {
+		t.Errorf("unexpected run %+v", run)
+	}
+}
+
+func TestGetPrunedJobRunNames(t *testing.T) {
+	runs := []*schemas.JobRunSchema{
+		{Name: "r6", Status: schemas.JobStatusRunning},
+		{Name: "r5", Status: schemas.JobStatusSucceeded},
+		{Name: "r4", Status: schemas.JobStatusFailed},
+		{Name: "r3", Status: schemas.JobStatusSucceeded},
+		{Name: "r2", Status: schemas.JobStatusFailed},
+		{Name: "r1", Status: schemas.JobStatusSucceeded},
+	}
+	names := getPrunedJobRunNames(runs, 2, 1)
+	if len(names) != 2 || names[0] != "r2" || names[1] != "r1" {
+		t.Errorf("expected the oldest finished runs beyond the limits to be pruned, got %v", names)
+	}
+}
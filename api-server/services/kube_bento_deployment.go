@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
@@ -61,15 +63,44 @@ func (s *kubeBentoDeploymentService) Deploy(ctx context.Context, deploymentTarge
 		}()
 	}()
 
-	bento, err := BentoService.GetAssociatedBento(ctx, deploymentTarget)
+	kubeBentoDeployment, err = s.Render(ctx, deployment, deploymentTarget)
 	if err != nil {
-		err = errors.Wrap(err, "failed to get associated bento")
 		return
 	}
+
+	var oldKubeBentoDeployment *servingv1alpha2.BentoDeployment
+	oldKubeBentoDeployment, err = cli.Get(ctx, kubeBentoDeployment.Name, metav1.GetOptions{})
+	isNotFound := apierrors.IsNotFound(err)
+	if err != nil && !isNotFound {
+		err = errors.Wrap(err, "failed to get kube bento deployment")
+		return
+	}
+	if isNotFound {
+		kubeBentoDeployment, err = cli.Create(ctx, kubeBentoDeployment, metav1.CreateOptions{})
+		if err != nil {
+			err = errors.Wrapf(err, "failed to create kube bento deployment %s", kubeBentoDeployment.Name)
+			return
+		}
+	} else {
+		kubeBentoDeployment.SetResourceVersion(oldKubeBentoDeployment.GetResourceVersion())
+		kubeBentoDeployment, err = cli.Update(ctx, kubeBentoDeployment, metav1.UpdateOptions{})
+		if err != nil {
+			err = errors.Wrapf(err, "failed to update kube bento deployment %s", kubeBentoDeployment.Name)
+			return
+		}
+	}
+	return
+}
+
+// Render builds the BentoDeployment of the deployment target exactly as Deploy sends it to the cluster
+func (s *kubeBentoDeploymentService) Render(ctx context.Context, deployment *models.Deployment, deploymentTarget *models.DeploymentTarget) (*servingv1alpha2.BentoDeployment, error) {
+	bento, err := BentoService.GetAssociatedBento(ctx, deploymentTarget)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get associated bento")
+	}
 	tag, err := BentoService.GetTag(ctx, bento)
 	if err != nil {
-		err = errors.Wrap(err, "failed to get bento tag")
-		return
+		return nil, errors.Wrap(err, "failed to get bento tag")
 	}
 
 	var autoscalingSpec *modelschemas.DeploymentTargetHPAConf
@@ -77,11 +108,13 @@ func (s *kubeBentoDeploymentService) Deploy(ctx context.Context, deploymentTarge
 		autoscalingSpec = deploymentTarget.Config.HPAConf
 	}
 
-	envs := make([]modelschemas.LabelItemSchema, 0)
-	if deploymentTarget.Config != nil && deploymentTarget.Config.Envs != nil {
-		for _, env := range *deploymentTarget.Config.Envs {
-			envs = append(envs, *env)
-		}
+	var configEnvs *[]*modelschemas.LabelItemSchema
+	if deploymentTarget.Config != nil {
+		configEnvs = deploymentTarget.Config.Envs
+	}
+	envs, err := toBentoDeploymentEnvs("", configEnvs)
+	if err != nil {
+		return nil, err
 	}
 
 	var resources *modelschemas.DeploymentTargetResources
@@ -93,11 +126,9 @@ func (s *kubeBentoDeploymentService) Deploy(ctx context.Context, deploymentTarge
 	if deploymentTarget.Config != nil && deploymentTarget.Config.Runners != nil {
 		runners = make([]servingv1alpha2.BentoDeploymentRunnerSpec, 0, len(deploymentTarget.Config.Runners))
 		for name, runner := range deploymentTarget.Config.Runners {
-			envs_ := make([]modelschemas.LabelItemSchema, 0)
-			if runner.Envs != nil {
-				for _, env := range *runner.Envs {
-					envs_ = append(envs_, *env)
-				}
+			envs_, err := toBentoDeploymentEnvs(name+"/", runner.Envs)
+			if err != nil {
+				return nil, err
 			}
 			runners = append(runners, servingv1alpha2.BentoDeploymentRunnerSpec{
 				Name:        name,
@@ -108,13 +139,18 @@ func (s *kubeBentoDeploymentService) Deploy(ctx context.Context, deploymentTarge
 		}
 	}
 
+	// the runners come from a map, they are sorted so that rendering the same target twice gives the same object
+	sort.Slice(runners, func(i, j int) bool {
+		return runners[i].Name < runners[j].Name
+	})
+
 	ingress := servingv1alpha2.BentoDeploymentIngressSpec{}
 
 	if deploymentTarget.Config != nil && deploymentTarget.Config.EnableIngress != nil && *deploymentTarget.Config.EnableIngress {
 		ingress.Enabled = true
 	}
 
-	kubeBentoDeployment = &servingv1alpha2.BentoDeployment{
+	return &servingv1alpha2.BentoDeployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      deployment.Name,
 			Namespace: DeploymentService.GetKubeNamespace(deployment),
@@ -127,28 +163,108 @@ func (s *kubeBentoDeploymentService) Deploy(ctx context.Context, deploymentTarge
 			Runners:     runners,
 			Ingress:     ingress,
 		},
+	}, nil
+}
+
+// toBentoDeploymentEnvs rejects the envs referencing secrets, the BentoDeployment can only carry plain values
+// and the values of the secrets must not be written to it, they need a BentoDeployment CRD that references secret keys
+func toBentoDeploymentEnvs(prefix string, items *[]*modelschemas.LabelItemSchema) ([]modelschemas.LabelItemSchema, error) {
+	envs := make([]modelschemas.LabelItemSchema, 0)
+	if items == nil {
+		return envs, nil
 	}
+	for _, env := range *items {
+		if err := validatePlainEnv(env); err != nil {
+			return nil, errors.Wrapf(err, "env %s%s", prefix, env.Key)
+		}
+		envs = append(envs, *env)
+	}
+	return envs, nil
+}
 
-	var oldKubeBentoDeployment *servingv1alpha2.BentoDeployment
-	oldKubeBentoDeployment, err = cli.Get(ctx, kubeBentoDeployment.Name, metav1.GetOptions{})
+// ValidateDeploymentTargetConfigEnvs tells before a revision is requested whether the envs of the config can be deployed
+func ValidateDeploymentTargetConfigEnvs(config *modelschemas.DeploymentTargetConfig) error {
+	if config == nil {
+		return nil
+	}
+	if _, err := toBentoDeploymentEnvs("", config.Envs); err != nil {
+		return err
+	}
+	for name, runner := range config.Runners {
+		if _, err := toBentoDeploymentEnvs(name+"/", runner.Envs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type KubeBentoDeploymentDryRunResult struct {
+	// Desired is the BentoDeployment returned by the server-side dry run, it has the defaults of the cluster applied
+	Desired *servingv1alpha2.BentoDeployment
+	// Live is nil when the BentoDeployment does not exist yet
+	Live           *servingv1alpha2.BentoDeployment
+	AdmissionError string
+	Warnings       []string
+}
+
+// DryRun submits the rendered BentoDeployment with the server-side dry run, so that the admission errors are known before a revision is created
+func (s *kubeBentoDeploymentService) DryRun(ctx context.Context, deployment *models.Deployment, deploymentTarget *models.DeploymentTarget) (*KubeBentoDeploymentDryRunResult, error) {
+	cli, err := DeploymentService.GetKubeBentoDeploymentCli(ctx, deployment)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get kube bento deployment cli")
+	}
+
+	desired, err := s.Render(ctx, deployment, deploymentTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &KubeBentoDeploymentDryRunResult{
+		Desired:  desired,
+		Warnings: make([]string, 0),
+	}
+
+	live, err := cli.Get(ctx, desired.Name, metav1.GetOptions{})
 	isNotFound := apierrors.IsNotFound(err)
 	if err != nil && !isNotFound {
-		err = errors.Wrap(err, "failed to get kube bento deployment")
-		return
+		return nil, errors.Wrap(err, "failed to get kube bento deployment")
 	}
+
 	if isNotFound {
-		kubeBentoDeployment, err = cli.Create(ctx, kubeBentoDeployment, metav1.CreateOptions{})
+		kubeCli, _, err := DeploymentService.GetKubeCliSet(ctx, deployment)
 		if err != nil {
-			err = errors.Wrapf(err, "failed to create kube bento deployment %s", kubeBentoDeployment.Name)
-			return
+			return nil, err
+		}
+		_, err = kubeCli.CoreV1().Namespaces().Get(ctx, desired.Namespace, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("namespace %s does not exist yet, it will be created by the deployment, so the server-side dry run was skipped", desired.Namespace))
+			return res, nil
 		}
-	} else {
-		kubeBentoDeployment.SetResourceVersion(oldKubeBentoDeployment.GetResourceVersion())
-		kubeBentoDeployment, err = cli.Update(ctx, kubeBentoDeployment, metav1.UpdateOptions{})
 		if err != nil {
-			err = errors.Wrapf(err, "failed to update kube bento deployment %s", kubeBentoDeployment.Name)
-			return
+			return nil, errors.Wrapf(err, "failed to get kube namespace %s", desired.Namespace)
 		}
 	}
-	return
+
+	var dryRunned *servingv1alpha2.BentoDeployment
+	if isNotFound {
+		dryRunned, err = cli.Create(ctx, desired, metav1.CreateOptions{
+			DryRun: []string{metav1.DryRunAll},
+		})
+	} else {
+		res.Live = live
+		desired.SetResourceVersion(live.GetResourceVersion())
+		dryRunned, err = cli.Update(ctx, desired, metav1.UpdateOptions{
+			DryRun: []string{metav1.DryRunAll},
+		})
+	}
+	if err != nil {
+		// the rejections of the api server and of the admission webhooks are part of the result, everything else is a failure of the dry run itself
+		if _, ok := err.(apierrors.APIStatus); ok {
+			res.AdmissionError = err.Error()
+			return res, nil
+		}
+		return nil, errors.Wrapf(err, "failed to dry run kube bento deployment %s", desired.Name)
+	}
+	res.Desired = dryRunned
+	return res, nil
 }
@@ -14,8 +14,11 @@ import (
 	commonconsts "github.com/bentoml/yatai-common/consts"
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/common/consts"
 	"github.com/bentoml/yatai/common/utils"
+
+	servingv1alpha2 "github.com/bentoml/yatai-deployment/apis/serving/v1alpha2"
 )
 
 type kubeIngressService struct{}
@@ -165,3 +168,143 @@ func (s *kubeIngressService) DeployDeploymentTargetAsKubeIngresses(ctx context.C
 	}
 	return nil
 }
+
+func (s *kubeIngressService) GetCustomKubeIngressName(deployment *models.Deployment) string {
+	return fmt.Sprintf("%s-custom-domains", deployment.Name)
+}
+
+// ToCustomKubeIngress renders the ingress of the custom hostnames of the stable target,
+// it routes to the service yatai-deployment creates for the BentoDeployment and is owned by it
+func (s *kubeIngressService) ToCustomKubeIngress(ctx context.Context, deployment *models.Deployment, deploymentTarget *models.DeploymentTarget, kubeBentoDeployment *servingv1alpha2.BentoDeployment) (*v1.Ingress, error) {
+	ingress := deploymentTarget.Ingress
+
+	labels, err := DeploymentTargetService.GetKubeLabels(ctx, deploymentTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := make(map[string]string, len(ingress.Annotations)+1)
+	for k, v := range ingress.Annotations {
+		annotations[k] = v
+	}
+
+	var tls []v1.IngressTLS
+	if ingress.TLS != nil {
+		if ingress.TLS.Issuer != "" {
+			annotations[schemas.KubeAnnotationCertManagerIssuer] = ingress.TLS.Issuer
+		}
+		if ingress.TLS.ClusterIssuer != "" {
+			annotations[schemas.KubeAnnotationCertManagerClusterIssuer] = ingress.TLS.ClusterIssuer
+		}
+		secretName := ingress.TLS.SecretName
+		if secretName == "" {
+			secretName = fmt.Sprintf("%s-tls", deployment.Name)
+		}
+		tls = []v1.IngressTLS{
+			{
+				Hosts:      ingress.Hostnames,
+				SecretName: secretName,
+			},
+		}
+	}
+
+	pathType := v1.PathTypePrefix
+	rules := make([]v1.IngressRule, 0, len(ingress.Hostnames))
+	for _, hostname := range ingress.Hostnames {
+		rules = append(rules, v1.IngressRule{
+			Host: hostname,
+			IngressRuleValue: v1.IngressRuleValue{
+				HTTP: &v1.HTTPIngressRuleValue{
+					Paths: []v1.HTTPIngressPath{
+						{
+							Path:     ingress.GetPathPrefix(),
+							PathType: &pathType,
+							Backend: v1.IngressBackend{
+								Service: &v1.IngressServiceBackend{
+									Name: deployment.Name,
+									Port: v1.ServiceBackendPort{
+										Number: consts.BentoServicePort,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	return &v1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        s.GetCustomKubeIngressName(deployment),
+			Namespace:   DeploymentService.GetKubeNamespace(deployment),
+			Labels:      labels,
+			Annotations: annotations,
+			// the ingress is not controlled by the BentoDeployment, yatai-deployment would otherwise reconcile it away
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: servingv1alpha2.GroupVersion.String(),
+					Kind:       "BentoDeployment",
+					Name:       kubeBentoDeployment.Name,
+					UID:        kubeBentoDeployment.UID,
+				},
+			},
+		},
+		Spec: v1.IngressSpec{
+			IngressClassName: utils.StringPtr(commonconsts.KubeIngressClassName),
+			TLS:              tls,
+			Rules:            rules,
+		},
+	}, nil
+}
+
+// DeployCustomKubeIngress creates or updates the ingress of the custom hostnames of the target,
+// it is deleted when the target has none, the canary targets are served on the hostnames of the stable target
+func (s *kubeIngressService) DeployCustomKubeIngress(ctx context.Context, deploymentTarget *models.DeploymentTarget, kubeBentoDeployment *servingv1alpha2.BentoDeployment) error {
+	if deploymentTarget.Type == modelschemas.DeploymentTargetTypeCanary {
+		return nil
+	}
+
+	deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentTarget)
+	if err != nil {
+		return errors.Wrap(err, "get deployment")
+	}
+
+	ingressesCli, err := DeploymentService.GetKubeIngressesCli(ctx, deployment)
+	if err != nil {
+		return errors.Wrap(err, "get kube ingresses cli")
+	}
+
+	kubeName := s.GetCustomKubeIngressName(deployment)
+	oldKubeIng, err := ingressesCli.Get(ctx, kubeName, metav1.GetOptions{})
+	notFound := apierrors.IsNotFound(err)
+	if !notFound && err != nil {
+		return errors.Wrapf(err, "get k8s ingress %s", kubeName)
+	}
+
+	if deploymentTarget.Ingress == nil {
+		if notFound {
+			return nil
+		}
+		logrus.Infof("delete k8s ingress %s ...", kubeName)
+		err = ingressesCli.Delete(ctx, kubeName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "delete k8s ingress %s", kubeName)
+		}
+		return nil
+	}
+
+	kubeIng, err := s.ToCustomKubeIngress(ctx, deployment, deploymentTarget, kubeBentoDeployment)
+	if err != nil {
+		return err
+	}
+	if notFound {
+		logrus.Infof("create k8s ingress %s ...", kubeName)
+		_, err = ingressesCli.Create(ctx, kubeIng, metav1.CreateOptions{})
+		return errors.Wrapf(err, "create k8s ingress %s", kubeName)
+	}
+	logrus.Infof("update k8s ingress %s ...", kubeName)
+	kubeIng.SetResourceVersion(oldKubeIng.GetResourceVersion())
+	_, err = ingressesCli.Update(ctx, kubeIng, metav1.UpdateOptions{})
+	return errors.Wrapf(err, "update k8s ingress %s", kubeName)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/networking/v1"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+
+	servingv1alpha2 "github.com/bentoml/yatai-deployment/apis/serving/v1alpha2"
+)
+
+func TestToCustomKubeIngress(t *testing.T) {
+	deployment := &models.Deployment{
+		ResourceMixin: models.ResourceMixin{Name: "iris"},
+		KubeNamespace: "yatai",
+	}
+	deploymentTarget := &models.DeploymentTarget{
+		DeploymentAssociate: models.DeploymentAssociate{
+			AssociatedDeploymentCache: deployment,
+		},
+		Type: modelschemas.DeploymentTargetTypeStable,
+		Ingress: &schemas.DeploymentTargetIngressSchema{
+			Hostnames:   []string{"iris.example.com", "api.example.com"},
+			PathPrefix:  "/iris",
+			TLS:         &schemas.DeploymentTargetIngressTLSSchema{ClusterIssuer: "letsencrypt"},
+			Annotations: map[string]string{"nginx.ingress.kubernetes.io/limit-rps": "10"},
+		},
+	}
+	kubeBentoDeployment := &servingv1alpha2.BentoDeployment{}
+	kubeBentoDeployment.Name = "iris"
+	kubeBentoDeployment.UID = "uid"
+
+	kubeIng, err := KubeIngressService.ToCustomKubeIngress(context.Background(), deployment, deploymentTarget, kubeBentoDeployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kubeIng.Name != "iris-custom-domains" || kubeIng.Namespace != "yatai" {
+		t.Errorf("unexpected ingress %s/%s", kubeIng.Namespace, kubeIng.Name)
+	}
+	if kubeIng.Annotations[schemas.KubeAnnotationCertManagerClusterIssuer] != "letsencrypt" || kubeIng.Annotations["nginx.ingress.kubernetes.io/limit-rps"] != "10" {
+		t.Errorf("expected the issuer and the annotations of the target, got %v", kubeIng.Annotations)
+	}
+	if len(kubeIng.Spec.TLS) != 1 || kubeIng.Spec.TLS[0].SecretName != "iris-tls" || len(kubeIng.Spec.TLS[0].Hosts) != 2 {
+		t.Errorf("expected the certificate to be issued into the default secret, got %+v", kubeIng.Spec.TLS)
+	}
+	if len(kubeIng.Spec.Rules) != 2 {
+		t.Fatalf("expected a rule per hostname, got %d", len(kubeIng.Spec.Rules))
+	}
+	path := kubeIng.Spec.Rules[1].HTTP.Paths[0]
+	if kubeIng.Spec.Rules[1].Host != "api.example.com" || path.Path != "/iris" || *path.PathType != v1.PathTypePrefix || path.Backend.Service.Name != "iris" {
+		t.Errorf("unexpected rule %+v", kubeIng.Spec.Rules[1])
+	}
+	if len(kubeIng.OwnerReferences) != 1 || kubeIng.OwnerReferences[0].UID != "uid" || kubeIng.OwnerReferences[0].Controller != nil {
+		t.Errorf("expected the ingress to be owned but not controlled by the BentoDeployment, got %+v", kubeIng.OwnerReferences)
+	}
+}
+
+func TestValidateDeploymentTargetIngress(t *testing.T) {
+	ingress := &schemas.DeploymentTargetIngressSchema{
+		Hostnames: []string{"iris.example.com", "*.iris.example.com"},
+		TLS:       &schemas.DeploymentTargetIngressTLSSchema{SecretName: "iris-cert"},
+	}
+	if err := ingress.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if urls := ingress.GetURLs(); len(urls) != 2 || urls[0] != "https://iris.example.com" {
+		t.Errorf("expected https urls, got %v", urls)
+	}
+
+	invalids := []*schemas.DeploymentTargetIngressSchema{
+		{},
+		{Hostnames: []string{"Iris_Example"}},
+		{Hostnames: []string{"iris.example.com", "iris.example.com"}},
+		{Hostnames: []string{"iris.example.com"}, PathPrefix: "iris"},
+		{Hostnames: []string{"iris.example.com"}, TLS: &schemas.DeploymentTargetIngressTLSSchema{}},
+		{Hostnames: []string{"iris.example.com"}, TLS: &schemas.DeploymentTargetIngressTLSSchema{Issuer: "a", ClusterIssuer: "b"}},
+		{Hostnames: []string{"iris.example.com"}, Annotations: map[string]string{"not an annotation": ""}},
+	}
+	for idx, ingress := range invalids {
+		if err := ingress.Validate(); err == nil {
+			t.Errorf("expected the ingress %d to be invalid", idx)
+		}
+	}
+}
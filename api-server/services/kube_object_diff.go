@@ -0,0 +1,94 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+// DiffKubeObjects returns the fields that change when going from the live object to the desired one,
+// both objects are compared through their json representation and the result is sorted by path
+func DiffKubeObjects(live, desired interface{}) ([]*schemas.KubeObjectDiffItemSchema, error) {
+	liveValue, err := toJsonValue(live)
+	if err != nil {
+		return nil, errors.Wrap(err, "convert live object")
+	}
+	desiredValue, err := toJsonValue(desired)
+	if err != nil {
+		return nil, errors.Wrap(err, "convert desired object")
+	}
+	res := make([]*schemas.KubeObjectDiffItemSchema, 0)
+	diffJsonValues("", liveValue, desiredValue, &res)
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+	return res, nil
+}
+
+func toJsonValue(obj interface{}) (interface{}, error) {
+	if obj == nil || (reflect.ValueOf(obj).Kind() == reflect.Ptr && reflect.ValueOf(obj).IsNil()) {
+		return nil, nil
+	}
+	content, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var res interface{}
+	err = json.Unmarshal(content, &res)
+	return res, err
+}
+
+func joinDiffPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func diffJsonValues(path string, from, to interface{}, res *[]*schemas.KubeObjectDiffItemSchema) {
+	if reflect.DeepEqual(from, to) {
+		return
+	}
+	if from == nil {
+		*res = append(*res, &schemas.KubeObjectDiffItemSchema{Path: path, Operation: schemas.KubeObjectDiffOperationAdded, To: to})
+		return
+	}
+	if to == nil {
+		*res = append(*res, &schemas.KubeObjectDiffItemSchema{Path: path, Operation: schemas.KubeObjectDiffOperationRemoved, From: from})
+		return
+	}
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		for key, fromItem := range fromMap {
+			diffJsonValues(joinDiffPath(path, key), fromItem, toMap[key], res)
+		}
+		for key, toItem := range toMap {
+			if _, ok := fromMap[key]; !ok {
+				diffJsonValues(joinDiffPath(path, key), nil, toItem, res)
+			}
+		}
+		return
+	}
+	fromSlice, fromIsSlice := from.([]interface{})
+	toSlice, toIsSlice := to.([]interface{})
+	if fromIsSlice && toIsSlice {
+		for idx := 0; idx < len(fromSlice) || idx < len(toSlice); idx++ {
+			var fromItem, toItem interface{}
+			if idx < len(fromSlice) {
+				fromItem = fromSlice[idx]
+			}
+			if idx < len(toSlice) {
+				toItem = toSlice[idx]
+			}
+			diffJsonValues(fmt.Sprintf("%s[%d]", path, idx), fromItem, toItem, res)
+		}
+		return
+	}
+	*res = append(*res, &schemas.KubeObjectDiffItemSchema{Path: path, Operation: schemas.KubeObjectDiffOperationChanged, From: from, To: to})
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+func TestDiffKubeObjects(t *testing.T) {
+	live := map[string]interface{}{
+		"bento_tag": "iris:v1",
+		"envs":      []interface{}{map[string]interface{}{"key": "A", "value": "1"}},
+		"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "1000m", "memory": "2G"}},
+	}
+	desired := map[string]interface{}{
+		"bento_tag": "iris:v2",
+		"envs":      []interface{}{map[string]interface{}{"key": "A", "value": "1"}, map[string]interface{}{"key": "B", "value": "2"}},
+		"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "1000m"}},
+		"ingress":   map[string]interface{}{"enabled": true},
+	}
+	diff, err := DiffKubeObjects(live, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*schemas.KubeObjectDiffItemSchema{
+		{Path: "bento_tag", Operation: schemas.KubeObjectDiffOperationChanged, From: "iris:v1", To: "iris:v2"},
+		{Path: "envs[1]", Operation: schemas.KubeObjectDiffOperationAdded, To: map[string]interface{}{"key": "B", "value": "2"}},
+		{Path: "ingress", Operation: schemas.KubeObjectDiffOperationAdded, To: map[string]interface{}{"enabled": true}},
+		{Path: "resources.limits.memory", Operation: schemas.KubeObjectDiffOperationRemoved, From: "2G"},
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		for _, item := range diff {
+			t.Logf("%+v", item)
+		}
+		t.Errorf("unexpected diff")
+	}
+
+	diff, err = DiffKubeObjects(desired, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 0 {
+		t.Errorf("identical objects should have no diff, got %d items", len(diff))
+	}
+}
@@ -143,6 +143,33 @@ func (s *labelService) Delete(ctx context.Context, label *models.Label) (*models
 	return label, s.getBaseDB(ctx).Unscoped().Delete(label).Error
 }
 
+func (s *labelService) DeleteByResource(ctx context.Context, organizationId uint, resource models.IResource) error {
+	return s.getBaseDB(ctx).Unscoped().
+		Where("organization_id = ?", organizationId).
+		Where("resource_type = ?", resource.GetResourceType()).
+		Where("resource_id = ?", resource.GetId()).
+		Delete(&models.Label{}).Error
+}
+
+func (s *labelService) ListLabelItemsByResource(ctx context.Context, organizationId uint, resource models.IResource) (modelschemas.LabelItemsSchema, error) {
+	labels, _, err := s.List(ctx, ListLabelOption{
+		OrganizationId: &organizationId,
+		ResourceType:   resource.GetResourceType().Ptr(),
+		ResourceId:     utils.UintPtr(resource.GetId()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make(modelschemas.LabelItemsSchema, 0, len(labels))
+	for _, label := range labels {
+		items = append(items, modelschemas.LabelItemSchema{
+			Key:   label.Key,
+			Value: label.Value,
+		})
+	}
+	return items, nil
+}
+
 func (s *labelService) List(ctx context.Context, opt ListLabelOption) ([]*models.Label, uint, error) {
 	query := getBaseQuery(ctx, s)
 
@@ -2,16 +2,15 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/iancoleman/strcase"
-	"github.com/minio/minio-go/v7"
 	"github.com/pkg/errors"
 	"github.com/rs/xid"
 	"github.com/sirupsen/logrus"
@@ -21,6 +20,7 @@ import (
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai/api-server/models"
 	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
 )
 
 type modelService struct{}
@@ -49,6 +49,7 @@ type UpdateModelOption struct {
 	UploadStartedAt           **time.Time
 	UploadFinishedAt          **time.Time
 	UploadFinishedReason      *string
+	Checksum                  *string
 	Labels                    *modelschemas.LabelItemsSchema
 }
 
@@ -65,6 +66,10 @@ type ListModelOption struct {
 	Order             *string
 	Names             *[]string
 	Modules           *[]string
+	// Unreferenced keeps the models that no bento refers to, neither by bento_model_rel nor by its manifest
+	Unreferenced        *bool
+	UploadStatus        *modelschemas.ModelUploadStatus
+	UploadStartedBefore *time.Time
 }
 
 func (s *modelService) Create(ctx context.Context, opt CreateModelOption) (model *models.Model, err error) {
@@ -113,230 +118,286 @@ func (s *modelService) Create(ctx context.Context, opt CreateModelOption) (model
 	return
 }
 
-func (s *modelService) StartMultipartUpload(ctx context.Context, model *models.Model) (uploadId string, err error) {
+func (s *modelService) getStorageDriver(ctx context.Context, model *models.Model) (StorageDriver, error) {
 	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
 	if err != nil {
-		return
+		return nil, err
 	}
 	org, err := OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
 	if err != nil {
-		return
+		return nil, err
 	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
+	return OrganizationService.GetStorageDriver(ctx, org, StorageBucketTypeModels)
+}
+
+func (s *modelService) PreSignUploadUrl(ctx context.Context, model *models.Model) (*url.URL, error) {
+	driver, err := s.getStorageDriver(ctx, model)
 	if err != nil {
-		return
+		return nil, err
 	}
-	minioCore, err := s3Config.GetMinioCore()
+	objectName, err := s.getObjectName(ctx, model)
 	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
-		return
+		return nil, err
 	}
+	return driver.PreSignPutUrl(ctx, objectName, time.Hour)
+}
 
-	bucketName, err := s.GetS3BucketName(ctx, model)
+func (s *modelService) StartMultipartUpload(ctx context.Context, model *models.Model) (string, error) {
+	driver, err := s.getStorageDriver(ctx, model)
 	if err != nil {
-		return
+		return "", err
 	}
-
-	err = s3Config.MakeSureBucket(ctx, bucketName)
+	objectName, err := s.getObjectName(ctx, model)
 	if err != nil {
-		return
+		return "", err
 	}
+	return driver.StartMultipartUpload(ctx, objectName)
+}
 
-	objectName, err := s.getS3ObjectName(ctx, model)
+func (s *modelService) PreSignMultipartUploadUrl(ctx context.Context, model *models.Model, partNumber int, uploadId string) (*url.URL, error) {
+	driver, err := s.getStorageDriver(ctx, model)
 	if err != nil {
-		return
+		return nil, err
 	}
-
-	uploadId, err = minioCore.NewMultipartUpload(ctx, bucketName, objectName, minio.PutObjectOptions{})
+	objectName, err := s.getObjectName(ctx, model)
 	if err != nil {
-		err = errors.Wrap(err, "new multipart upload")
-		return
+		return nil, err
 	}
-	return
+	return driver.PreSignMultipartUploadUrl(ctx, objectName, uploadId, partNumber, time.Hour)
 }
 
-func (s *modelService) PreSignMultipartUploadUrl(ctx context.Context, model *models.Model, partNumber int, uploadId string) (url_ *url.URL, err error) {
-	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
+func (s *modelService) UploadMultipartPart(ctx context.Context, model *models.Model, partNumber int, uploadId string, reader io.Reader, partSize int64) (string, error) {
+	driver, err := s.getStorageDriver(ctx, model)
 	if err != nil {
-		return
+		return "", err
 	}
-	org, err := OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
+	objectName, err := s.getObjectName(ctx, model)
 	if err != nil {
-		return
+		return "", err
 	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
+	return driver.PutMultipartUploadPart(ctx, objectName, uploadId, partNumber, reader, partSize)
+}
+
+func (s *modelService) CompleteMultipartUpload(ctx context.Context, model *models.Model, uploadId string, parts []StorageCompletePart) (err error) {
+	driver, err := s.getStorageDriver(ctx, model)
 	if err != nil {
 		return
 	}
-	minioCore, err := s3Config.GetMinioCore()
+	objectName, err := s.getObjectName(ctx, model)
 	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
 		return
 	}
+	err = driver.CompleteMultipartUpload(ctx, objectName, uploadId, parts)
+	return
+}
 
-	bucketName, err := s.GetS3BucketName(ctx, model)
+func (s *modelService) Upload(ctx context.Context, model *models.Model, reader io.Reader, objectSize int64) (err error) {
+	driver, err := s.getStorageDriver(ctx, model)
 	if err != nil {
 		return
 	}
-
-	err = s3Config.MakeSureBucket(ctx, bucketName)
+	objectName, err := s.getObjectName(ctx, model)
 	if err != nil {
 		return
 	}
 
-	objectName, err := s.getS3ObjectName(ctx, model)
+	hash := sha256.New()
+	err = driver.Put(ctx, objectName, io.TeeReader(reader, hash), objectSize)
 	if err != nil {
 		return
 	}
 
-	queryValues := make(url.Values)
-	queryValues.Set("partNumber", strconv.Itoa(partNumber))
-	queryValues.Set("uploadId", uploadId)
-
-	url_, err = minioCore.Presign(ctx, http.MethodPut, bucketName, objectName, time.Hour, queryValues)
-	if err != nil {
-		err = errors.Wrap(err, "presigned put object")
-		return
-	}
-	if s3Config.Endpoint != s3Config.EndpointInCluster {
-		url_.Host = s3Config.Endpoint
-	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	_, err = s.Update(ctx, model, UpdateModelOption{
+		Checksum: &checksum,
+	})
 	return
 }
 
-func (s *modelService) CompleteMultipartUpload(ctx context.Context, model *models.Model, uploadId string, parts []minio.CompletePart) (err error) {
-	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
+func (s *modelService) PreSignDownloadUrl(ctx context.Context, model *models.Model) (*url.URL, error) {
+	driver, err := s.getStorageDriver(ctx, model)
 	if err != nil {
-		return
+		return nil, err
 	}
-	org, err := OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
+	objectName, err := s.getObjectName(ctx, model)
 	if err != nil {
-		return
+		return nil, err
 	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
+	return driver.PreSignGetUrl(ctx, objectName, time.Hour)
+}
+
+func (s *modelService) StatObject(ctx context.Context, model *models.Model) (*StorageObjectInfo, error) {
+	driver, err := s.getStorageDriver(ctx, model)
 	if err != nil {
-		return
+		return nil, err
 	}
-	minioCore, err := s3Config.GetMinioCore()
+	objectName, err := s.getObjectName(ctx, model)
 	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
-		return
+		return nil, err
 	}
+	return driver.Stat(ctx, objectName)
+}
 
-	bucketName, err := s.GetS3BucketName(ctx, model)
-	if err != nil {
-		return
-	}
+func (s *modelService) Download(ctx context.Context, model *models.Model, writer io.Writer) error {
+	return s.DownloadWithOption(ctx, model, writer, StorageGetOption{})
+}
 
-	err = s3Config.MakeSureBucket(ctx, bucketName)
+func (s *modelService) DownloadWithOption(ctx context.Context, model *models.Model, writer io.Writer, opt StorageGetOption) error {
+	driver, err := s.getStorageDriver(ctx, model)
 	if err != nil {
-		return
+		return err
 	}
-
-	objectName, err := s.getS3ObjectName(ctx, model)
+	objectName, err := s.getObjectName(ctx, model)
 	if err != nil {
-		return
+		return err
 	}
+	return driver.Get(ctx, objectName, writer, opt)
+}
 
-	_, err = minioCore.CompleteMultipartUpload(ctx, bucketName, objectName, uploadId, parts, minio.PutObjectOptions{})
+// ComputeChecksum reads the stored object back and returns its hex encoded sha256
+func (s *modelService) ComputeChecksum(ctx context.Context, model *models.Model) (checksum string, err error) {
+	hash := sha256.New()
+	err = s.Download(ctx, model, hash)
 	if err != nil {
-		err = errors.Wrap(err, "new multipart upload")
 		return
 	}
+	checksum = hex.EncodeToString(hash.Sum(nil))
 	return
 }
 
-func (s *modelService) Upload(ctx context.Context, model *models.Model, reader io.Reader, objectSize int64) (err error) {
-	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
-	if err != nil {
-		return
-	}
-	org, err := OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
-	if err != nil {
-		return
-	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
+// GetReferencingBento returns a bento referencing the model by bento_model_rel or manifest, nil if none
+func (s *modelService) GetReferencingBento(ctx context.Context, model *models.Model) (*models.Bento, error) {
+	bentos, _, err := BentoService.List(ctx, ListBentoOption{
+		BaseListOption: BaseListOption{
+			Start: utils.UintPtr(0),
+			Count: utils.UintPtr(1),
+		},
+		ModelIds: &[]uint{model.ID},
+	})
 	if err != nil {
-		return
+		return nil, errors.Wrap(err, "list bentos by model")
 	}
-	minioClient, err := s3Config.GetMinioClient()
-	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
-		return
+	if len(bentos) > 0 {
+		return bentos[0], nil
 	}
-
-	bucketName, err := s.GetS3BucketName(ctx, model)
+	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
 	if err != nil {
-		return
+		return nil, err
 	}
-
-	err = s3Config.MakeSureBucket(ctx, bucketName)
+	tag := fmt.Sprintf("%s:%s", modelRepository.Name, model.Version)
+	bentos, _, err = BentoService.List(ctx, ListBentoOption{
+		BaseListOption: BaseListOption{
+			Start: utils.UintPtr(0),
+			Count: utils.UintPtr(1),
+		},
+		OrganizationId:   utils.UintPtr(modelRepository.OrganizationId),
+		ManifestModelTag: &tag,
+	})
 	if err != nil {
-		return
+		return nil, errors.Wrap(err, "list bentos by manifest")
 	}
-
-	objectName, err := s.getS3ObjectName(ctx, model)
-	if err != nil {
-		return
+	if len(bentos) > 0 {
+		return bentos[0], nil
 	}
+	return nil, nil
+}
 
-	logrus.Debugf("uploading to s3: %s/%s", bucketName, objectName)
-	_, err = minioClient.PutObject(ctx, bucketName, objectName, reader, objectSize, minio.PutObjectOptions{ContentType: "application/octet-stream"})
+// ListReferencingBentos returns every bento that refers to the model, either by bento_model_rel or by its manifest
+func (s *modelService) ListReferencingBentos(ctx context.Context, model *models.Model) ([]*models.Bento, error) {
+	bentos, _, err := BentoService.List(ctx, ListBentoOption{
+		ModelIds: &[]uint{model.ID},
+	})
 	if err != nil {
-		err = errors.Wrap(err, "put object")
-		return
+		return nil, errors.Wrap(err, "list bentos by model")
 	}
-
-	logrus.Debugf("uploaded to s3: %s/%s", bucketName, objectName)
-	return
-}
-
-func (s *modelService) PreSignUploadUrl(ctx context.Context, model *models.Model) (url *url.URL, err error) {
 	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
 	if err != nil {
-		return
+		return nil, err
 	}
-	org, err := OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
+	tag := fmt.Sprintf("%s:%s", modelRepository.Name, model.Version)
+	manifestBentos, _, err := BentoService.List(ctx, ListBentoOption{
+		OrganizationId:   utils.UintPtr(modelRepository.OrganizationId),
+		ManifestModelTag: &tag,
+	})
 	if err != nil {
-		return
+		return nil, errors.Wrap(err, "list bentos by manifest")
 	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
-	if err != nil {
-		return
+	bentoIdsSeen := make(map[uint]struct{}, len(bentos))
+	for _, bento := range bentos {
+		bentoIdsSeen[bento.ID] = struct{}{}
 	}
-	minioClient, err := s3Config.GetMinioClient()
-	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
-		return
+	for _, bento := range manifestBentos {
+		if _, ok := bentoIdsSeen[bento.ID]; ok {
+			continue
+		}
+		bentoIdsSeen[bento.ID] = struct{}{}
+		bentos = append(bentos, bento)
 	}
+	return bentos, nil
+}
 
-	bucketName, err := s.GetS3BucketName(ctx, model)
-	if err != nil {
-		return
-	}
+type ModelImpactDeployment struct {
+	Cluster          *models.Cluster
+	Deployment       *models.Deployment
+	DeploymentTarget *models.DeploymentTarget
+}
 
-	err = s3Config.MakeSureBucket(ctx, bucketName)
-	if err != nil {
-		return
-	}
+type ModelImpactBento struct {
+	Bento       *models.Bento
+	Deployments []*ModelImpactDeployment
+}
 
-	objectName, err := s.getS3ObjectName(ctx, model)
+// GetImpact walks model -> bentos -> active deployment targets -> clusters, it answers what breaks if the model is retired
+func (s *modelService) GetImpact(ctx context.Context, model *models.Model) ([]*ModelImpactBento, error) {
+	bentos, err := s.ListReferencingBentos(ctx, model)
 	if err != nil {
-		return
+		return nil, err
 	}
-
-	url, err = minioClient.PresignedPutObject(ctx, bucketName, objectName, time.Hour)
+	res := make([]*ModelImpactBento, 0, len(bentos))
+	if len(bentos) == 0 {
+		return res, nil
+	}
+	bentoIds := make([]uint, 0, len(bentos))
+	for _, bento := range bentos {
+		bentoIds = append(bentoIds, bento.ID)
+	}
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		DeploymentRevisionStatus: modelschemas.DeploymentRevisionStatusActive.Ptr(),
+		BentoIds:                 &bentoIds,
+	})
 	if err != nil {
-		err = errors.Wrap(err, "presigned put object")
-		return
+		return nil, errors.Wrap(err, "list active deployment targets")
 	}
-	if s3Config.Endpoint != s3Config.EndpointInCluster {
-		url.Host = s3Config.Endpoint
+	deploymentsMapping := make(map[uint][]*ModelImpactDeployment, len(bentos))
+	for _, deploymentTarget := range deploymentTargets {
+		deployment, err := DeploymentService.GetAssociatedDeployment(ctx, deploymentTarget)
+		if err != nil {
+			return nil, err
+		}
+		cluster, err := ClusterService.GetAssociatedCluster(ctx, deployment)
+		if err != nil {
+			return nil, err
+		}
+		deploymentsMapping[deploymentTarget.BentoId] = append(deploymentsMapping[deploymentTarget.BentoId], &ModelImpactDeployment{
+			Cluster:          cluster,
+			Deployment:       deployment,
+			DeploymentTarget: deploymentTarget,
+		})
+	}
+	for _, bento := range bentos {
+		deployments := deploymentsMapping[bento.ID]
+		if deployments == nil {
+			deployments = make([]*ModelImpactDeployment, 0)
+		}
+		res = append(res, &ModelImpactBento{
+			Bento:       bento,
+			Deployments: deployments,
+		})
 	}
-	return
+	return res, nil
 }
 
-func (s *modelService) Download(ctx context.Context, model *models.Model, writer io.Writer) (err error) {
+func (s *modelService) Delete(ctx context.Context, model *models.Model) (m *models.Model, err error) {
 	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
 	if err != nil {
 		return
@@ -345,91 +406,67 @@ func (s *modelService) Download(ctx context.Context, model *models.Model, writer
 	if err != nil {
 		return
 	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
-	if err != nil {
-		return
-	}
-	minioClient, err := s3Config.GetMinioClient()
-	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
-		return
-	}
 
-	bucketName, err := s.GetS3BucketName(ctx, model)
+	bento, err := s.GetReferencingBento(ctx, model)
 	if err != nil {
 		return
 	}
-
-	err = s3Config.MakeSureBucket(ctx, bucketName)
-	if err != nil {
+	if bento != nil {
+		bentoTag, err_ := BentoService.GetTag(ctx, bento)
+		if err_ != nil {
+			err = err_
+			return
+		}
+		err = errors.Errorf("model %s is referenced by bento %s, cannot delete it", model.Version, bentoTag)
 		return
 	}
-
-	objectName, err := s.getS3ObjectName(ctx, model)
+	aliasNames, err := RepositoryAliasService.ListAliasNamesByTargetIds(ctx, modelschemas.ResourceTypeModelRepository, []uint{model.ID})
 	if err != nil {
+		err = errors.Wrap(err, "list aliases")
 		return
 	}
-
-	obj, err := minioClient.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
-	if err != nil {
-		err = errors.Wrap(err, "get object")
+	if names := aliasNames[model.ID]; len(names) > 0 {
+		err = errors.Errorf("model %s is pointed at by alias %s, cannot delete it", model.Version, strings.Join(names, ", "))
 		return
 	}
 
-	_, err = io.Copy(writer, obj)
-	if err != nil {
-		err = errors.Wrap(err, "copy object")
-	}
-
-	return
-}
-
-func (s *modelService) PreSignDownloadUrl(ctx context.Context, model *models.Model) (url *url.URL, err error) {
-	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
-	if err != nil {
-		return
-	}
-	org, err := OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
-	if err != nil {
-		return
-	}
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
+	driver, err := OrganizationService.GetStorageDriver(ctx, org, StorageBucketTypeModels)
 	if err != nil {
 		return
 	}
-	minioClient, err := s3Config.GetMinioClient()
+	objectName, err := s.getObjectName(ctx, model)
 	if err != nil {
-		err = errors.Wrap(err, "create s3 client")
 		return
 	}
 
-	bucketName, err := s.GetS3BucketName(ctx, model)
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
 	if err != nil {
 		return
 	}
+	defer func() { df(err) }()
 
-	err = s3Config.MakeSureBucket(ctx, bucketName)
+	err = LabelService.DeleteByResource(ctx, org.ID, model)
 	if err != nil {
 		return
 	}
-
-	objectName, err := s.getS3ObjectName(ctx, model)
+	err = db.Unscoped().Delete(model).Error
 	if err != nil {
 		return
 	}
 
-	url, err = minioClient.PresignedGetObject(ctx, bucketName, objectName, time.Hour, nil)
-	if err != nil {
-		err = errors.Wrap(err, "presigned get object")
-		return
-	}
-	if s3Config.Endpoint != s3Config.EndpointInCluster {
-		url.Host = s3Config.Endpoint
-	}
+	// the archive cannot be restored, so it is only deleted once the row is gone for good
+	runAfterCommit(ctx, func() {
+		if err := driver.Delete(ctx, objectName); err != nil {
+			logrus.Errorf("delete the archive %s of model %s: %v", objectName, model.Version, err)
+		}
+	})
+
+	m = model
 	return
 }
 
-func (s *modelService) getS3ObjectName(ctx context.Context, model *models.Model) (string, error) {
+func (s *modelService) getObjectName(ctx context.Context, model *models.Model) (string, error) {
 	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
 	if err != nil {
 		return "", err
@@ -438,28 +475,11 @@ func (s *modelService) getS3ObjectName(ctx context.Context, model *models.Model)
 	if err != nil {
 		return "", err
 	}
-	objectName := fmt.Sprintf("models/%s/%s/%s.tar.gz", org.Name, modelRepository.Name, model.Version)
-	return objectName, nil
+	return s.formatObjectName(org, modelRepository.Name, model.Version), nil
 }
 
-func (s *modelService) GetS3BucketName(ctx context.Context, model *models.Model) (string, error) {
-	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
-	if err != nil {
-		return "", err
-	}
-	org, err := OrganizationService.GetAssociatedOrganization(ctx, modelRepository)
-	if err != nil {
-		return "", err
-	}
-
-	s3Config, err := OrganizationService.GetS3Config(ctx, org)
-	if err != nil {
-		return "", err
-	}
-
-	s3BucketName := s3Config.ModelsBucketName
-
-	return s3BucketName, nil
+func (s *modelService) formatObjectName(org *models.Organization, modelRepositoryName, version string) string {
+	return fmt.Sprintf("models/%s/%s/%s.tar.gz", org.Name, modelRepositoryName, version)
 }
 
 func (s *modelService) GetTag(ctx context.Context, model *models.Model) (modelschemas.Tag, error) {
@@ -529,6 +549,14 @@ func (s *modelService) Update(ctx context.Context, model *models.Model, opt Upda
 			}
 		}()
 	}
+	if opt.Checksum != nil {
+		updaters["checksum"] = *opt.Checksum
+		defer func() {
+			if err == nil {
+				model.Checksum = *opt.Checksum
+			}
+		}()
+	}
 
 	// nolint: ineffassign,staticcheck
 	db, ctx, df, err := startTransaction(ctx)
@@ -621,6 +649,22 @@ func (s *modelService) GetByVersion(ctx context.Context, modelRepositoryId uint,
 	return &model, nil
 }
 
+// GetByVersionOrAlias looks the version up first and falls back to the aliases of the repository
+func (s *modelService) GetByVersionOrAlias(ctx context.Context, modelRepository *models.ModelRepository, versionOrAlias string) (*models.Model, error) {
+	model, err := s.GetByVersion(ctx, modelRepository.ID, versionOrAlias)
+	if err == nil || !utils.IsNotFound(err) {
+		return model, err
+	}
+	alias, err_ := RepositoryAliasService.GetByName(ctx, modelRepository, versionOrAlias)
+	if err_ != nil {
+		if utils.IsNotFound(err_) {
+			return nil, err
+		}
+		return nil, errors.Wrapf(err_, "get alias %s", versionOrAlias)
+	}
+	return s.Get(ctx, alias.TargetId)
+}
+
 func (s *modelService) ListByUids(ctx context.Context, uids []string) ([]*models.Model, error) {
 	models_ := make([]*models.Model, 0, len(uids))
 	if len(uids) == 0 {
@@ -668,6 +712,18 @@ func (s *modelService) List(ctx context.Context, opt ListModelOption) ([]*models
 	if opt.Modules != nil {
 		query = query.Where("model.manifest->>'module' in (?)", *opt.Modules)
 	}
+	if opt.Unreferenced != nil && *opt.Unreferenced {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM bento_model_rel WHERE bento_model_rel.model_id = model.id)")
+		query = query.Where(`NOT EXISTS (SELECT 1 FROM bento JOIN bento_repository ON bento.bento_repository_id = bento_repository.id
+			WHERE bento_repository.organization_id = model_repository.organization_id
+			AND jsonb_exists(bento.manifest->'models', model_repository.name || ':' || model.version))`)
+	}
+	if opt.UploadStatus != nil {
+		query = query.Where("model.upload_status = ?", *opt.UploadStatus)
+	}
+	if opt.UploadStartedBefore != nil {
+		query = query.Where("model.upload_started_at < ?", *opt.UploadStartedBefore)
+	}
 	query = opt.BindQueryWithKeywords(query, "model_repository")
 	query = opt.BindQueryWithLabels(query, modelschemas.ResourceTypeModel)
 	query = query.Select("distinct(model.*)")
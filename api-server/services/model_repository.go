@@ -8,6 +8,7 @@ import (
 
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/common/consts"
 )
 
@@ -27,20 +28,22 @@ type CreateModelRepositoryOption struct {
 }
 
 type UpdateModelRepositoryOption struct {
-	Description *string
-	Labels      *modelschemas.LabelItemsSchema
+	Description     *string
+	Labels          *modelschemas.LabelItemsSchema
+	RetentionPolicy **schemas.RetentionPolicySchema
 }
 
 type ListModelRepositoryOption struct {
 	BaseListOption
 	BaseListByLabelsOption
-	OrganizationId *uint
-	CreatorId      *uint
-	CreatorIds     *[]uint
-	LastUpdaterIds *[]uint
-	Order          *string
-	Names          *[]string
-	Ids            *[]uint
+	OrganizationId     *uint
+	CreatorId          *uint
+	CreatorIds         *[]uint
+	LastUpdaterIds     *[]uint
+	Order              *string
+	Names              *[]string
+	Ids                *[]uint
+	HasRetentionPolicy *bool
 }
 
 func (*modelRepositoryService) Create(ctx context.Context, opt CreateModelRepositoryOption) (*models.ModelRepository, error) {
@@ -74,6 +77,14 @@ func (s *modelRepositoryService) Update(ctx context.Context, modelRepository *mo
 			}
 		}()
 	}
+	if opt.RetentionPolicy != nil {
+		updaters["retention_policy"] = *opt.RetentionPolicy
+		defer func() {
+			if err == nil {
+				modelRepository.RetentionPolicy = *opt.RetentionPolicy
+			}
+		}()
+	}
 	if len(updaters) == 0 {
 		return modelRepository, nil
 	}
@@ -150,6 +161,13 @@ func (s *modelRepositoryService) List(ctx context.Context, opt ListModelReposito
 	if opt.CreatorIds != nil {
 		query = query.Where("model_repository.creator_id in (?)", *opt.CreatorIds)
 	}
+	if opt.HasRetentionPolicy != nil {
+		if *opt.HasRetentionPolicy {
+			query = query.Where("model_repository.retention_policy IS NOT NULL")
+		} else {
+			query = query.Where("model_repository.retention_policy IS NULL")
+		}
+	}
 	query = query.Joins("LEFT JOIN model ON model.model_repository_id = model_repository.id")
 	query = query.Joins("LEFT OUTER JOIN model m2 ON m2.model_repository_id = model_repository.id AND model.id < m2.id")
 	query = query.Where("m2.id IS NULL")
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai/api-server/config"
+)
+
+type prometheusService struct{}
+
+var PrometheusService = prometheusService{}
+
+const prometheusQueryTimeout = 10 * time.Second
+
+func (s *prometheusService) GetEndpoint() string {
+	if config.YataiConfig.Prometheus == nil {
+		return ""
+	}
+	return strings.TrimRight(config.YataiConfig.Prometheus.Endpoint, "/")
+}
+
+// QueryMaxValue runs an instant query and returns the highest sample of the result, nil is returned when the result is empty
+func (s *prometheusService) QueryMaxValue(ctx context.Context, query string) (*float64, error) {
+	endpoint := s.GetEndpoint()
+	if endpoint == "" {
+		return nil, errors.New("prometheus endpoint is not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, prometheusQueryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/api/v1/query?"+url.Values{"query": []string{query}}.Encode(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "new prometheus query request")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "do prometheus query request")
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read prometheus query response")
+	}
+	return parsePrometheusQueryMaxValue(body)
+}
+
+type prometheusQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+func parsePrometheusSampleValue(sample []interface{}) (float64, error) {
+	if len(sample) != 2 {
+		return 0, errors.Errorf("invalid prometheus sample %v", sample)
+	}
+	value, ok := sample[1].(string)
+	if !ok {
+		return 0, errors.Errorf("invalid prometheus sample value %v", sample[1])
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+func parsePrometheusQueryMaxValue(body []byte) (*float64, error) {
+	var resp prometheusQueryResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "unmarshal prometheus query response")
+	}
+	if resp.Status != "success" {
+		return nil, errors.Errorf("prometheus query failed: %s", resp.Error)
+	}
+
+	var values []float64
+	switch resp.Data.ResultType {
+	case "scalar":
+		var sample []interface{}
+		if err := json.Unmarshal(resp.Data.Result, &sample); err != nil {
+			return nil, errors.Wrap(err, "unmarshal prometheus scalar result")
+		}
+		value, err := parsePrometheusSampleValue(sample)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	case "vector":
+		var series []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(resp.Data.Result, &series); err != nil {
+			return nil, errors.Wrap(err, "unmarshal prometheus vector result")
+		}
+		for _, serie := range series {
+			value, err := parsePrometheusSampleValue(serie.Value)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+	default:
+		return nil, errors.Errorf("unsupported prometheus result type %s, the query must return a scalar or an instant vector", resp.Data.ResultType)
+	}
+
+	var res *float64
+	for _, value := range values {
+		value := value
+		if res == nil || value > *res {
+			res = &value
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import "testing"
+
+func TestParsePrometheusQueryMaxValue(t *testing.T) {
+	value, err := parsePrometheusQueryMaxValue([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"pod":"a"},"value":[1666000000.1,"0.25"]},
+		{"metric":{"pod":"b"},"value":[1666000000.1,"0.75"]}
+	]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value == nil || *value != 0.75 {
+		t.Errorf("expected the highest sample 0.75, got %v", value)
+	}
+
+	value, err = parsePrometheusQueryMaxValue([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1666000000.1,"3"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value == nil || *value != 3 {
+		t.Errorf("expected the scalar 3, got %v", value)
+	}
+
+	value, err = parsePrometheusQueryMaxValue([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != nil {
+		t.Errorf("expected no value for an empty vector, got %v", *value)
+	}
+
+	_, err = parsePrometheusQueryMaxValue([]byte(`{"status":"error","error":"parse error"}`))
+	if err == nil {
+		t.Errorf("expected an error for a failed query")
+	}
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type repositoryAliasService struct{}
+
+var RepositoryAliasService = repositoryAliasService{}
+
+func (s *repositoryAliasService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.RepositoryAlias{})
+}
+
+type repositoryAliasHistoryService struct{}
+
+func (s *repositoryAliasHistoryService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.RepositoryAliasHistory{})
+}
+
+type SetRepositoryAliasOption struct {
+	CreatorId      uint
+	OrganizationId uint
+	// Repository is the bento repository or the model repository the alias belongs to
+	Repository models.IResource
+	Name       string
+	// Target is the bento or the model the alias points at
+	Target models.IResource
+}
+
+type ListRepositoryAliasOption struct {
+	BaseListOption
+	ResourceType *modelschemas.ResourceType
+	ResourceId   *uint
+	TargetIds    *[]uint
+}
+
+type ListRepositoryAliasHistoryOption struct {
+	BaseListOption
+	RepositoryAliasId *uint
+}
+
+// Set points the alias at the target, the alias is created if it does not exist yet and every move is recorded in the history
+func (s *repositoryAliasService) Set(ctx context.Context, opt SetRepositoryAliasOption) (alias *models.RepositoryAlias, err error) {
+	errs := validation.IsDNS1035Label(opt.Name)
+	if len(errs) > 0 {
+		err = errors.New(strings.Join(errs, ";"))
+		return
+	}
+
+	// nolint: ineffassign,staticcheck
+	db, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	alias, err = s.GetByName(ctx, opt.Repository, opt.Name)
+	isNotFound := utils.IsNotFound(err)
+	if err != nil && !isNotFound {
+		return
+	}
+	if isNotFound {
+		alias = &models.RepositoryAlias{
+			CreatorAssociate: models.CreatorAssociate{
+				CreatorId: opt.CreatorId,
+			},
+			OrganizationAssociate: models.OrganizationAssociate{
+				OrganizationId: opt.OrganizationId,
+			},
+			ResourceType: opt.Repository.GetResourceType(),
+			ResourceId:   opt.Repository.GetId(),
+			Name:         opt.Name,
+			TargetId:     opt.Target.GetId(),
+		}
+		err = db.Create(alias).Error
+		if err != nil {
+			return
+		}
+	} else {
+		if alias.TargetId == opt.Target.GetId() {
+			return
+		}
+		err = s.getBaseDB(ctx).Where("id = ?", alias.ID).Updates(map[string]interface{}{
+			"target_id": opt.Target.GetId(),
+		}).Error
+		if err != nil {
+			return
+		}
+		alias.TargetId = opt.Target.GetId()
+	}
+
+	history := &models.RepositoryAliasHistory{
+		CreatorAssociate: models.CreatorAssociate{
+			CreatorId: opt.CreatorId,
+		},
+		RepositoryAliasId: alias.ID,
+		TargetId:          opt.Target.GetId(),
+		Version:           opt.Target.GetName(),
+	}
+	err = db.Create(history).Error
+	return
+}
+
+func (s *repositoryAliasService) Get(ctx context.Context, id uint) (*models.RepositoryAlias, error) {
+	var alias models.RepositoryAlias
+	err := getBaseQuery(ctx, s).Where("id = ?", id).First(&alias).Error
+	if err != nil {
+		return nil, err
+	}
+	if alias.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &alias, nil
+}
+
+func (s *repositoryAliasService) GetByName(ctx context.Context, repository models.IResource, name string) (*models.RepositoryAlias, error) {
+	var alias models.RepositoryAlias
+	err := getBaseQuery(ctx, s).
+		Where("resource_type = ?", repository.GetResourceType()).
+		Where("resource_id = ?", repository.GetId()).
+		Where("name = ?", name).
+		First(&alias).Error
+	if err != nil {
+		return nil, err
+	}
+	if alias.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &alias, nil
+}
+
+func (s *repositoryAliasService) List(ctx context.Context, opt ListRepositoryAliasOption) ([]*models.RepositoryAlias, uint, error) {
+	query := getBaseQuery(ctx, s)
+	if opt.ResourceType != nil {
+		query = query.Where("resource_type = ?", *opt.ResourceType)
+	}
+	if opt.ResourceId != nil {
+		query = query.Where("resource_id = ?", *opt.ResourceId)
+	}
+	if opt.TargetIds != nil {
+		query = query.Where("target_id IN (?)", *opt.TargetIds)
+	}
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	aliases := make([]*models.RepositoryAlias, 0)
+	query = opt.BindQueryWithLimit(query)
+	query = query.Order("name ASC")
+	err = query.Find(&aliases).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return aliases, uint(total), err
+}
+
+func (s *repositoryAliasService) Delete(ctx context.Context, alias *models.RepositoryAlias) (*models.RepositoryAlias, error) {
+	return alias, s.getBaseDB(ctx).Unscoped().Delete(alias).Error
+}
+
+func (s *repositoryAliasService) ListHistories(ctx context.Context, opt ListRepositoryAliasHistoryOption) ([]*models.RepositoryAliasHistory, uint, error) {
+	query := getBaseQuery(ctx, &repositoryAliasHistoryService{})
+	if opt.RepositoryAliasId != nil {
+		query = query.Where("repository_alias_id = ?", *opt.RepositoryAliasId)
+	}
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	histories := make([]*models.RepositoryAliasHistory, 0)
+	query = opt.BindQueryWithLimit(query)
+	query = query.Order("id DESC")
+	err = query.Find(&histories).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return histories, uint(total), err
+}
+
+// ListAliasNamesByTargetIds returns the alias names of every target, it is used to keep aliased versions from being deleted
+func (s *repositoryAliasService) ListAliasNamesByTargetIds(ctx context.Context, repositoryResourceType modelschemas.ResourceType, targetIds []uint) (map[uint][]string, error) {
+	res := make(map[uint][]string)
+	if len(targetIds) == 0 {
+		return res, nil
+	}
+	aliases, _, err := s.List(ctx, ListRepositoryAliasOption{
+		ResourceType: &repositoryResourceType,
+		TargetIds:    &targetIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, alias := range aliases {
+		res[alias.TargetId] = append(res[alias.TargetId], alias.Name)
+	}
+	return res, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 )
 
 type resourceService struct{}
@@ -54,6 +55,12 @@ func (m *resourceService) Get(ctx context.Context, resourceType modelschemas.Res
 	case modelschemas.ResourceTypeYataiComponent:
 		yataiComponent, err := YataiComponentService.Get(ctx, resourceId)
 		return yataiComponent, err
+	case schemas.ResourceTypeSecret:
+		secret, err := SecretService.Get(ctx, resourceId)
+		return secret, err
+	case schemas.ResourceTypeJob:
+		job, err := JobService.Get(ctx, resourceId)
+		return job, err
 	default:
 		return nil, errors.Errorf("cannot recognize this resource type: %s", resourceType)
 	}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type retentionService struct{}
+
+var RetentionService = retentionService{}
+
+type retentionItem struct {
+	id        uint
+	createdAt time.Time
+}
+
+// filterExpired returns the indexes of the expired items, items must be sorted from newest to oldest
+func (s *retentionService) filterExpired(policy *schemas.RetentionPolicySchema, items []retentionItem, now time.Time) []int {
+	res := make([]int, 0)
+	if policy.IsEmpty() {
+		return res
+	}
+	for idx, item := range items {
+		if policy.KeepLast != nil && uint(idx) < *policy.KeepLast {
+			continue
+		}
+		if policy.MaxAgeDays != nil && now.Sub(item.createdAt) < time.Duration(*policy.MaxAgeDays)*24*time.Hour {
+			continue
+		}
+		res = append(res, idx)
+	}
+	return res
+}
+
+func (s *retentionService) listKeptResourceIds(ctx context.Context, policy *schemas.RetentionPolicySchema, organizationId uint, resourceType modelschemas.ResourceType, resourceIds []uint) (map[uint]struct{}, error) {
+	res := make(map[uint]struct{})
+	if len(policy.KeepLabels) == 0 || len(resourceIds) == 0 {
+		return res, nil
+	}
+	labels, _, err := LabelService.List(ctx, ListLabelOption{
+		OrganizationId: &organizationId,
+		ResourceType:   &resourceType,
+		ResourceIds:    &resourceIds,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list labels")
+	}
+	for _, label := range labels {
+		for _, keepLabel := range policy.KeepLabels {
+			if label.Key == keepLabel.Key && (keepLabel.Value == "" || label.Value == keepLabel.Value) {
+				res[label.ResourceId] = struct{}{}
+			}
+		}
+	}
+	return res, nil
+}
+
+func (s *retentionService) ListExpiredBentos(ctx context.Context, bentoRepository *models.BentoRepository) ([]*models.Bento, error) {
+	res := make([]*models.Bento, 0)
+	if bentoRepository.RetentionPolicy.IsEmpty() {
+		return res, nil
+	}
+	bentos, _, err := BentoService.List(ctx, ListBentoOption{
+		BentoRepositoryId: &bentoRepository.ID,
+		Order:             utils.StringPtr("bento.created_at DESC"),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list bentos")
+	}
+	items := make([]retentionItem, 0, len(bentos))
+	for _, bento := range bentos {
+		items = append(items, retentionItem{id: bento.ID, createdAt: bento.CreatedAt})
+	}
+	expiredBentos := make([]*models.Bento, 0)
+	expiredBentoIds := make([]uint, 0)
+	for _, idx := range s.filterExpired(bentoRepository.RetentionPolicy, items, time.Now()) {
+		if bentos[idx].UploadStatus == modelschemas.BentoUploadStatusUploading {
+			continue
+		}
+		expiredBentos = append(expiredBentos, bentos[idx])
+		expiredBentoIds = append(expiredBentoIds, bentos[idx].ID)
+	}
+	if len(expiredBentos) == 0 {
+		return res, nil
+	}
+	keptBentoIds, err := s.listKeptResourceIds(ctx, bentoRepository.RetentionPolicy, bentoRepository.OrganizationId, modelschemas.ResourceTypeBento, expiredBentoIds)
+	if err != nil {
+		return nil, err
+	}
+	// the bentos of the inactive revisions are kept as well, the revisions can be rolled back to
+	deploymentTargets, _, err := DeploymentTargetService.List(ctx, ListDeploymentTargetOption{
+		BentoIds: &expiredBentoIds,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployment targets")
+	}
+	for _, deploymentTarget := range deploymentTargets {
+		keptBentoIds[deploymentTarget.BentoId] = struct{}{}
+	}
+	jobs, _, err := JobService.List(ctx, ListJobOption{
+		BentoIds: &expiredBentoIds,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list jobs")
+	}
+	for _, job := range jobs {
+		keptBentoIds[job.BentoId] = struct{}{}
+	}
+	aliasNames, err := RepositoryAliasService.ListAliasNamesByTargetIds(ctx, modelschemas.ResourceTypeBentoRepository, expiredBentoIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "list aliases")
+	}
+	for bentoId := range aliasNames {
+		keptBentoIds[bentoId] = struct{}{}
+	}
+	for _, bento := range expiredBentos {
+		if _, ok := keptBentoIds[bento.ID]; ok {
+			continue
+		}
+		res = append(res, bento)
+	}
+	return res, nil
+}
+
+func (s *retentionService) ListExpiredModels(ctx context.Context, modelRepository *models.ModelRepository) ([]*models.Model, error) {
+	res := make([]*models.Model, 0)
+	if modelRepository.RetentionPolicy.IsEmpty() {
+		return res, nil
+	}
+	models_, _, err := ModelService.List(ctx, ListModelOption{
+		ModelRepositoryId: &modelRepository.ID,
+		Order:             utils.StringPtr("model.created_at DESC"),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list models")
+	}
+	items := make([]retentionItem, 0, len(models_))
+	for _, model := range models_ {
+		items = append(items, retentionItem{id: model.ID, createdAt: model.CreatedAt})
+	}
+	expiredModels := make([]*models.Model, 0)
+	expiredModelIds := make([]uint, 0)
+	for _, idx := range s.filterExpired(modelRepository.RetentionPolicy, items, time.Now()) {
+		if models_[idx].UploadStatus == modelschemas.ModelUploadStatusUploading {
+			continue
+		}
+		expiredModels = append(expiredModels, models_[idx])
+		expiredModelIds = append(expiredModelIds, models_[idx].ID)
+	}
+	if len(expiredModels) == 0 {
+		return res, nil
+	}
+	keptModelIds, err := s.listKeptResourceIds(ctx, modelRepository.RetentionPolicy, modelRepository.OrganizationId, modelschemas.ResourceTypeModel, expiredModelIds)
+	if err != nil {
+		return nil, err
+	}
+	aliasNames, err := RepositoryAliasService.ListAliasNamesByTargetIds(ctx, modelschemas.ResourceTypeModelRepository, expiredModelIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "list aliases")
+	}
+	for modelId := range aliasNames {
+		keptModelIds[modelId] = struct{}{}
+	}
+	for _, model := range expiredModels {
+		if _, ok := keptModelIds[model.ID]; ok {
+			continue
+		}
+		// models referenced by any bento cannot be deleted, this also covers the deployed ones
+		bento, err := ModelService.GetReferencingBento(ctx, model)
+		if err != nil {
+			return nil, err
+		}
+		if bento != nil {
+			continue
+		}
+		res = append(res, model)
+	}
+	return res, nil
+}
+
+func (s *retentionService) createDeletedEvent(ctx context.Context, organizationId, creatorId uint, resource models.IResource) error {
+	_, err := EventService.Create(ctx, CreateEventOption{
+		CreatorId:      creatorId,
+		OrganizationId: &organizationId,
+		ResourceType:   resource.GetResourceType(),
+		ResourceId:     resource.GetId(),
+		Status:         modelschemas.EventStatusSuccess,
+		OperationName:  "garbage collected",
+	})
+	return err
+}
+
+func (s *retentionService) deleteBento(ctx context.Context, bentoRepository *models.BentoRepository, bento *models.Bento) (err error) {
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+	err = s.createDeletedEvent(ctx, bentoRepository.OrganizationId, bentoRepository.CreatorId, bento)
+	if err != nil {
+		return
+	}
+	_, err = BentoService.Delete(ctx, bento)
+	return
+}
+
+func (s *retentionService) deleteModel(ctx context.Context, modelRepository *models.ModelRepository, model *models.Model) (err error) {
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+	err = s.createDeletedEvent(ctx, modelRepository.OrganizationId, modelRepository.CreatorId, model)
+	if err != nil {
+		return
+	}
+	_, err = ModelService.Delete(ctx, model)
+	return
+}
+
+// CollectGarbage deletes the expired versions of all repositories which have a retention policy.
+// Bentos are collected first so that the models they referenced can be collected in the same run.
+func (s *retentionService) CollectGarbage(ctx context.Context) error {
+	logger := logrus.WithField("cron", "retention gc")
+
+	bentoRepositories, _, err := BentoRepositoryService.List(ctx, ListBentoRepositoryOption{
+		HasRetentionPolicy: utils.BoolPtr(true),
+	})
+	if err != nil {
+		return errors.Wrap(err, "list bento repositories")
+	}
+	for _, bentoRepository := range bentoRepositories {
+		bentos, err := s.ListExpiredBentos(ctx, bentoRepository)
+		if err != nil {
+			logger.Errorf("list expired bentos of bento repository %s: %s", bentoRepository.Name, err.Error())
+			continue
+		}
+		for _, bento := range bentos {
+			if err = s.deleteBento(ctx, bentoRepository, bento); err != nil {
+				logger.Errorf("delete bento %s:%s: %s", bentoRepository.Name, bento.Version, err.Error())
+				continue
+			}
+			logger.Infof("deleted bento %s:%s", bentoRepository.Name, bento.Version)
+		}
+	}
+
+	modelRepositories, _, err := ModelRepositoryService.List(ctx, ListModelRepositoryOption{
+		HasRetentionPolicy: utils.BoolPtr(true),
+	})
+	if err != nil {
+		return errors.Wrap(err, "list model repositories")
+	}
+	for _, modelRepository := range modelRepositories {
+		models_, err := s.ListExpiredModels(ctx, modelRepository)
+		if err != nil {
+			logger.Errorf("list expired models of model repository %s: %s", modelRepository.Name, err.Error())
+			continue
+		}
+		for _, model := range models_ {
+			if err = s.deleteModel(ctx, modelRepository, model); err != nil {
+				logger.Errorf("delete model %s:%s: %s", modelRepository.Name, model.Version, err.Error())
+				continue
+			}
+			logger.Infof("deleted model %s:%s", modelRepository.Name, model.Version)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	commonconsts "github.com/bentoml/yatai-common/consts"
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/config"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/common/consts"
+	"github.com/bentoml/yatai/common/utils"
+)
+
+type secretService struct{}
+
+var SecretService = secretService{}
+
+func (s *secretService) getBaseDB(ctx context.Context) *gorm.DB {
+	return mustGetSession(ctx).Model(&models.Secret{})
+}
+
+type CreateSecretOption struct {
+	CreatorId      uint
+	OrganizationId uint
+	// ClusterId is nil for the organization secrets
+	ClusterId   *uint
+	Name        string
+	Description string
+	Data        map[string]string
+}
+
+type UpdateSecretOption struct {
+	Description *string
+	Data        map[string]string
+}
+
+type ListSecretOption struct {
+	BaseListOption
+	OrganizationId *uint
+	// ClusterId lists the secrets of the cluster, the organization secrets are listed when it is nil
+	ClusterId *uint
+}
+
+func (s *secretService) whereScope(query *gorm.DB, organizationId uint, clusterId *uint) *gorm.DB {
+	query = query.Where("organization_id = ?", organizationId)
+	if clusterId == nil {
+		return query.Where("cluster_id IS NULL")
+	}
+	return query.Where("cluster_id = ?", *clusterId)
+}
+
+func (s *secretService) validateData(data map[string]string) error {
+	if len(data) == 0 {
+		return errors.New("the data of a secret cannot be empty")
+	}
+	for key := range data {
+		errs := validation.IsConfigMapKey(key)
+		if len(errs) > 0 {
+			return errors.Errorf("invalid secret key %s: %s", key, strings.Join(errs, ";"))
+		}
+	}
+	return nil
+}
+
+func (s *secretService) Create(ctx context.Context, opt CreateSecretOption) (*models.Secret, error) {
+	// the secret is materialized in the namespaces of the deployments with its name
+	errs := validation.IsDNS1123Subdomain(opt.Name)
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, ";"))
+	}
+	if err := s.validateData(opt.Data); err != nil {
+		return nil, err
+	}
+	encryptedData, err := encryptSecretData(opt.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &models.Secret{
+		CreatorAssociate: models.CreatorAssociate{
+			CreatorId: opt.CreatorId,
+		},
+		OrganizationAssociate: models.OrganizationAssociate{
+			OrganizationId: opt.OrganizationId,
+		},
+		NullableClusterAssociate: models.NullableClusterAssociate{
+			ClusterId: opt.ClusterId,
+		},
+		Name:          opt.Name,
+		Description:   opt.Description,
+		EncryptedData: encryptedData,
+	}
+	err = s.getBaseDB(ctx).Create(secret).Error
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (s *secretService) Update(ctx context.Context, secret *models.Secret, opt UpdateSecretOption) (secret_ *models.Secret, err error) {
+	updaters := make(map[string]interface{})
+	if opt.Description != nil {
+		updaters["description"] = *opt.Description
+		defer func() {
+			if err == nil {
+				secret.Description = *opt.Description
+			}
+		}()
+	}
+	if opt.Data != nil {
+		if err = s.validateData(opt.Data); err != nil {
+			return
+		}
+		var encryptedData string
+		encryptedData, err = encryptSecretData(opt.Data)
+		if err != nil {
+			return
+		}
+		updaters["encrypted_data"] = encryptedData
+		defer func() {
+			if err == nil {
+				secret.EncryptedData = encryptedData
+			}
+		}()
+	}
+
+	if len(updaters) == 0 {
+		return secret, nil
+	}
+
+	err = s.getBaseDB(ctx).Where("id = ?", secret.ID).Updates(updaters).Error
+	return secret, err
+}
+
+func (s *secretService) Get(ctx context.Context, id uint) (*models.Secret, error) {
+	var secret models.Secret
+	err := getBaseQuery(ctx, s).Where("id = ?", id).First(&secret).Error
+	if err != nil {
+		return nil, err
+	}
+	if secret.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &secret, nil
+}
+
+// GetByName looks the secret up in a single scope, use Resolve to fall back on the organization secrets
+func (s *secretService) GetByName(ctx context.Context, organizationId uint, clusterId *uint, name string) (*models.Secret, error) {
+	var secret models.Secret
+	err := s.whereScope(getBaseQuery(ctx, s), organizationId, clusterId).Where("name = ?", name).First(&secret).Error
+	if err != nil {
+		return nil, err
+	}
+	if secret.ID == 0 {
+		return nil, consts.ErrNotFound
+	}
+	return &secret, nil
+}
+
+func (s *secretService) List(ctx context.Context, opt ListSecretOption) ([]*models.Secret, uint, error) {
+	query := getBaseQuery(ctx, s)
+	if opt.OrganizationId != nil {
+		query = s.whereScope(query, *opt.OrganizationId, opt.ClusterId)
+	}
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	secrets := make([]*models.Secret, 0)
+	query = opt.BindQueryWithLimit(query)
+	query = query.Order("name ASC")
+	err = query.Find(&secrets).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return secrets, uint(total), err
+}
+
+// Delete leaves the secrets already materialized in the namespaces of the deployments in place
+func (s *secretService) Delete(ctx context.Context, secret *models.Secret) (*models.Secret, error) {
+	return secret, s.getBaseDB(ctx).Unscoped().Delete(secret).Error
+}
+
+// Resolve returns the secret of the cluster with the name, or the organization secret when the cluster has none
+func (s *secretService) Resolve(ctx context.Context, cluster *models.Cluster, name string) (*models.Secret, error) {
+	secret, err := s.GetByName(ctx, cluster.OrganizationId, utils.UintPtr(cluster.ID), name)
+	if !utils.IsNotFound(err) {
+		return secret, err
+	}
+	return s.GetByName(ctx, cluster.OrganizationId, nil, name)
+}
+
+func (s *secretService) GetData(secret *models.Secret) (map[string]string, error) {
+	data, err := decryptSecretData(secret.EncryptedData)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decrypt secret %s", secret.Name)
+	}
+	return data, nil
+}
+
+func (s *secretService) GetKeys(secret *models.Secret) ([]string, error) {
+	data, err := s.GetData(secret)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Materialize creates or updates the kubernetes secret of the yatai secret in the namespace,
+// a kubernetes secret with the same name that is not managed by yatai is never overwritten
+func (s *secretService) Materialize(ctx context.Context, cluster *models.Cluster, kubeNs string, secret *models.Secret) error {
+	data, err := s.GetData(secret)
+	if err != nil {
+		return err
+	}
+	kubeCli, _, err := ClusterService.GetKubeCliSet(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	secretsCli := kubeCli.CoreV1().Secrets(kubeNs)
+
+	kubeSecret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name,
+			Namespace: kubeNs,
+			Labels: map[string]string{
+				commonconsts.KubeLabelCreator: commonconsts.KubeCreator,
+			},
+		},
+		Type:       apiv1.SecretTypeOpaque,
+		StringData: data,
+	}
+
+	oldKubeSecret, err := secretsCli.Get(ctx, secret.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = secretsCli.Create(ctx, kubeSecret, metav1.CreateOptions{})
+		return errors.Wrapf(err, "create kube secret %s", secret.Name)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "get kube secret %s", secret.Name)
+	}
+	if oldKubeSecret.Labels[commonconsts.KubeLabelCreator] != commonconsts.KubeCreator {
+		return errors.Errorf("kube secret %s already exists in namespace %s and is not managed by yatai", secret.Name, kubeNs)
+	}
+	kubeSecret.SetResourceVersion(oldKubeSecret.GetResourceVersion())
+	_, err = secretsCli.Update(ctx, kubeSecret, metav1.UpdateOptions{})
+	return errors.Wrapf(err, "update kube secret %s", secret.Name)
+}
+
+// validatePlainEnv rejects the envs referencing a secret, the values of the secrets would otherwise be written in plain text
+// to the kubernetes objects, the references can be deployed once the BentoDeployment CRD supports secret key references
+func validatePlainEnv(env *modelschemas.LabelItemSchema) error {
+	if ref := schemas.ParseSecretKeyRef(env.Value); ref != nil {
+		return errors.Errorf("the secret reference %s cannot be deployed, the BentoDeployment CRD of yatai-deployment must support secret key references first", ref)
+	}
+	return nil
+}
+
+func getSecretsEncryptionCipher() (cipher.AEAD, error) {
+	if config.YataiConfig.Secrets == nil || config.YataiConfig.Secrets.EncryptionKey == "" {
+		return nil, errors.New("secrets encryption key is not configured")
+	}
+	// the configured key can be any string, hashing it gives a key of the size of AES-256
+	key := sha256.Sum256([]byte(config.YataiConfig.Secrets.EncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encryptSecretData(data map[string]string) (string, error) {
+	aead, err := getSecretsEncryptionCipher()
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	// the nonce is stored in front of the ciphertext
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+func decryptSecretData(encryptedData string) (map[string]string, error) {
+	aead, err := getSecretsEncryptionCipher()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encryptedData)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("the encrypted data is too short")
+	}
+	plaintext, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "the secrets encryption key may have been changed")
+	}
+	data := make(map[string]string)
+	err = json.Unmarshal(plaintext, &data)
+	return data, err
+}
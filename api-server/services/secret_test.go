@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/config"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+func TestSecretDataEncryption(t *testing.T) {
+	oldSecretsConfig := config.YataiConfig.Secrets
+	defer func() { config.YataiConfig.Secrets = oldSecretsConfig }()
+
+	config.YataiConfig.Secrets = nil
+	if _, err := encryptSecretData(map[string]string{"api_key": "xxx"}); err == nil {
+		t.Fatal("expected the encryption to fail without an encryption key")
+	}
+
+	config.YataiConfig.Secrets = &config.YataiSecretsConfigYaml{EncryptionKey: "key-1"}
+	encryptedData, err := encryptSecretData(map[string]string{"api_key": "sk-123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := decryptSecretData(encryptedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["api_key"] != "sk-123" {
+		t.Errorf("unexpected decrypted data %v", data)
+	}
+
+	config.YataiConfig.Secrets.EncryptionKey = "key-2"
+	if _, err = decryptSecretData(encryptedData); err == nil {
+		t.Error("expected the decryption to fail with another encryption key")
+	}
+}
+
+func TestParseSecretKeyRef(t *testing.T) {
+	if ref := schemas.ParseSecretKeyRef("plain value"); ref != nil {
+		t.Errorf("expected a plain value not to be a reference, got %v", ref)
+	}
+	ref := schemas.ParseSecretKeyRef("secret://openai/api_key")
+	if ref == nil || ref.Name != "openai" || ref.Key != "api_key" {
+		t.Errorf("unexpected reference %v", ref)
+	}
+	// the malformed references are plain values that happen to start with the prefix
+	for _, value := range []string{"secret://openai", "secret:///api_key", "secret://openai/", "secret://Open AI/api_key", "secret://openai/api/key"} {
+		if ref = schemas.ParseSecretKeyRef(value); ref != nil {
+			t.Errorf("expected %s to be a plain value, got %v", value, ref)
+		}
+	}
+}
+
+func TestValidateDeploymentTargetConfigEnvs(t *testing.T) {
+	envs := []*modelschemas.LabelItemSchema{{Key: "LOG_LEVEL", Value: "info"}}
+	config := &modelschemas.DeploymentTargetConfig{
+		Envs: &envs,
+		Runners: map[string]modelschemas.DeploymentTargetRunnerConfig{
+			"encoder": {Envs: &[]*modelschemas.LabelItemSchema{{Key: "API_KEY", Value: "secret://openai/api_key"}}},
+		},
+	}
+	if err := ValidateDeploymentTargetConfigEnvs(config); err == nil {
+		t.Error("expected a runner env referencing a secret to be rejected")
+	}
+	config.Runners = map[string]modelschemas.DeploymentTargetRunnerConfig{
+		"encoder": {Envs: &[]*modelschemas.LabelItemSchema{{Key: "CALLBACK", Value: "secret://not a reference"}}},
+	}
+	if err := ValidateDeploymentTargetConfigEnvs(config); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai/api-server/config"
+	"github.com/bentoml/yatai/api-server/models"
+)
+
+const (
+	StorageDriverS3    = "s3"
+	StorageDriverLocal = "local"
+)
+
+type StorageBucketType string
+
+const (
+	StorageBucketTypeBentos StorageBucketType = "bentos"
+	StorageBucketTypeModels StorageBucketType = "models"
+)
+
+type StorageObjectInfo struct {
+	Size         int64
+	ETag         string
+	LastModified time.Time
+}
+
+type StorageCompletePart struct {
+	PartNumber int
+	ETag       string
+}
+
+type StorageObjectRange struct {
+	// Start and End are both inclusive
+	Start int64
+	End   int64
+}
+
+type StorageGetOption struct {
+	// MatchETag makes the get fail if the object has been replaced since it was stated
+	MatchETag string
+	Range     *StorageObjectRange
+}
+
+// StorageDriver stores the bento and model archives, every driver instance is bound to a single bucket
+type StorageDriver interface {
+	Put(ctx context.Context, objectName string, reader io.Reader, objectSize int64) error
+	PreSignPutUrl(ctx context.Context, objectName string, expires time.Duration) (*url.URL, error)
+	StartMultipartUpload(ctx context.Context, objectName string) (uploadId string, err error)
+	PreSignMultipartUploadUrl(ctx context.Context, objectName string, uploadId string, partNumber int, expires time.Duration) (*url.URL, error)
+	// PutMultipartUploadPart uploads a part through the api-server, it is the only way to upload the parts when the driver cannot presign urls
+	PutMultipartUploadPart(ctx context.Context, objectName string, uploadId string, partNumber int, reader io.Reader, partSize int64) (etag string, err error)
+	CompleteMultipartUpload(ctx context.Context, objectName string, uploadId string, parts []StorageCompletePart) error
+	AbortMultipartUpload(ctx context.Context, objectName string, uploadId string) error
+	// ListMultipartUploads returns the ids of the multipart uploads of the object that are neither completed nor aborted
+	ListMultipartUploads(ctx context.Context, objectName string) (uploadIds []string, err error)
+	Get(ctx context.Context, objectName string, writer io.Writer, opt StorageGetOption) error
+	PreSignGetUrl(ctx context.Context, objectName string, expires time.Duration) (*url.URL, error)
+	Stat(ctx context.Context, objectName string) (*StorageObjectInfo, error)
+	Delete(ctx context.Context, objectName string) error
+}
+
+// CopyStorageObject copies the object server side when both drivers share a backend, and streams it through the api-server otherwise
+func CopyStorageObject(ctx context.Context, src StorageDriver, srcObjectName string, dst StorageDriver, dstObjectName string) error {
+	if srcS3, ok := src.(*s3StorageDriver); ok {
+		if dstS3, ok := dst.(*s3StorageDriver); ok && dstS3.sharesBackendWith(srcS3) {
+			return dstS3.copyFrom(ctx, srcS3, srcObjectName, dstObjectName)
+		}
+	}
+	info, err := src.Stat(ctx, srcObjectName)
+	if err != nil {
+		return err
+	}
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(src.Get(ctx, srcObjectName, writer, StorageGetOption{
+			MatchETag: info.ETag,
+		}))
+	}()
+	err = dst.Put(ctx, dstObjectName, reader, info.Size)
+	// unblock the reading goroutine if the put stopped early
+	_ = reader.CloseWithError(errors.New("copy aborted"))
+	return err
+}
+
+func getStorageDriverName() string {
+	if config.YataiConfig.Storage == nil || config.YataiConfig.Storage.Driver == "" {
+		return StorageDriverS3
+	}
+	return config.YataiConfig.Storage.Driver
+}
+
+func (s *organizationService) GetStorageDriver(ctx context.Context, org *models.Organization, bucketType StorageBucketType) (StorageDriver, error) {
+	if getStorageDriverName() == StorageDriverLocal {
+		return newLocalStorageDriver(config.YataiConfig.Storage.Local, bucketType)
+	}
+	s3Config, err := s.GetS3Config(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	return newS3StorageDriver(s3Config, bucketType), nil
+}
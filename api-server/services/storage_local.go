@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/xid"
+
+	"github.com/bentoml/yatai/api-server/config"
+	"github.com/bentoml/yatai/common/consts"
+)
+
+const localMultipartObjectFileName = "object"
+
+type localStorageDriver struct {
+	rootDir   string
+	bucketDir string
+}
+
+func newLocalStorageDriver(conf *config.YataiLocalStorageConfigYaml, bucketType StorageBucketType) (*localStorageDriver, error) {
+	if conf == nil || conf.RootDir == "" {
+		return nil, errors.New("the root_dir of the local storage is not configured")
+	}
+	rootDir, err := filepath.Abs(conf.RootDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get absolute path of %s", conf.RootDir)
+	}
+	return &localStorageDriver{
+		rootDir:   rootDir,
+		bucketDir: filepath.Join(rootDir, string(bucketType)),
+	}, nil
+}
+
+func (d *localStorageDriver) getObjectPath(objectName string) (string, error) {
+	objectPath := filepath.Join(d.bucketDir, filepath.FromSlash(objectName))
+	if !strings.HasPrefix(objectPath, d.bucketDir+string(filepath.Separator)) {
+		return "", errors.Errorf("invalid object name %s", objectName)
+	}
+	return objectPath, nil
+}
+
+func (d *localStorageDriver) getMultipartUploadDir(uploadId string) (string, error) {
+	if _, err := xid.FromString(uploadId); err != nil {
+		return "", errors.Errorf("invalid upload id %s", uploadId)
+	}
+	return filepath.Join(d.rootDir, ".multipart", uploadId), nil
+}
+
+// writeFile writes to a temporary file first, so readers never see a partially written object,
+// the file is dropped unless exactly size bytes are written, a negative size accepts any size
+func (d *localStorageDriver) writeFile(objectPath string, reader io.Reader, size int64) error {
+	err := os.MkdirAll(filepath.Dir(objectPath), 0755)
+	if err != nil {
+		return errors.Wrap(err, "create object directory")
+	}
+	f, err := os.CreateTemp(filepath.Dir(objectPath), ".tmp-*")
+	if err != nil {
+		return errors.Wrap(err, "create temporary file")
+	}
+	defer os.Remove(f.Name()) // nolint: errcheck
+	if size >= 0 {
+		// one more byte is read so that a reader longer than the size is noticed as well
+		reader = io.LimitReader(reader, size+1)
+	}
+	n, err := io.Copy(f, reader)
+	if err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "write object")
+	}
+	if size >= 0 && n != size {
+		_ = f.Close()
+		return errors.Errorf("expected %d bytes but got %d, the upload is incomplete", size, n)
+	}
+	err = f.Close()
+	if err != nil {
+		return errors.Wrap(err, "close object")
+	}
+	return errors.Wrap(os.Rename(f.Name(), objectPath), "rename object")
+}
+
+func (d *localStorageDriver) Put(ctx context.Context, objectName string, reader io.Reader, objectSize int64) error {
+	objectPath, err := d.getObjectPath(objectName)
+	if err != nil {
+		return err
+	}
+	return d.writeFile(objectPath, reader, objectSize)
+}
+
+func (d *localStorageDriver) PreSignPutUrl(ctx context.Context, objectName string, expires time.Duration) (*url.URL, error) {
+	return nil, errors.Wrap(consts.ErrNoImplemented, "the local storage does not support presigned urls")
+}
+
+func (d *localStorageDriver) StartMultipartUpload(ctx context.Context, objectName string) (string, error) {
+	if _, err := d.getObjectPath(objectName); err != nil {
+		return "", err
+	}
+	uploadId := xid.New().String()
+	uploadDir, err := d.getMultipartUploadDir(uploadId)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(uploadDir, 0755)
+	if err != nil {
+		return "", errors.Wrap(err, "create multipart upload directory")
+	}
+	// remember the object of the upload so that the abandoned uploads can be found by ListMultipartUploads
+	err = os.WriteFile(filepath.Join(uploadDir, localMultipartObjectFileName), []byte(d.getMultipartObjectKey(objectName)), 0644)
+	if err != nil {
+		return "", errors.Wrap(err, "write multipart upload object name")
+	}
+	return uploadId, nil
+}
+
+func (d *localStorageDriver) PreSignMultipartUploadUrl(ctx context.Context, objectName string, uploadId string, partNumber int, expires time.Duration) (*url.URL, error) {
+	return nil, errors.Wrap(consts.ErrNoImplemented, "the local storage does not support presigned urls")
+}
+
+func (d *localStorageDriver) PutMultipartUploadPart(ctx context.Context, objectName string, uploadId string, partNumber int, reader io.Reader, partSize int64) (string, error) {
+	if _, err := d.getObjectPath(objectName); err != nil {
+		return "", err
+	}
+	if partNumber < 1 {
+		return "", errors.Errorf("invalid part number %d", partNumber)
+	}
+	uploadDir, err := d.getMultipartUploadDir(uploadId)
+	if err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(filepath.Join(uploadDir, localMultipartObjectFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", errors.Errorf("multipart upload %s does not exist", uploadId)
+		}
+		return "", errors.Wrapf(err, "read multipart upload %s", uploadId)
+	}
+	if string(content) != d.getMultipartObjectKey(objectName) {
+		return "", errors.Errorf("multipart upload %s does not belong to object %s", uploadId, objectName)
+	}
+	partPath := filepath.Join(uploadDir, strconv.Itoa(partNumber))
+	err = d.writeFile(partPath, reader, partSize)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(partPath)
+	if err != nil {
+		return "", errors.Wrap(err, "stat part")
+	}
+	return d.getETag(info), nil
+}
+
+func (d *localStorageDriver) CompleteMultipartUpload(ctx context.Context, objectName string, uploadId string, parts []StorageCompletePart) error {
+	objectPath, err := d.getObjectPath(objectName)
+	if err != nil {
+		return err
+	}
+	uploadDir, err := d.getMultipartUploadDir(uploadId)
+	if err != nil {
+		return err
+	}
+	readers := make([]io.Reader, 0, len(parts))
+	for _, part := range parts {
+		f, err := os.Open(filepath.Join(uploadDir, strconv.Itoa(part.PartNumber)))
+		if err != nil {
+			return errors.Wrapf(err, "open part %d", part.PartNumber)
+		}
+		defer f.Close() // nolint: errcheck
+		readers = append(readers, f)
+	}
+	err = d.writeFile(objectPath, io.MultiReader(readers...), -1)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(os.RemoveAll(uploadDir), "remove multipart upload directory")
+}
+
+func (d *localStorageDriver) AbortMultipartUpload(ctx context.Context, objectName string, uploadId string) error {
+	uploadDir, err := d.getMultipartUploadDir(uploadId)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(os.RemoveAll(uploadDir), "remove multipart upload directory")
+}
+
+func (d *localStorageDriver) getMultipartObjectKey(objectName string) string {
+	return filepath.Join(filepath.Base(d.bucketDir), filepath.FromSlash(objectName))
+}
+
+func (d *localStorageDriver) ListMultipartUploads(ctx context.Context, objectName string) ([]string, error) {
+	uploadIds := make([]string, 0)
+	entries, err := os.ReadDir(filepath.Join(d.rootDir, ".multipart"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return uploadIds, nil
+		}
+		return nil, errors.Wrap(err, "list multipart uploads")
+	}
+	objectKey := d.getMultipartObjectKey(objectName)
+	for _, entry := range entries {
+		uploadDir, err := d.getMultipartUploadDir(entry.Name())
+		if err != nil {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(uploadDir, localMultipartObjectFileName))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "read multipart upload %s", entry.Name())
+		}
+		if string(content) == objectKey {
+			uploadIds = append(uploadIds, entry.Name())
+		}
+	}
+	return uploadIds, nil
+}
+
+func (d *localStorageDriver) Get(ctx context.Context, objectName string, writer io.Writer, opt StorageGetOption) error {
+	objectPath, err := d.getObjectPath(objectName)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(objectPath)
+	if err != nil {
+		return errors.Wrap(err, "open object")
+	}
+	defer f.Close() // nolint: errcheck
+	if opt.MatchETag != "" {
+		info, err := f.Stat()
+		if err != nil {
+			return errors.Wrap(err, "stat object")
+		}
+		if etag := d.getETag(info); etag != opt.MatchETag {
+			return errors.Errorf("the etag of object %s has changed from %s to %s", objectName, opt.MatchETag, etag)
+		}
+	}
+	var reader io.Reader = f
+	if opt.Range != nil {
+		_, err = f.Seek(opt.Range.Start, io.SeekStart)
+		if err != nil {
+			return errors.Wrap(err, "seek object")
+		}
+		reader = io.LimitReader(f, opt.Range.End-opt.Range.Start+1)
+	}
+	_, err = io.Copy(writer, reader)
+	if err != nil {
+		return errors.Wrap(err, "copy object")
+	}
+	return nil
+}
+
+func (d *localStorageDriver) PreSignGetUrl(ctx context.Context, objectName string, expires time.Duration) (*url.URL, error) {
+	return nil, errors.Wrap(consts.ErrNoImplemented, "the local storage does not support presigned urls")
+}
+
+func (d *localStorageDriver) getETag(info os.FileInfo) string {
+	return fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size())
+}
+
+func (d *localStorageDriver) Stat(ctx context.Context, objectName string) (*StorageObjectInfo, error) {
+	objectPath, err := d.getObjectPath(objectName)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(objectPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "stat object")
+	}
+	return &StorageObjectInfo{
+		Size:         info.Size(),
+		ETag:         d.getETag(info),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+func (d *localStorageDriver) Delete(ctx context.Context, objectName string) error {
+	objectPath, err := d.getObjectPath(objectName)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(objectPath)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "remove object")
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bentoml/yatai/api-server/config"
+)
+
+func TestLocalStorageDriver(t *testing.T) {
+	ctx := context.Background()
+	driver, err := newLocalStorageDriver(&config.YataiLocalStorageConfigYaml{
+		RootDir: t.TempDir(),
+	}, StorageBucketTypeBentos)
+	if err != nil {
+		t.Fatalf("new local storage driver: %v", err)
+	}
+
+	objectName := "bentos/default/iris/v1.tar.gz"
+	content := "hello yatai"
+	err = driver.Put(ctx, objectName, strings.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	info, err := driver.Stat(ctx, objectName)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Size != int64(len(content)) {
+		t.Fatalf("size is %d", info.Size)
+	}
+
+	var buf bytes.Buffer
+	err = driver.Get(ctx, objectName, &buf, StorageGetOption{
+		MatchETag: info.ETag,
+		Range:     &StorageObjectRange{Start: 6, End: 10},
+	})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if buf.String() != "yatai" {
+		t.Fatalf("range content is %q", buf.String())
+	}
+
+	err = driver.Get(ctx, objectName, &buf, StorageGetOption{MatchETag: "outdated"})
+	if err == nil {
+		t.Fatal("get with an outdated etag should fail")
+	}
+
+	uploadId, err := driver.StartMultipartUpload(ctx, objectName)
+	if err != nil {
+		t.Fatalf("start multipart upload: %v", err)
+	}
+	parts := make([]StorageCompletePart, 0, 2)
+	for partNumber, part := range []string{"hello ", "multipart"} {
+		etag, err := driver.PutMultipartUploadPart(ctx, objectName, uploadId, partNumber+1, strings.NewReader(part), int64(len(part)))
+		if err != nil {
+			t.Fatalf("put part: %v", err)
+		}
+		parts = append(parts, StorageCompletePart{PartNumber: partNumber + 1, ETag: etag})
+	}
+	if _, err = driver.PutMultipartUploadPart(ctx, "bentos/default/iris/v2.tar.gz", uploadId, 3, strings.NewReader(content), -1); err == nil {
+		t.Fatal("parts of another object should be rejected")
+	}
+	err = driver.CompleteMultipartUpload(ctx, objectName, uploadId, parts)
+	if err != nil {
+		t.Fatalf("complete multipart upload: %v", err)
+	}
+	buf.Reset()
+	err = driver.Get(ctx, objectName, &buf, StorageGetOption{})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if buf.String() != "hello multipart" {
+		t.Fatalf("multipart content is %q", buf.String())
+	}
+
+	err = driver.Delete(ctx, objectName)
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err = driver.Stat(ctx, objectName); err == nil {
+		t.Fatal("stat after delete should fail")
+	}
+
+	// a client disconnecting early sends fewer bytes than announced
+	if err = driver.Put(ctx, objectName, strings.NewReader(content[:5]), int64(len(content))); err == nil {
+		t.Fatal("a truncated put should fail")
+	}
+	if _, err = driver.Stat(ctx, objectName); err == nil {
+		t.Fatal("a truncated put should not leave an object")
+	}
+
+	if err = driver.Put(ctx, "../escape.tar.gz", strings.NewReader(content), -1); err == nil {
+		t.Fatal("object names escaping the bucket should be rejected")
+	}
+}
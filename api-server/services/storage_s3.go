@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type s3StorageDriver struct {
+	s3Config   *S3Config
+	bucketName string
+}
+
+func newS3StorageDriver(s3Config *S3Config, bucketType StorageBucketType) *s3StorageDriver {
+	bucketName := s3Config.BentosBucketName
+	if bucketType == StorageBucketTypeModels {
+		bucketName = s3Config.ModelsBucketName
+	}
+	return &s3StorageDriver{
+		s3Config:   s3Config,
+		bucketName: bucketName,
+	}
+}
+
+func (d *s3StorageDriver) getMinioClient(ctx context.Context) (*minio.Client, error) {
+	minioClient, err := d.s3Config.GetMinioClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "create s3 client")
+	}
+	err = d.s3Config.MakeSureBucket(ctx, d.bucketName)
+	if err != nil {
+		return nil, err
+	}
+	return minioClient, nil
+}
+
+func (d *s3StorageDriver) getMinioCore(ctx context.Context) (*minio.Core, error) {
+	minioCore, err := d.s3Config.GetMinioCore()
+	if err != nil {
+		return nil, errors.Wrap(err, "create s3 client")
+	}
+	err = d.s3Config.MakeSureBucket(ctx, d.bucketName)
+	if err != nil {
+		return nil, err
+	}
+	return minioCore, nil
+}
+
+// fixPresignedUrl makes the presigned url reachable from outside of the cluster
+func (d *s3StorageDriver) fixPresignedUrl(url_ *url.URL) *url.URL {
+	if d.s3Config.Endpoint != d.s3Config.EndpointInCluster {
+		url_.Host = d.s3Config.Endpoint
+	}
+	return url_
+}
+
+func (d *s3StorageDriver) sharesBackendWith(other *s3StorageDriver) bool {
+	return d.s3Config.EndpointInCluster == other.s3Config.EndpointInCluster &&
+		d.s3Config.AccessKey == other.s3Config.AccessKey &&
+		d.s3Config.SecretKey == other.s3Config.SecretKey
+}
+
+func (d *s3StorageDriver) copyFrom(ctx context.Context, src *s3StorageDriver, srcObjectName string, objectName string) error {
+	minioClient, err := d.getMinioClient(ctx)
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("copying in s3: %s/%s -> %s/%s", src.bucketName, srcObjectName, d.bucketName, objectName)
+	// a single CopyObject request is capped at 5GiB, ComposeObject switches to a multipart copy above it
+	_, err = minioClient.ComposeObject(ctx, minio.CopyDestOptions{
+		Bucket: d.bucketName,
+		Object: objectName,
+	}, minio.CopySrcOptions{
+		Bucket: src.bucketName,
+		Object: srcObjectName,
+	})
+	if err != nil {
+		return errors.Wrap(err, "copy object")
+	}
+	return nil
+}
+
+func (d *s3StorageDriver) Put(ctx context.Context, objectName string, reader io.Reader, objectSize int64) error {
+	minioClient, err := d.getMinioClient(ctx)
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("uploading to s3: %s/%s", d.bucketName, objectName)
+	_, err = minioClient.PutObject(ctx, d.bucketName, objectName, reader, objectSize, minio.PutObjectOptions{ContentType: "application/octet-stream"})
+	if err != nil {
+		return errors.Wrap(err, "put object")
+	}
+	logrus.Debugf("uploaded to s3: %s/%s", d.bucketName, objectName)
+	return nil
+}
+
+func (d *s3StorageDriver) PreSignPutUrl(ctx context.Context, objectName string, expires time.Duration) (*url.URL, error) {
+	minioClient, err := d.getMinioClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	url_, err := minioClient.PresignedPutObject(ctx, d.bucketName, objectName, expires)
+	if err != nil {
+		return nil, errors.Wrap(err, "presigned put object")
+	}
+	return d.fixPresignedUrl(url_), nil
+}
+
+func (d *s3StorageDriver) StartMultipartUpload(ctx context.Context, objectName string) (string, error) {
+	minioCore, err := d.getMinioCore(ctx)
+	if err != nil {
+		return "", err
+	}
+	uploadId, err := minioCore.NewMultipartUpload(ctx, d.bucketName, objectName, minio.PutObjectOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "new multipart upload")
+	}
+	return uploadId, nil
+}
+
+func (d *s3StorageDriver) PreSignMultipartUploadUrl(ctx context.Context, objectName string, uploadId string, partNumber int, expires time.Duration) (*url.URL, error) {
+	minioCore, err := d.getMinioCore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	queryValues := make(url.Values)
+	queryValues.Set("partNumber", strconv.Itoa(partNumber))
+	queryValues.Set("uploadId", uploadId)
+	url_, err := minioCore.Presign(ctx, http.MethodPut, d.bucketName, objectName, expires, queryValues)
+	if err != nil {
+		return nil, errors.Wrap(err, "presigned put object")
+	}
+	return d.fixPresignedUrl(url_), nil
+}
+
+func (d *s3StorageDriver) PutMultipartUploadPart(ctx context.Context, objectName string, uploadId string, partNumber int, reader io.Reader, partSize int64) (string, error) {
+	minioCore, err := d.getMinioCore(ctx)
+	if err != nil {
+		return "", err
+	}
+	part, err := minioCore.PutObjectPart(ctx, d.bucketName, objectName, uploadId, partNumber, reader, partSize, "", "", nil)
+	if err != nil {
+		return "", errors.Wrap(err, "put object part")
+	}
+	return part.ETag, nil
+}
+
+func (d *s3StorageDriver) CompleteMultipartUpload(ctx context.Context, objectName string, uploadId string, parts []StorageCompletePart) error {
+	minioCore, err := d.getMinioCore(ctx)
+	if err != nil {
+		return err
+	}
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, part := range parts {
+		completeParts = append(completeParts, minio.CompletePart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		})
+	}
+	_, err = minioCore.CompleteMultipartUpload(ctx, d.bucketName, objectName, uploadId, completeParts, minio.PutObjectOptions{})
+	if err != nil {
+		return errors.Wrap(err, "complete multipart upload")
+	}
+	return nil
+}
+
+func (d *s3StorageDriver) AbortMultipartUpload(ctx context.Context, objectName string, uploadId string) error {
+	minioCore, err := d.getMinioCore(ctx)
+	if err != nil {
+		return err
+	}
+	err = minioCore.AbortMultipartUpload(ctx, d.bucketName, objectName, uploadId)
+	if err != nil {
+		return errors.Wrap(err, "abort multipart upload")
+	}
+	return nil
+}
+
+func (d *s3StorageDriver) ListMultipartUploads(ctx context.Context, objectName string) ([]string, error) {
+	minioCore, err := d.getMinioCore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uploadIds := make([]string, 0)
+	keyMarker, uploadIdMarker := "", ""
+	for {
+		res, err := minioCore.ListMultipartUploads(ctx, d.bucketName, objectName, keyMarker, uploadIdMarker, "", 1000)
+		if err != nil {
+			return nil, errors.Wrap(err, "list multipart uploads")
+		}
+		for _, upload := range res.Uploads {
+			// the prefix also matches the objects whose names start with this object name
+			if upload.Key != objectName {
+				continue
+			}
+			uploadIds = append(uploadIds, upload.UploadID)
+		}
+		if !res.IsTruncated {
+			break
+		}
+		keyMarker, uploadIdMarker = res.NextKeyMarker, res.NextUploadIDMarker
+	}
+	return uploadIds, nil
+}
+
+func (d *s3StorageDriver) Get(ctx context.Context, objectName string, writer io.Writer, opt StorageGetOption) error {
+	minioClient, err := d.getMinioClient(ctx)
+	if err != nil {
+		return err
+	}
+	opts := minio.GetObjectOptions{}
+	if opt.MatchETag != "" {
+		if err = opts.SetMatchETag(opt.MatchETag); err != nil {
+			return errors.Wrap(err, "set match etag")
+		}
+	}
+	if opt.Range != nil {
+		if err = opts.SetRange(opt.Range.Start, opt.Range.End); err != nil {
+			return errors.Wrap(err, "set range")
+		}
+	}
+	obj, err := minioClient.GetObject(ctx, d.bucketName, objectName, opts)
+	if err != nil {
+		return errors.Wrap(err, "get object")
+	}
+	defer obj.Close()
+	_, err = io.Copy(writer, obj)
+	if err != nil {
+		return errors.Wrap(err, "copy object")
+	}
+	return nil
+}
+
+func (d *s3StorageDriver) PreSignGetUrl(ctx context.Context, objectName string, expires time.Duration) (*url.URL, error) {
+	minioClient, err := d.getMinioClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	url_, err := minioClient.PresignedGetObject(ctx, d.bucketName, objectName, expires, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "presigned get object")
+	}
+	return d.fixPresignedUrl(url_), nil
+}
+
+func (d *s3StorageDriver) Stat(ctx context.Context, objectName string) (*StorageObjectInfo, error) {
+	minioClient, err := d.getMinioClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objectInfo, err := minioClient.StatObject(ctx, d.bucketName, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "stat object")
+	}
+	return &StorageObjectInfo{
+		Size:         objectInfo.Size,
+		ETag:         objectInfo.ETag,
+		LastModified: objectInfo.LastModified,
+	}, nil
+}
+
+func (d *s3StorageDriver) Delete(ctx context.Context, objectName string) error {
+	minioClient, err := d.getMinioClient(ctx)
+	if err != nil {
+		return err
+	}
+	err = minioClient.RemoveObject(ctx, d.bucketName, objectName, minio.RemoveObjectOptions{})
+	if err != nil {
+		return errors.Wrap(err, "remove object")
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/config"
+	"github.com/bentoml/yatai/api-server/models"
+)
+
+const (
+	defaultUploadTimeout = 6 * time.Hour
+
+	uploadTimedOutReason = "upload timed out"
+)
+
+type uploadReaperService struct{}
+
+var UploadReaperService = uploadReaperService{}
+
+func (s *uploadReaperService) getUploadTimeout() time.Duration {
+	if config.YataiConfig.Server.UploadTimeout > 0 {
+		return time.Duration(config.YataiConfig.Server.UploadTimeout) * time.Second
+	}
+	return defaultUploadTimeout
+}
+
+// abortMultipartUploads aborts every unfinished multipart upload of the object, the upload id is not persisted so they are looked up in the storage
+func (s *uploadReaperService) abortMultipartUploads(ctx context.Context, driver StorageDriver, objectName string) error {
+	uploadIds, err := driver.ListMultipartUploads(ctx, objectName)
+	if err != nil {
+		return err
+	}
+	for _, uploadId := range uploadIds {
+		err = driver.AbortMultipartUpload(ctx, objectName, uploadId)
+		if err != nil {
+			return errors.Wrapf(err, "abort multipart upload %s", uploadId)
+		}
+	}
+	return nil
+}
+
+func (s *uploadReaperService) createReapedEvent(ctx context.Context, organizationId, creatorId uint, resource models.IResource) error {
+	_, err := EventService.Create(ctx, CreateEventOption{
+		CreatorId:      creatorId,
+		OrganizationId: &organizationId,
+		ResourceType:   resource.GetResourceType(),
+		ResourceId:     resource.GetId(),
+		Status:         modelschemas.EventStatusFailed,
+		OperationName:  "upload reaped",
+	})
+	return err
+}
+
+func (s *uploadReaperService) reapBento(ctx context.Context, bento *models.Bento) (err error) {
+	bentoRepository, err := BentoRepositoryService.GetAssociatedBentoRepository(ctx, bento)
+	if err != nil {
+		return
+	}
+	driver, err := BentoService.getStorageDriver(ctx, bento)
+	if err != nil {
+		return
+	}
+	objectName, err := BentoService.getObjectName(ctx, bento)
+	if err != nil {
+		return
+	}
+	err = s.abortMultipartUploads(ctx, driver, objectName)
+	if err != nil {
+		return
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	// the upload may have been finished while the multipart uploads were being aborted, only a row still uploading is marked as failed
+	now := time.Now()
+	result := BentoService.getBaseDB(ctx).
+		Where("id = ?", bento.ID).
+		Where("upload_status = ?", modelschemas.BentoUploadStatusUploading).
+		Updates(map[string]interface{}{
+			"upload_status":          modelschemas.BentoUploadStatusFailed,
+			"upload_finished_at":     now,
+			"upload_finished_reason": uploadTimedOutReason,
+		})
+	if err = result.Error; err != nil {
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+	err = s.createReapedEvent(ctx, bentoRepository.OrganizationId, bento.CreatorId, bento)
+	return
+}
+
+func (s *uploadReaperService) reapModel(ctx context.Context, model *models.Model) (err error) {
+	modelRepository, err := ModelRepositoryService.GetAssociatedModelRepository(ctx, model)
+	if err != nil {
+		return
+	}
+	driver, err := ModelService.getStorageDriver(ctx, model)
+	if err != nil {
+		return
+	}
+	objectName, err := ModelService.getObjectName(ctx, model)
+	if err != nil {
+		return
+	}
+	err = s.abortMultipartUploads(ctx, driver, objectName)
+	if err != nil {
+		return
+	}
+
+	// nolint: ineffassign,staticcheck
+	_, ctx, df, err := startTransaction(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { df(err) }()
+
+	// the upload may have been finished while the multipart uploads were being aborted, only a row still uploading is marked as failed
+	now := time.Now()
+	result := ModelService.getBaseDB(ctx).
+		Where("id = ?", model.ID).
+		Where("upload_status = ?", modelschemas.ModelUploadStatusUploading).
+		Updates(map[string]interface{}{
+			"upload_status":          modelschemas.ModelUploadStatusFailed,
+			"upload_finished_at":     now,
+			"upload_finished_reason": uploadTimedOutReason,
+		})
+	if err = result.Error; err != nil {
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+	err = s.createReapedEvent(ctx, modelRepository.OrganizationId, model.CreatorId, model)
+	return
+}
+
+// ReapStalledUploads marks the uploads which started longer than the upload timeout ago as failed and aborts their multipart uploads,
+// so that the storage is released and the same version can be pushed again
+func (s *uploadReaperService) ReapStalledUploads(ctx context.Context) error {
+	logger := logrus.WithField("cron", "upload reaper")
+	startedBefore := time.Now().Add(-s.getUploadTimeout())
+
+	bentoUploadStatus := modelschemas.BentoUploadStatusUploading
+	bentos, _, err := BentoService.List(ctx, ListBentoOption{
+		UploadStatus:        &bentoUploadStatus,
+		UploadStartedBefore: &startedBefore,
+	})
+	if err != nil {
+		return errors.Wrap(err, "list stalled bentos")
+	}
+	for _, bento := range bentos {
+		if err = s.reapBento(ctx, bento); err != nil {
+			logger.Errorf("reap bento %d: %s", bento.ID, err.Error())
+			continue
+		}
+		logger.Infof("reaped stalled upload of bento %d", bento.ID)
+	}
+
+	modelUploadStatus := modelschemas.ModelUploadStatusUploading
+	models_, _, err := ModelService.List(ctx, ListModelOption{
+		UploadStatus:        &modelUploadStatus,
+		UploadStartedBefore: &startedBefore,
+	})
+	if err != nil {
+		return errors.Wrap(err, "list stalled models")
+	}
+	for _, model := range models_ {
+		if err = s.reapModel(ctx, model); err != nil {
+			logger.Errorf("reap model %d: %s", model.ID, err.Error())
+			continue
+		}
+		logger.Infof("reaped stalled upload of model %d", model.ID)
+	}
+	return nil
+}
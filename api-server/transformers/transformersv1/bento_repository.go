@@ -130,7 +130,7 @@ func ToBentoRepositoryWithLatestDeploymentsSchemas(ctx context.Context, bentoRep
 	}
 	deploymentSchemasMap := make(map[string]*schemasv1.DeploymentSchema, len(allDeploymentSchemas))
 	for _, s := range allDeploymentSchemas {
-		deploymentSchemasMap[s.Uid] = s
+		deploymentSchemasMap[s.Uid] = &s.DeploymentSchema
 	}
 
 	schemas, err := ToBentoRepositorySchemas(ctx, bentoRepositories)
@@ -0,0 +1,56 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+func ToCanaryRolloutSchema(ctx context.Context, rollout *models.CanaryRollout) (*schemas.CanaryRolloutSchema, error) {
+	if rollout == nil {
+		return nil, nil
+	}
+	ss, err := ToCanaryRolloutSchemas(ctx, []*models.CanaryRollout{rollout})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToCanaryRolloutSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToCanaryRolloutSchemas(ctx context.Context, rollouts []*models.CanaryRollout) ([]*schemas.CanaryRolloutSchema, error) {
+	res := make([]*schemas.CanaryRolloutSchema, 0, len(rollouts))
+	for _, rollout := range rollouts {
+		creatorSchema, err := GetAssociatedCreatorSchema(ctx, rollout)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedCreatorSchema")
+		}
+		deploymentRevision, err := services.DeploymentRevisionService.GetAssociatedDeploymentRevision(ctx, rollout)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedDeploymentRevision")
+		}
+		deploymentTarget, err := services.DeploymentTargetService.Get(ctx, rollout.DeploymentTargetId)
+		if err != nil {
+			return nil, errors.Wrap(err, "get canary deployment target")
+		}
+		res = append(res, &schemas.CanaryRolloutSchema{
+			BaseSchema:              ToBaseSchema(rollout),
+			Creator:                 creatorSchema,
+			DeploymentRevisionUid:   deploymentRevision.Uid,
+			DeploymentTargetUid:     deploymentTarget.Uid,
+			Status:                  rollout.Status,
+			Steps:                   rollout.Steps,
+			Analysis:                rollout.Analysis,
+			CurrentStep:             rollout.CurrentStep,
+			CurrentWeight:           rollout.GetCurrentWeight(),
+			StepStartedAt:           rollout.StepStartedAt,
+			StepBaselinePodRestarts: rollout.StepBaselinePodRestarts,
+			Reason:                  rollout.Reason,
+			FinishedAt:              rollout.FinishedAt,
+		})
+	}
+	return res, nil
+}
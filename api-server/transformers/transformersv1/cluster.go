@@ -9,6 +9,7 @@ import (
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai-schemas/schemasv1"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/api-server/services"
 )
 
@@ -47,7 +48,7 @@ func ToClusterSchemas(ctx context.Context, clusters []*models.Cluster) ([]*schem
 	return res, nil
 }
 
-func ToClusterFullSchema(ctx context.Context, cluster *models.Cluster) (*schemasv1.ClusterFullSchema, error) {
+func ToClusterFullSchema(ctx context.Context, cluster *models.Cluster) (*schemas.ClusterFullSchema, error) {
 	if cluster == nil {
 		return nil, nil
 	}
@@ -64,7 +65,8 @@ func ToClusterFullSchema(ctx context.Context, cluster *models.Cluster) (*schemas
 		return nil, errors.Wrap(err, "to organization schema")
 	}
 	var kubeConfig *string
-	var config **modelschemas.ClusterConfigSchema
+	var config **schemas.ClusterConfigSchema
+	var baseConfig **modelschemas.ClusterConfigSchema
 	currentUser, err := services.GetCurrentUser(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "get current user")
@@ -76,17 +78,25 @@ func ToClusterFullSchema(ctx context.Context, cluster *models.Cluster) (*schemas
 	} else {
 		kubeConfig = &cluster.KubeConfig
 		config = &cluster.Config
+		var baseConfig_ *modelschemas.ClusterConfigSchema
+		if cluster.Config != nil {
+			baseConfig_ = &cluster.Config.ClusterConfigSchema
+		}
+		baseConfig = &baseConfig_
 	}
 	grafanaRootPath, err := services.ClusterService.GetGrafanaRootPath(ctx, cluster)
 	if err != nil {
 		return nil, err
 	}
-	return &schemasv1.ClusterFullSchema{
-		ClusterSchema:   *s,
-		Organization:    orgSchema,
-		KubeConfig:      kubeConfig,
-		Config:          config,
-		GrafanaRootPath: grafanaRootPath,
+	return &schemas.ClusterFullSchema{
+		ClusterFullSchema: schemasv1.ClusterFullSchema{
+			ClusterSchema:   *s,
+			Organization:    orgSchema,
+			KubeConfig:      kubeConfig,
+			Config:          baseConfig,
+			GrafanaRootPath: grafanaRootPath,
+		},
+		Config: config,
 	}, nil
 }
 
@@ -136,5 +146,5 @@ func GetAssociatedClusterFullSchema(ctx context.Context, associate IClusterAssoc
 	if err != nil {
 		return nil, errors.Wrap(err, "ToClusterSchema")
 	}
-	return clusterSchema, nil
+	return &clusterSchema.ClusterFullSchema, nil
 }
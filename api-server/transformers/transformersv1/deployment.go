@@ -8,11 +8,12 @@ import (
 	"github.com/bentoml/yatai-schemas/modelschemas"
 	"github.com/bentoml/yatai-schemas/schemasv1"
 	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
 	"github.com/bentoml/yatai/api-server/services"
 	"github.com/bentoml/yatai/common/utils"
 )
 
-func ToDeploymentSchema(ctx context.Context, deployment *models.Deployment) (*schemasv1.DeploymentSchema, error) {
+func ToDeploymentSchema(ctx context.Context, deployment *models.Deployment) (*schemas.DeploymentSchema, error) {
 	if deployment == nil {
 		return nil, nil
 	}
@@ -23,7 +24,7 @@ func ToDeploymentSchema(ctx context.Context, deployment *models.Deployment) (*sc
 	return ss[0], nil
 }
 
-func ToDeploymentSchemas(ctx context.Context, deployments []*models.Deployment) ([]*schemasv1.DeploymentSchema, error) {
+func ToDeploymentSchemas(ctx context.Context, deployments []*models.Deployment) ([]*schemas.DeploymentSchema, error) {
 	status_ := modelschemas.DeploymentRevisionStatusActive
 	deploymentIds := make([]uint, 0, len(deployments))
 
@@ -59,7 +60,7 @@ func ToDeploymentSchemas(ctx context.Context, deployments []*models.Deployment)
 		return nil, errors.Wrap(err, "ToResourceSchemasMap")
 	}
 
-	res := make([]*schemasv1.DeploymentSchema, 0, len(deployments))
+	res := make([]*schemas.DeploymentSchema, 0, len(deployments))
 	for _, deployment := range deployments {
 		deploymentRevisionUid := deploymentIdToDeploymentRevisionUid[deployment.ID]
 		deploymentRevisionSchema := deploymentRevisionSchemasMap[deploymentRevisionUid]
@@ -79,14 +80,17 @@ func ToDeploymentSchemas(ctx context.Context, deployments []*models.Deployment)
 		if !ok {
 			return nil, errors.Errorf("resource schema not found for deployment %s", deployment.GetUid())
 		}
-		res = append(res, &schemasv1.DeploymentSchema{
-			ResourceSchema: resourceSchema,
-			Creator:        creatorSchema,
-			Cluster:        clusterSchema,
-			Status:         deployment.Status,
-			LatestRevision: deploymentRevisionSchema,
-			URLs:           urls,
-			KubeNamespace:  deployment.KubeNamespace,
+		res = append(res, &schemas.DeploymentSchema{
+			DeploymentSchema: schemasv1.DeploymentSchema{
+				ResourceSchema: resourceSchema,
+				Creator:        creatorSchema,
+				Cluster:        clusterSchema,
+				Status:         deployment.Status,
+				LatestRevision: deploymentRevisionSchema,
+				URLs:           urls,
+				KubeNamespace:  deployment.KubeNamespace,
+			},
+			Drift: deployment.Drift,
 		})
 	}
 	return res, nil
@@ -106,7 +110,7 @@ func GetAssociatedDeploymentSchema(ctx context.Context, associate IDeploymentAss
 	if err != nil {
 		return nil, errors.Wrap(err, "ToDeploymentSchema")
 	}
-	return deploymentSchema, nil
+	return &deploymentSchema.DeploymentSchema, nil
 }
 
 type INullableDeploymentAssociate interface {
@@ -126,5 +130,5 @@ func GetAssociatedNullableDeploymentSchema(ctx context.Context, associate INulla
 	if err != nil {
 		return nil, errors.Wrap(err, "ToNullableDeploymentSchema")
 	}
-	return deploymentSchema, nil
+	return &deploymentSchema.DeploymentSchema, nil
 }
@@ -0,0 +1,67 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/schemasv1"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+func ToDeploymentChangeRequestSchema(ctx context.Context, request *models.DeploymentChangeRequest) (*schemas.DeploymentChangeRequestSchema, error) {
+	if request == nil {
+		return nil, nil
+	}
+	ss, err := ToDeploymentChangeRequestSchemas(ctx, []*models.DeploymentChangeRequest{request})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToDeploymentChangeRequestSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToDeploymentChangeRequestSchemas(ctx context.Context, requests []*models.DeploymentChangeRequest) ([]*schemas.DeploymentChangeRequestSchema, error) {
+	res := make([]*schemas.DeploymentChangeRequestSchema, 0, len(requests))
+	for _, request := range requests {
+		creatorSchema, err := GetAssociatedCreatorSchema(ctx, request)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedCreatorSchema")
+		}
+		var reviewerSchema *schemasv1.UserSchema
+		if request.ReviewerId != nil {
+			reviewer, err := services.UserService.Get(ctx, *request.ReviewerId)
+			if err != nil {
+				return nil, errors.Wrap(err, "get reviewer")
+			}
+			reviewerSchema, err = ToUserSchema(ctx, reviewer)
+			if err != nil {
+				return nil, errors.Wrap(err, "ToUserSchema")
+			}
+		}
+		deployment, err := services.DeploymentService.GetAssociatedDeployment(ctx, request)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedDeployment")
+		}
+		cluster, err := services.ClusterService.GetAssociatedCluster(ctx, request)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedCluster")
+		}
+		res = append(res, &schemas.DeploymentChangeRequestSchema{
+			BaseSchema:     ToBaseSchema(request),
+			Creator:        creatorSchema,
+			Reviewer:       reviewerSchema,
+			Cluster:        cluster.Name,
+			KubeNamespace:  deployment.KubeNamespace,
+			DeploymentName: deployment.Name,
+			Kind:           request.Kind,
+			Status:         request.Status,
+			Targets:        request.Targets,
+			Diff:           request.Diff,
+			ReviewComment:  request.ReviewComment,
+			ReviewedAt:     request.ReviewedAt,
+		})
+	}
+	return res, nil
+}
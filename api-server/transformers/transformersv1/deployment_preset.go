@@ -0,0 +1,50 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+func ToDeploymentPresetSchema(ctx context.Context, preset *models.DeploymentPreset) (*schemas.DeploymentPresetSchema, error) {
+	if preset == nil {
+		return nil, nil
+	}
+	ss, err := ToDeploymentPresetSchemas(ctx, []*models.DeploymentPreset{preset})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToDeploymentPresetSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToDeploymentPresetSchemas(ctx context.Context, presets []*models.DeploymentPreset) ([]*schemas.DeploymentPresetSchema, error) {
+	res := make([]*schemas.DeploymentPresetSchema, 0, len(presets))
+	for _, preset := range presets {
+		creatorSchema, err := GetAssociatedCreatorSchema(ctx, preset)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedCreatorSchema")
+		}
+		cluster, err := services.ClusterService.GetAssociatedNullableCluster(ctx, preset)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedNullableCluster")
+		}
+		clusterName := ""
+		if cluster != nil {
+			clusterName = cluster.Name
+		}
+		res = append(res, &schemas.DeploymentPresetSchema{
+			BaseSchema:  ToBaseSchema(preset),
+			Creator:     creatorSchema,
+			Cluster:     clusterName,
+			Name:        preset.Name,
+			Description: preset.Description,
+			Config:      preset.Config,
+			IsDefault:   preset.IsDefault,
+		})
+	}
+	return res, nil
+}
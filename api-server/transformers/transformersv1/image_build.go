@@ -0,0 +1,44 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+)
+
+func ToImageBuildSchema(ctx context.Context, build *models.ImageBuild) (*schemas.ImageBuildSchema, error) {
+	if build == nil {
+		return nil, nil
+	}
+	ss, err := ToImageBuildSchemas(ctx, []*models.ImageBuild{build})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToImageBuildSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToImageBuildSchemas(ctx context.Context, builds []*models.ImageBuild) ([]*schemas.ImageBuildSchema, error) {
+	res := make([]*schemas.ImageBuildSchema, 0, len(builds))
+	for _, build := range builds {
+		creatorSchema, err := GetAssociatedCreatorSchema(ctx, build)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedCreatorSchema")
+		}
+		res = append(res, &schemas.ImageBuildSchema{
+			BaseSchema:    ToBaseSchema(build),
+			Creator:       creatorSchema,
+			Attempt:       build.Attempt,
+			Status:        build.Status,
+			PodName:       build.PodName,
+			FailureReason: build.FailureReason,
+			Logs:          build.Logs,
+			StartedAt:     build.StartedAt,
+			FinishedAt:    build.FinishedAt,
+			NextRetryAt:   build.NextRetryAt,
+		})
+	}
+	return res, nil
+}
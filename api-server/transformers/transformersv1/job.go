@@ -0,0 +1,67 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+func ToJobSchema(ctx context.Context, job *models.Job) (*schemas.JobSchema, error) {
+	if job == nil {
+		return nil, nil
+	}
+	ss, err := ToJobSchemas(ctx, []*models.Job{job})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToJobSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToJobSchemas(ctx context.Context, jobs []*models.Job) ([]*schemas.JobSchema, error) {
+	resourceSchemasMap, err := ToResourceSchemasMap(ctx, jobs)
+	if err != nil {
+		return nil, errors.Wrap(err, "ToResourceSchemasMap")
+	}
+	res := make([]*schemas.JobSchema, 0, len(jobs))
+	for _, job := range jobs {
+		creatorSchema, err := GetAssociatedCreatorSchema(ctx, job)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedCreatorSchema")
+		}
+		cluster, err := services.ClusterService.GetAssociatedCluster(ctx, job)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedCluster")
+		}
+		bento, err := services.BentoService.GetAssociatedBento(ctx, job)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedBento")
+		}
+		bentoSchemas, err := ToBentoWithRepositorySchemas(ctx, []*models.Bento{bento})
+		if err != nil {
+			return nil, errors.Wrap(err, "ToBentoWithRepositorySchemas")
+		}
+		resourceSchema, ok := resourceSchemasMap[job.GetUid()]
+		if !ok {
+			return nil, errors.Errorf("resource schema not found for job %s", job.GetUid())
+		}
+		res = append(res, &schemas.JobSchema{
+			ResourceSchema:  resourceSchema,
+			Creator:         creatorSchema,
+			Cluster:         cluster.Name,
+			KubeNamespace:   job.KubeNamespace,
+			Description:     job.Description,
+			Bento:           bentoSchemas[0],
+			Type:            job.Type,
+			Schedule:        job.Schedule,
+			Suspend:         job.Suspend,
+			Config:          job.Config,
+			Status:          job.Status,
+			StatusUpdatedAt: job.StatusUpdatedAt,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,48 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+func ToModelImpactSchema(ctx context.Context, model *models.Model, impactBentos []*services.ModelImpactBento) (*schemas.ModelImpactSchema, error) {
+	modelSchema, err := ToModelSchema(ctx, model)
+	if err != nil {
+		return nil, errors.Wrap(err, "ToModelSchema")
+	}
+	bentos := make([]*models.Bento, 0, len(impactBentos))
+	for _, impactBento := range impactBentos {
+		bentos = append(bentos, impactBento.Bento)
+	}
+	bentoSchemas, err := ToBentoWithRepositorySchemas(ctx, bentos)
+	if err != nil {
+		return nil, errors.Wrap(err, "ToBentoWithRepositorySchemas")
+	}
+	bentoImpactSchemas := make([]*schemas.ModelImpactBentoSchema, 0, len(impactBentos))
+	for idx, impactBento := range impactBentos {
+		deploymentSchemas := make([]*schemas.ModelImpactDeploymentSchema, 0, len(impactBento.Deployments))
+		for _, impactDeployment := range impactBento.Deployments {
+			deploymentSchemas = append(deploymentSchemas, &schemas.ModelImpactDeploymentSchema{
+				Cluster:              impactDeployment.Cluster.Name,
+				KubeNamespace:        impactDeployment.Deployment.KubeNamespace,
+				Deployment:           impactDeployment.Deployment.Name,
+				Status:               impactDeployment.Deployment.Status,
+				DeploymentTargetUid:  impactDeployment.DeploymentTarget.Uid,
+				DeploymentTargetType: impactDeployment.DeploymentTarget.Type,
+			})
+		}
+		bentoImpactSchemas = append(bentoImpactSchemas, &schemas.ModelImpactBentoSchema{
+			Bento:       bentoSchemas[idx],
+			Deployments: deploymentSchemas,
+		})
+	}
+	return &schemas.ModelImpactSchema{
+		Model:  modelSchema,
+		Bentos: bentoImpactSchemas,
+	}, nil
+}
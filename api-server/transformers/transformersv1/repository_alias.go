@@ -0,0 +1,79 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai-schemas/modelschemas"
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+func getRepositoryAliasTargetVersion(ctx context.Context, alias *models.RepositoryAlias) (string, error) {
+	switch alias.ResourceType {
+	case modelschemas.ResourceTypeBentoRepository:
+		bento, err := services.BentoService.Get(ctx, alias.TargetId)
+		if err != nil {
+			return "", errors.Wrapf(err, "get bento of alias %s", alias.Name)
+		}
+		return bento.Version, nil
+	case modelschemas.ResourceTypeModelRepository:
+		model, err := services.ModelService.Get(ctx, alias.TargetId)
+		if err != nil {
+			return "", errors.Wrapf(err, "get model of alias %s", alias.Name)
+		}
+		return model.Version, nil
+	default:
+		return "", errors.Errorf("unknown repository type %s of alias %s", alias.ResourceType, alias.Name)
+	}
+}
+
+func ToRepositoryAliasSchema(ctx context.Context, alias *models.RepositoryAlias) (*schemas.RepositoryAliasSchema, error) {
+	if alias == nil {
+		return nil, nil
+	}
+	ss, err := ToRepositoryAliasSchemas(ctx, []*models.RepositoryAlias{alias})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToRepositoryAliasSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToRepositoryAliasSchemas(ctx context.Context, aliases []*models.RepositoryAlias) ([]*schemas.RepositoryAliasSchema, error) {
+	res := make([]*schemas.RepositoryAliasSchema, 0, len(aliases))
+	for _, alias := range aliases {
+		creatorSchema, err := GetAssociatedCreatorSchema(ctx, alias)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedCreatorSchema")
+		}
+		version, err := getRepositoryAliasTargetVersion(ctx, alias)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, &schemas.RepositoryAliasSchema{
+			BaseSchema: ToBaseSchema(alias),
+			Creator:    creatorSchema,
+			Name:       alias.Name,
+			Version:    version,
+		})
+	}
+	return res, nil
+}
+
+func ToRepositoryAliasHistorySchemas(ctx context.Context, histories []*models.RepositoryAliasHistory) ([]*schemas.RepositoryAliasHistorySchema, error) {
+	res := make([]*schemas.RepositoryAliasHistorySchema, 0, len(histories))
+	for _, history := range histories {
+		creatorSchema, err := GetAssociatedCreatorSchema(ctx, history)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedCreatorSchema")
+		}
+		res = append(res, &schemas.RepositoryAliasHistorySchema{
+			BaseSchema: ToBaseSchema(history),
+			Creator:    creatorSchema,
+			Version:    history.Version,
+		})
+	}
+	return res, nil
+}
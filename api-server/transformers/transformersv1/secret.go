@@ -0,0 +1,53 @@
+package transformersv1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/bentoml/yatai/api-server/models"
+	"github.com/bentoml/yatai/api-server/schemas"
+	"github.com/bentoml/yatai/api-server/services"
+)
+
+func ToSecretSchema(ctx context.Context, secret *models.Secret) (*schemas.SecretSchema, error) {
+	if secret == nil {
+		return nil, nil
+	}
+	ss, err := ToSecretSchemas(ctx, []*models.Secret{secret})
+	if err != nil {
+		return nil, errors.Wrap(err, "ToSecretSchemas")
+	}
+	return ss[0], nil
+}
+
+func ToSecretSchemas(ctx context.Context, secrets []*models.Secret) ([]*schemas.SecretSchema, error) {
+	res := make([]*schemas.SecretSchema, 0, len(secrets))
+	for _, secret := range secrets {
+		creatorSchema, err := GetAssociatedCreatorSchema(ctx, secret)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedCreatorSchema")
+		}
+		cluster, err := services.ClusterService.GetAssociatedNullableCluster(ctx, secret)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetAssociatedNullableCluster")
+		}
+		clusterName := ""
+		if cluster != nil {
+			clusterName = cluster.Name
+		}
+		keys, err := services.SecretService.GetKeys(secret)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, &schemas.SecretSchema{
+			BaseSchema:  ToBaseSchema(secret),
+			Creator:     creatorSchema,
+			Cluster:     clusterName,
+			Name:        secret.Name,
+			Description: secret.Description,
+			Keys:        keys,
+		})
+	}
+	return res, nil
+}
@@ -17,7 +17,9 @@ const (
 
 	TracingContextKey = "tracing-context"
 	// nolint: gosec
-	YataiApiTokenHeaderName = "X-YATAI-API-TOKEN"
+	YataiApiTokenHeaderName     = "X-YATAI-API-TOKEN"
+	YataiChecksumHeaderName     = "X-Yatai-Checksum-Sha256"
+	YataiOrganizationHeaderName = "X-Yatai-Organization"
 
 	BentoServicePort       = 3000
 	BentoServicePortEnvKey = "PORT"
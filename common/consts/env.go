@@ -27,6 +27,9 @@ const (
 	EnvS3SecretKey = "S3_SECRET_KEY"
 	EnvS3Secure    = "S3_SECURE"
 
+	EnvStorageDriver       = "STORAGE_DRIVER"
+	EnvLocalStorageRootDir = "LOCAL_STORAGE_ROOT_DIR"
+
 	EnvDockerRegistryServer   = "DOCKER_REGISTRY_SERVER"
 	EnvDockerRegistryUsername = "DOCKER_REGISTRY_USERNAME"
 	// nolint:gosec
@@ -38,4 +41,15 @@ const (
 	EnvDockerImageBuilderPrivileged = "DOCKER_IMAGE_BUILDER_PRIVILEGED"
 
 	EnvReadHeaderTimeout = "READ_HEADER_TIMEOUT"
+	EnvUploadTimeout     = "UPLOAD_TIMEOUT"
+
+	EnvPrometheusEndpoint = "PROMETHEUS_ENDPOINT"
+
+	// nolint:gosec
+	EnvSecretsEncryptionKey = "SECRETS_ENCRYPTION_KEY"
+
+	// EnvYataiEndpoint and EnvYataiApiToken are read by the subcommands that talk to a running api server
+	EnvYataiEndpoint = "YATAI_ENDPOINT"
+	// nolint:gosec
+	EnvYataiApiToken = "YATAI_API_TOKEN"
 )
@@ -10,3 +10,8 @@ var KubeListEverything = metav1.ListOptions{
 	LabelSelector: labels.Everything().String(),
 	FieldSelector: fields.Everything().String(),
 }
+
+const (
+	// KubeLabelYataiJob is set on the kubernetes jobs and cron jobs of a yatai job, the value is the job name
+	KubeLabelYataiJob = "yatai.ai/job"
+)
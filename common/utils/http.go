@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -52,3 +55,84 @@ func APIOutputErr(ctx context.Context, w http.ResponseWriter, statusCode int, ms
 		Message: msg,
 	})
 }
+
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// ParseByteRange parses a single range of the Range header against the content size and
+// returns the inclusive offsets. ok is false when the header should be ignored and the full content served.
+func ParseByteRange(header string, size int64) (start, end int64, ok bool, err error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return
+	}
+	spec := strings.TrimSpace(strings.TrimPrefix(header, prefix))
+	if spec == "" || strings.Contains(spec, ",") {
+		return
+	}
+	startStr, sep, endStr := Partition(spec, "-")
+	if sep == "" {
+		return
+	}
+	startStr = strings.TrimSpace(startStr)
+	endStr = strings.TrimSpace(endStr)
+	if startStr == "" {
+		// suffix range: the last N bytes
+		var suffixLength int64
+		suffixLength, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || suffixLength < 0 {
+			err = nil
+			return
+		}
+		if suffixLength == 0 || size == 0 {
+			err = ErrRangeNotSatisfiable
+			return
+		}
+		if suffixLength > size {
+			suffixLength = size
+		}
+		return size - suffixLength, size - 1, true, nil
+	}
+	start, err = strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+	end = size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, nil
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		return 0, 0, false, ErrRangeNotSatisfiable
+	}
+	return start, end, true, nil
+}
+
+// MatchETag reports whether the If-None-Match header value matches the unquoted etag with the weak comparison
+func MatchETag(header, etag string) bool {
+	for _, item := range strings.Split(header, ",") {
+		item = strings.TrimSpace(item)
+		if item == "*" {
+			return true
+		}
+		item = strings.TrimPrefix(item, "W/")
+		if strings.Trim(item, "\"") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// StrongMatchETag reports whether the If-Range header value is the unquoted etag with the strong comparison of RFC 7233,
+// a weak etag never matches since the bytes of the representation it validates may differ
+func StrongMatchETag(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if strings.HasPrefix(header, "W/") || len(header) < 2 || !strings.HasPrefix(header, "\"") || !strings.HasSuffix(header, "\"") {
+		return false
+	}
+	return header[1:len(header)-1] == etag
+}
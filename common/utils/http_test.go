@@ -0,0 +1,62 @@
+package utils
+
+import "testing"
+
+func TestParseByteRange(t *testing.T) {
+	cases := []struct {
+		header string
+		size   int64
+		start  int64
+		end    int64
+		ok     bool
+		err    error
+	}{
+		{"bytes=0-99", 1000, 0, 99, true, nil},
+		{"bytes=100-", 1000, 100, 999, true, nil},
+		{"bytes=-100", 1000, 900, 999, true, nil},
+		{"bytes=-2000", 1000, 0, 999, true, nil},
+		{"bytes=900-5000", 1000, 900, 999, true, nil},
+		{"bytes=1000-", 1000, 0, 0, false, ErrRangeNotSatisfiable},
+		{"bytes=-0", 1000, 0, 0, false, ErrRangeNotSatisfiable},
+		{"bytes=0-1,5-6", 1000, 0, 0, false, nil},
+		{"bytes=5-1", 1000, 0, 0, false, nil},
+		{"items=0-1", 1000, 0, 0, false, nil},
+		{"bytes=a-b", 1000, 0, 0, false, nil},
+	}
+	for _, c := range cases {
+		start, end, ok, err := ParseByteRange(c.header, c.size)
+		if start != c.start || end != c.end || ok != c.ok || err != c.err {
+			t.Fatalf("%s: got (%d, %d, %v, %v), excepted (%d, %d, %v, %v)", c.header, start, end, ok, err, c.start, c.end, c.ok, c.err)
+		}
+	}
+}
+
+func TestMatchETag(t *testing.T) {
+	if !MatchETag(`"abc"`, "abc") {
+		t.Fatal("abc should match")
+	}
+	if !MatchETag(`"xyz", W/"abc"`, "abc") {
+		t.Fatal("weak abc should match")
+	}
+	if !MatchETag("*", "abc") {
+		t.Fatal("* should match")
+	}
+	if MatchETag(`"xyz"`, "abc") {
+		t.Fatal("xyz should not match")
+	}
+}
+
+func TestStrongMatchETag(t *testing.T) {
+	if !StrongMatchETag(`"abc"`, "abc") {
+		t.Fatal("abc should match")
+	}
+	if StrongMatchETag(`W/"abc"`, "abc") {
+		t.Fatal("weak abc should not match")
+	}
+	if StrongMatchETag("*", "abc") || StrongMatchETag("abc", "abc") {
+		t.Fatal("only a quoted etag should match")
+	}
+	if StrongMatchETag("Wed, 21 Oct 2015 07:28:00 GMT", "abc") {
+		t.Fatal("a date should not match")
+	}
+}